@@ -2,10 +2,12 @@ package cache
 
 import "sync"
 
-// Cache mechanism for caching strings
+// Cache mechanism for caching strings and the documents decoded from them
 type Cache interface {
 	Get(key string) ([]byte, error)
 	Set(key string, content []byte)
+	GetDocument(key string) (interface{}, error)
+	SetDocument(key string, doc interface{})
 	Lock()
 	Unlock()
 	Reset()
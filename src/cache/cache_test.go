@@ -64,3 +64,23 @@ func (suite *metricConfSuit) TestCanResetCache() {
 	suite.Equal(val, rVal1)
 	suite.NotNil(err2)
 }
+
+func (suite *metricConfSuit) TestCanSetAndResetDocument() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	mc := NewCache()
+	key := "dfdhj&**#json"
+	doc := map[string]interface{}{"seq": float64(58894)}
+
+	// NOTE(denisacostaq@gmail.com): When
+	mc.SetDocument(key, doc)
+	rDoc, err1 := mc.GetDocument(key)
+	_, errBody := mc.Get(key)
+	mc.Reset()
+	_, err2 := mc.GetDocument(key)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.Equal(doc, rDoc)
+	suite.NotNil(errBody)
+	suite.NotNil(err2)
+}
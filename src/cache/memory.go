@@ -9,6 +9,7 @@ import (
 type MemCache struct {
 	baseCache
 	vals      map[string][]byte
+	docs      map[string]interface{}
 	dataMutex *sync.RWMutex
 }
 
@@ -16,6 +17,7 @@ func newMemCache() *MemCache {
 	return &MemCache{
 		baseCache: baseCache{extLocker: &sync.Mutex{}},
 		vals:      make(map[string][]byte),
+		docs:      make(map[string]interface{}),
 		dataMutex: &sync.RWMutex{},
 	}
 }
@@ -37,11 +39,31 @@ func (c *MemCache) Set(key string, data []byte) {
 	c.vals[key] = data
 }
 
-// Reset clear al the cached data
+// GetDocument return the cached document by a giving key, error if this document not found
+func (c *MemCache) GetDocument(key string) (doc interface{}, err error) {
+	c.dataMutex.RLock()
+	defer c.dataMutex.RUnlock()
+	if doc, ok := c.docs[key]; ok {
+		return doc, nil
+	}
+	return nil, fmt.Errorf("document not found for key %s", key)
+}
+
+// SetDocument save a decoded document with a giving key in the cache sistem
+func (c *MemCache) SetDocument(key string, doc interface{}) {
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+	c.docs[key] = doc
+}
+
+// Reset clear al the cached data and documents
 func (c *MemCache) Reset() {
 	c.dataMutex.Lock()
 	defer c.dataMutex.Unlock()
 	for k := range c.vals {
 		delete(c.vals, k)
 	}
+	for k := range c.docs {
+		delete(c.docs, k)
+	}
 }
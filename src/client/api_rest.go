@@ -1,61 +1,59 @@
 package client
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/oliveagle/jsonpath"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/simelo/rextporter/src/config"
 	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
 	log "github.com/sirupsen/logrus"
 )
 
 // APIRestCreator have info to create api rest an client
 type APIRestCreator struct {
 	baseFactory
-	httpMethod           string
-	dataPath             string
-	tokenPath            string
-	tokenHeaderKey       string
-	tokenKeyFromEndpoint string
+	httpMethod    string
+	dataPath      string
+	body          []byte
+	contentType   string
+	auth          authStrategy
+	transport     *transportSource
+	retry         retryPolicy
+	breaker       *circuitBreaker
+	pagination    *paginationDef
+	clientMetrics *metrics.DefaultClientMetrics
 }
 
 // CreateAPIRestCreator create an APIRestCreator
-func CreateAPIRestCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc) (cf CacheableFactory, err error) {
-	resOptions := resConf.GetOptions()
-	httpMethod, err := resOptions.GetString(config.OptKeyRextResourceDefHTTPMethod)
+func CreateAPIRestCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf CacheableFactory, err error) {
+	httpMethod, err := resConf.GetOptions().GetString(config.OptKeyRextResourceDefHTTPMethod)
 	if err != nil {
 		log.WithError(err).Errorln("Can not find httpMethod")
 		return cf, err
 	}
-	resURI := strings.TrimPrefix(resConf.GetResourcePATH(srvConf.GetBasePath()), srvConf.GetBasePath())
-	auth := resConf.GetAuth(srvConf.GetAuthForBaseURL())
-	var tkHeaderKey, tkKeyFromEndpoint, tkKeyGenEndpoint string
-	if auth != nil {
-		authOpts := auth.GetOptions()
-		tkHeaderKey, err = authOpts.GetString(config.OptKeyRextAuthDefTokenHeaderKey)
-		if err != nil {
-			log.WithError(err).Errorln("Can not find tokenHeaderKey")
-			return cf, err
-		}
-		tkKeyFromEndpoint, err = authOpts.GetString(config.OptKeyRextAuthDefTokenKeyFromEndpoint)
-		if err != nil {
-			log.WithError(err).Errorln("Can not find tokenKeyFromEndpoint")
-			return cf, err
-		}
-		tkKeyGenEndpoint, err = authOpts.GetString(config.OptKeyRextAuthDefTokenGenEndpoint)
-		if err != nil {
-			log.WithError(err).Errorln("Can not find tkKeyGenEndpoint")
-			return cf, err
-		}
-	} else {
-		log.Warnln("you have an empty auth")
+	var ac APIRestCreator
+	if ac, err = newAPIRestCreator(resConf, srvConf, httpMethod, dataSourceResponseDurationDesc, cDefMetrics); err != nil {
+		return cf, err
 	}
+	if ac.pagination, err = paginationDefFromOptions(resConf.GetOptions()); err != nil {
+		log.WithError(err).Errorln("Can not read the pagination settings")
+		return cf, err
+	}
+	return ac, nil
+}
+
+// newAPIRestCreator create an APIRestCreator making httpMethod requests
+func newAPIRestCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, httpMethod string, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf APIRestCreator, err error) {
+	resOptions := resConf.GetOptions()
+	resURI := strings.TrimPrefix(resConf.GetResourcePATH(srvConf.GetBasePath()), srvConf.GetBasePath())
 	srvOpts := srvConf.GetOptions()
 	jobName, err := srvOpts.GetString(config.OptKeyRextServiceDefJobName)
 	if err != nil {
@@ -67,6 +65,26 @@ func CreateAPIRestCreator(resConf config.RextResourceDef, srvConf config.RextSer
 		log.WithError(err).Errorln("Can not find instanceName")
 		return cf, err
 	}
+	var ts *transportSource
+	if ts, err = transportSourceFor(resOptions, srvOpts, jobName, instanceName, cDefMetrics); err != nil {
+		log.WithError(err).Errorln("Can not read the http settings")
+		return cf, err
+	}
+	var retry retryPolicy
+	if retry, err = retryPolicyFromOptions(resOptions, srvOpts); err != nil {
+		log.WithError(err).Errorln("Can not read the retry policy")
+		return cf, err
+	}
+	var breaker *circuitBreaker
+	if breaker, err = circuitBreakerFor(resOptions, srvOpts, jobName, instanceName, resURI, cDefMetrics); err != nil {
+		log.WithError(err).Errorln("Can not read the circuit breaker settings")
+		return cf, err
+	}
+	var auth authStrategy
+	if auth, err = createAuthStrategy(resConf.GetAuth(srvConf.GetAuthForBaseURL()), srvConf, jobName, instanceName, dataSourceResponseDurationDesc, ts); err != nil {
+		log.WithError(err).Errorln("Can not create the auth")
+		return cf, err
+	}
 	cf = APIRestCreator{
 		baseFactory: baseFactory{
 			jobName:                        jobName,
@@ -74,11 +92,13 @@ func CreateAPIRestCreator(resConf config.RextResourceDef, srvConf config.RextSer
 			dataSource:                     resURI,
 			dataSourceResponseDurationDesc: dataSourceResponseDurationDesc,
 		},
-		httpMethod:           httpMethod,
-		dataPath:             resConf.GetResourcePATH(srvConf.GetBasePath()),
-		tokenPath:            srvConf.GetBasePath() + tkKeyGenEndpoint,
-		tokenHeaderKey:       tkHeaderKey,
-		tokenKeyFromEndpoint: tkKeyFromEndpoint,
+		httpMethod:    httpMethod,
+		dataPath:      resConf.GetResourcePATH(srvConf.GetBasePath()),
+		auth:          auth,
+		transport:     ts,
+		retry:         retry,
+		breaker:       breaker,
+		clientMetrics: cDefMetrics,
 	}
 	return cf, err
 }
@@ -86,21 +106,17 @@ func CreateAPIRestCreator(resConf config.RextResourceDef, srvConf config.RextSer
 // CreateClient create an api rest client
 func (ac APIRestCreator) CreateClient() (cl CacheableClient, err error) {
 	const generalScopeErr = "error creating api rest client"
+	var body io.Reader
+	if ac.body != nil {
+		body = bytes.NewReader(ac.body)
+	}
 	var req *http.Request
-	if req, err = http.NewRequest(ac.httpMethod, ac.dataPath, nil); err != nil {
+	if req, err = http.NewRequest(ac.httpMethod, ac.dataPath, body); err != nil {
 		errCause := fmt.Sprintln("can not create the request client: ", err.Error())
 		return APIRest{}, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
-	var tokenClient Client
-	tc := TokenCreator{
-		jobName:                        ac.jobName,
-		instanceName:                   ac.instanceName,
-		dataSource:                     ac.tokenPath,
-		dataSourceResponseDurationDesc: ac.dataSourceResponseDurationDesc,
-	}
-	if tokenClient, err = tc.CreateClient(); err != nil {
-		errCause := fmt.Sprintln("create token client: ", err.Error())
-		return APIRest{}, util.ErrorFromThisScope(errCause, generalScopeErr)
+	if len(ac.contentType) != 0 {
+		req.Header.Set("Content-Type", ac.contentType)
 	}
 	cl = APIRest{
 		baseClient: baseClient{
@@ -109,11 +125,14 @@ func (ac APIRestCreator) CreateClient() (cl CacheableClient, err error) {
 			dataSource:                     ac.dataSource,
 			dataSourceResponseDurationDesc: ac.dataSourceResponseDurationDesc,
 		},
-		baseCacheableClient:  baseCacheableClient(ac.dataPath),
-		req:                  req,
-		tokenClient:          tokenClient,
-		tokenHeaderKey:       ac.tokenHeaderKey,
-		tokenKeyFromEndpoint: ac.tokenKeyFromEndpoint,
+		baseCacheableClient: baseCacheableClient(ac.dataPath),
+		req:                 req,
+		auth:                ac.auth,
+		transport:           ac.transport,
+		retry:               ac.retry,
+		breaker:             ac.breaker,
+		pagination:          ac.pagination,
+		clientMetrics:       ac.clientMetrics,
 	}
 	return cl, nil
 }
@@ -122,19 +141,64 @@ func (ac APIRestCreator) CreateClient() (cl CacheableClient, err error) {
 type APIRest struct {
 	baseClient
 	baseCacheableClient
-	req                  *http.Request
-	tokenClient          Client
-	tokenHeaderKey       string
-	tokenKeyFromEndpoint string
-	token                string
+	req           *http.Request
+	auth          authStrategy
+	transport     *transportSource
+	retry         retryPolicy
+	breaker       *circuitBreaker
+	pagination    *paginationDef
+	clientMetrics *metrics.DefaultClientMetrics
 }
 
-// GetData can retrieve data from a rest API with a retry pollicy for token expiration.
-func (cl APIRest) GetData(metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+// GetData can retrieve data from a rest API with a retry pollicy for credentials expiration and transient failures,
+// a CircuitOpenError is returned without making any request if the data source is failing. The pages of a
+// paginated resource are merged in a single response.
+func (cl APIRest) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
 	const generalScopeErr = "error making a server request to get metric from remote endpoint"
-	cl.req.Header.Set(cl.tokenHeaderKey, cl.token)
-	getData := func() (data []byte, err error) {
-		httpClient := &http.Client{}
+	var probe bool
+	if probe, err = cl.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func(scrapeCtx context.Context) {
+		// NOTE(denisacostaq@gmail.com): a scrape cancelled by the Prometheus side say nothing about the data source
+		cl.breaker.record(probe, err, scrapeCtx.Err() == context.Canceled)
+	}(ctx)
+	httpClient, err := newHTTPClient(cl.transport)
+	if err != nil {
+		errCause := fmt.Sprintln("can not create the http client: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	ctx, cancel := cl.retry.budget(ctx)
+	defer cancel()
+	if cl.auth != nil {
+		cl.auth.prepareClient(httpClient)
+	}
+	req := cl.req.WithContext(ctx)
+	if cl.pagination == nil {
+		data, _, err = cl.do(ctx, httpClient, req, metricsCollector)
+		return data, err
+	}
+	var pages int
+	data, pages, err = cl.pagination.fetchAll(req, func(pageReq *http.Request) ([]byte, http.Header, error) {
+		return cl.do(ctx, httpClient, pageReq, metricsCollector)
+	})
+	if cl.clientMetrics != nil {
+		labels := []string{cl.jobName, cl.instanceName, cl.dataSource}
+		cl.clientMetrics.DataSourcePagesFetched.WithLabelValues(labels...).Add(float64(pages))
+	}
+	return data, err
+}
+
+// do make the request with the retry policy and return the response body and headers
+func (cl APIRest) do(ctx context.Context, httpClient *http.Client, req *http.Request, metricsCollector chan<- prometheus.Metric) (data []byte, header http.Header, err error) {
+	const generalScopeErr = "error making a server request to get metric from remote endpoint"
+	if cl.auth != nil {
+		if err = cl.auth.authenticate(ctx, req, metricsCollector); err != nil {
+			errCause := fmt.Sprintln("can not authenticate the request: ", err.Error())
+			return nil, nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	getData := func() (data []byte, rejected, retryable bool, err error) {
 		var resp *http.Response
 		{
 			successResponse := false
@@ -150,67 +214,64 @@ func (cl APIRest) GetData(metricsCollector chan<- prometheus.Metric) (data []byt
 					}
 				}
 			}(time.Now().UTC())
-			if resp, err = httpClient.Do(cl.req); err != nil {
-				log.WithFields(log.Fields{"err": err, "req": cl.req}).Errorln("no success response")
+			// NOTE(denisacostaq@gmail.com): the body was read by the previous attempt
+			if req.GetBody != nil {
+				if req.Body, err = req.GetBody(); err != nil {
+					errCause := fmt.Sprintln("can not get the request body: ", err.Error())
+					return nil, false, false, util.ErrorFromThisScope(errCause, generalScopeErr)
+				}
+			}
+			if resp, err = httpClient.Do(req); err != nil {
+				log.WithFields(log.Fields{"err": err, "req": req}).Errorln("no success response")
 				errCause := fmt.Sprintln("can not do the request: ", err.Error())
-				return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+				return nil, cl.auth != nil && cl.auth.rejected(nil), cl.retry.retryableError(ctx, err), util.ErrorFromThisScope(errCause, generalScopeErr)
 			}
 			if resp.StatusCode != http.StatusOK {
-				log.WithFields(log.Fields{"status": resp.Status, "req": cl.req}).Errorln("no success response")
+				resp.Body.Close()
+				log.WithFields(log.Fields{"status": resp.Status, "req": req}).Errorln("no success response")
 				errCause := fmt.Sprintf("no success response, status %s", resp.Status)
-				return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+				return nil, cl.auth != nil && cl.auth.rejected(resp), cl.retry.retryableStatus(resp.StatusCode), util.ErrorFromThisScope(errCause, generalScopeErr)
 			}
 			successResponse = true
 		}
 		defer resp.Body.Close()
+		header = resp.Header
 		if data, err = ioutil.ReadAll(resp.Body); err != nil {
 			errCause := fmt.Sprintln("can not read the body: ", err.Error())
-			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+			return nil, false, cl.retry.retryableError(ctx, err), util.ErrorFromThisScope(errCause, generalScopeErr)
 		}
-		return data, nil
+		return data, false, false, nil
 	}
-	if data, err = getData(); err != nil {
-		// log.Println("can not do the request:", err.Error(), "trying with a new token...")
-		if err = cl.resetToken(metricsCollector); err != nil {
-			errCause := fmt.Sprintln("can not reset the token: ", err.Error())
-			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	renewed := false
+	for attempt := 1; ; {
+		var rejected, retryable bool
+		if data, rejected, retryable, err = getData(); err == nil {
+			return data, header, nil
 		}
-		if data, err = getData(); err != nil {
-			errCause := fmt.Sprintln("can not do the request after a token reset neither: ", err.Error())
-			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		switch {
+		case rejected && !renewed:
+			// NOTE(denisacostaq@gmail.com): the credentials are renewed once, it is not counted as an attempt
+			renewed = true
+			if err = cl.auth.renew(ctx, metricsCollector); err != nil {
+				errCause := fmt.Sprintln("can not renew the credentials: ", err.Error())
+				return nil, nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+			}
+			if err = cl.auth.authenticate(ctx, req, metricsCollector); err != nil {
+				errCause := fmt.Sprintln("can not authenticate the request: ", err.Error())
+				return nil, nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+			}
+		case retryable && attempt < cl.retry.maxAttempts:
+			if cl.clientMetrics != nil {
+				labels := []string{cl.jobName, cl.instanceName, cl.dataSource}
+				cl.clientMetrics.DataSourceRequestRetries.WithLabelValues(labels...).Inc()
+			}
+			if waitErr := cl.retry.wait(ctx, attempt); waitErr != nil {
+				errCause := fmt.Sprintln("no time left to retry the request: ", err.Error())
+				return nil, nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+			}
+			attempt++
+		default:
+			return nil, nil, err
 		}
 	}
-	return data, nil
-}
-
-func (cl APIRest) resetToken(metricsCollector chan<- prometheus.Metric) (err error) {
-	const generalScopeErr = "error making resetting the token"
-	cl.token = ""
-	var data []byte
-	if data, err = cl.tokenClient.GetData(metricsCollector); err != nil {
-		errCause := fmt.Sprintln("can make the request to get a token: ", err.Error())
-		return util.ErrorFromThisScope(errCause, generalScopeErr)
-	}
-	var jsonData interface{}
-	if err = json.Unmarshal(data, &jsonData); err != nil {
-		errCause := fmt.Sprintln("can not decode the body: ", string(data), " ", err.Error())
-		return util.ErrorFromThisScope(errCause, generalScopeErr)
-	}
-	var val interface{}
-	jPath := "$" + strings.Replace(cl.tokenKeyFromEndpoint, "/", ".", -1)
-	if val, err = jsonpath.JsonPathLookup(jsonData, jPath); err != nil {
-		errCause := fmt.Sprintln("can not locate the path: ", err.Error())
-		return util.ErrorFromThisScope(errCause, generalScopeErr)
-	}
-	tk, ok := val.(string)
-	if !ok {
-		errCause := fmt.Sprintln("unable the get the token as a string value")
-		return util.ErrorFromThisScope(errCause, generalScopeErr)
-	}
-	cl.token = tk
-	if len(cl.token) == 0 {
-		errCause := fmt.Sprintln("unable the get a not null(empty) token")
-		return util.ErrorFromThisScope(errCause, generalScopeErr)
-	}
-	return nil
 }
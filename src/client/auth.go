@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"text/template"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// authStrategy put the credentials in the requests made by a client and know how to get new ones
+// when the remote service reject a request
+type authStrategy interface {
+	// prepareClient customize the http client before it is used, for example to set a cookie jar
+	prepareClient(httpClient *http.Client)
+	// authenticate put the current credentials in the request
+	authenticate(ctx context.Context, req *http.Request, metricsCollector chan<- prometheus.Metric) error
+	// rejected return true if the response(nil if the request fails) may be caused by invalid credentials
+	rejected(resp *http.Response) bool
+	// renew get new credentials
+	renew(ctx context.Context, metricsCollector chan<- prometheus.Metric) error
+}
+
+// createAuthStrategy create the strategy for the auth type in auth(can be nil), it return a nil strategy if
+// no auth is required
+func createAuthStrategy(auth config.RextAuthDef, srvConf config.RextServiceDef, jobName, instanceName string, dataSourceResponseDurationDesc *prometheus.Desc, ts *transportSource) (strategy authStrategy, err error) {
+	if auth == nil {
+		log.Warnln("you have an empty auth")
+		return nil, nil
+	}
+	switch auth.GetAuthType() {
+	case config.AuthTypeCSRF:
+		return createTokenAuth(auth, srvConf, jobName, instanceName, dataSourceResponseDurationDesc, ts)
+	case config.AuthTypeSessionLogin:
+		return createSessionAuth(auth, srvConf, jobName, instanceName, ts)
+	case config.AuthTypeHMAC:
+		return createHMACAuth(auth)
+	default:
+		log.WithField("auth_type", auth.GetAuthType()).Warnln("unknown auth type, requests will be made without auth")
+		return nil, nil
+	}
+}
+
+// requestDef describe a request to be sent for authentication purposes, like a login or a token request
+type requestDef struct {
+	httpMethod   string
+	url          string
+	bodyTemplate string
+	headers      map[string]string
+}
+
+// requestDefFromOptions read a request definition from the auth options
+func requestDefFromOptions(opts config.RextKeyValueStore, url, methodKey, defMethod, bodyKey, headersKey string) (rd requestDef, err error) {
+	rd.url = url
+	// NOTE(denisacostaq@gmail.com): method, body and headers are optional
+	if rd.httpMethod, _ = opts.GetString(methodKey); len(rd.httpMethod) == 0 {
+		rd.httpMethod = defMethod
+	}
+	rd.bodyTemplate, _ = opts.GetString(bodyKey)
+	if iHeaders, err := opts.GetObject(headersKey); err == nil {
+		var okHeaders bool
+		if rd.headers, okHeaders = iHeaders.(map[string]string); !okHeaders {
+			log.WithField("val", iHeaders).Errorln("headers should be a map[string]string")
+			return rd, config.ErrKeyInvalidType
+		}
+	}
+	return rd, nil
+}
+
+// bodyTemplateData is the data available to render a request body template
+type bodyTemplateData struct {
+	JobName      string
+	InstanceName string
+}
+
+// newRequest create the request rendering the body template
+func (rd requestDef) newRequest(jobName, instanceName string) (req *http.Request, err error) {
+	const generalScopeErr = "error creating the request"
+	var body []byte
+	if len(rd.bodyTemplate) != 0 {
+		var tmpl *template.Template
+		if tmpl, err = template.New("body").Funcs(template.FuncMap{"env": os.Getenv}).Parse(rd.bodyTemplate); err != nil {
+			errCause := fmt.Sprintln("can not parse the body template: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		var buff bytes.Buffer
+		if err = tmpl.Execute(&buff, bodyTemplateData{JobName: jobName, InstanceName: instanceName}); err != nil {
+			errCause := fmt.Sprintln("can not execute the body template: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		body = buff.Bytes()
+	}
+	if req, err = http.NewRequest(rd.httpMethod, rd.url, bytes.NewReader(body)); err != nil {
+		errCause := fmt.Sprintln("can not create the request: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	for k, v := range rd.headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// CircuitOpenError is returned without making any request while the circuit for a data source is open
+type CircuitOpenError struct {
+	JobName      string
+	InstanceName string
+	DataSource   string
+	RetryAt      time.Time
+}
+
+func (err CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for %s in %s(%s), retrying at %s", err.DataSource, err.JobName, err.InstanceName, err.RetryAt.Format(time.RFC3339))
+}
+
+// IsCircuitOpen return true if err is a CircuitOpenError
+func IsCircuitOpen(err error) bool {
+	var circuitOpenErr CircuitOpenError
+	return errors.As(err, &circuitOpenErr)
+}
+
+// circuitState is the state of a circuit breaker, the values are the ones exposed in the state metric
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (state circuitState) String() string {
+	switch state {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreakerDef describe when a circuit is opened and for how long
+type circuitBreakerDef struct {
+	failureThreshold int
+	coolDown         time.Duration
+}
+
+// defaultCircuitBreakerDef open the circuit after 5 consecutive failures for 30 seconds
+var defaultCircuitBreakerDef = circuitBreakerDef{
+	failureThreshold: 5,
+	coolDown:         30 * time.Second,
+}
+
+// circuitBreakerDefFromOptions read the circuit breaker settings, a value in resOpts(can be nil) override the
+// one in srvOpts, defaults are used for the missing ones
+func circuitBreakerDefFromOptions(resOpts, srvOpts config.RextKeyValueStore) (def circuitBreakerDef, err error) {
+	def = defaultCircuitBreakerDef
+	if iVal, found := optionFor(resOpts, srvOpts, config.OptKeyRextCircuitBreakerFailureThreshold); found {
+		var okVal bool
+		if def.failureThreshold, okVal = iVal.(int); !okVal {
+			log.WithField("val", iVal).Errorln("circuit breaker failure threshold should be an int")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	if iVal, found := optionFor(resOpts, srvOpts, config.OptKeyRextCircuitBreakerCoolDown); found {
+		var okVal bool
+		if def.coolDown, okVal = iVal.(time.Duration); !okVal {
+			log.WithField("val", iVal).Errorln("circuit breaker cool down should be a time.Duration")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	return def, nil
+}
+
+// circuitBreaker stop making requests to a data source after some consecutive failures, when the cool down
+// finish a single probe request is allowed(half-open state) to decide if the circuit is closed again
+type circuitBreaker struct {
+	circuitBreakerDef
+	jobName       string
+	instanceName  string
+	dataSource    string
+	clientMetrics *metrics.DefaultClientMetrics
+	mutex         *sync.Mutex
+	state         circuitState
+	failures      int
+	openedAt      time.Time
+	probing       bool
+}
+
+var (
+	circuitBreakersMutex = &sync.Mutex{}
+	circuitBreakers      = make(map[string]*circuitBreaker)
+)
+
+// circuitBreakerFor return the circuit breaker for a data source, it is created if not exist, clientMetrics can
+// be nil
+func circuitBreakerFor(resOpts, srvOpts config.RextKeyValueStore, jobName, instanceName, dataSource string, clientMetrics *metrics.DefaultClientMetrics) (cb *circuitBreaker, err error) {
+	var def circuitBreakerDef
+	if def, err = circuitBreakerDefFromOptions(resOpts, srvOpts); err != nil {
+		return nil, err
+	}
+	circuitBreakersMutex.Lock()
+	defer circuitBreakersMutex.Unlock()
+	key := jobName + "|" + instanceName + "|" + dataSource
+	if cb, found := circuitBreakers[key]; found {
+		return cb, nil
+	}
+	cb = &circuitBreaker{
+		circuitBreakerDef: def,
+		jobName:           jobName,
+		instanceName:      instanceName,
+		dataSource:        dataSource,
+		clientMetrics:     clientMetrics,
+		mutex:             &sync.Mutex{},
+	}
+	cb.setState(circuitClosed)
+	circuitBreakers[key] = cb
+	return cb, nil
+}
+
+// resetCircuitBreakers drop all the circuit breakers, the circuits start closed again
+func resetCircuitBreakers() {
+	circuitBreakersMutex.Lock()
+	defer circuitBreakersMutex.Unlock()
+	circuitBreakers = make(map[string]*circuitBreaker)
+}
+
+// setState change the state and update the state metric, the mutex should be locked
+func (cb *circuitBreaker) setState(state circuitState) {
+	if cb.state != state {
+		log.WithFields(log.Fields{
+			"job":         cb.jobName,
+			"instance":    cb.instanceName,
+			"data_source": cb.dataSource,
+			"from":        cb.state,
+			"to":          state,
+		}).Warnln("circuit state changed")
+	}
+	cb.state = state
+	if cb.clientMetrics != nil {
+		labels := []string{cb.jobName, cb.instanceName, cb.dataSource}
+		cb.clientMetrics.DataSourceCircuitState.WithLabelValues(labels...).Set(float64(state))
+	}
+}
+
+// allow return a CircuitOpenError if a request should not be made, probe is true if the request allowed is the
+// one deciding if an open circuit is closed again
+func (cb *circuitBreaker) allow() (probe bool, err error) {
+	if cb.failureThreshold <= 0 {
+		return false, nil
+	}
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	retryAt := cb.openedAt.Add(cb.coolDown)
+	switch cb.state {
+	case circuitOpen:
+		if time.Now().Before(retryAt) {
+			break
+		}
+		cb.setState(circuitHalfOpen)
+		cb.probing = true
+		return true, nil
+	case circuitHalfOpen:
+		if cb.probing {
+			break
+		}
+		cb.probing = true
+		return true, nil
+	default:
+		return false, nil
+	}
+	return false, CircuitOpenError{JobName: cb.jobName, InstanceName: cb.instanceName, DataSource: cb.dataSource, RetryAt: retryAt}
+}
+
+// record update the circuit with the result of a request allowed before, probe is the value returned by allow for
+// the request and ignored is true if the request was cancelled for a reason unrelated to the data source. While the
+// circuit is not closed only the probe result is considered, the other ones are from requests made before opening
+// it.
+func (cb *circuitBreaker) record(probe bool, err error, ignored bool) {
+	if cb.failureThreshold <= 0 {
+		return
+	}
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	if probe {
+		cb.probing = false
+	} else if cb.state != circuitClosed {
+		return
+	}
+	if ignored {
+		return
+	}
+	if err == nil {
+		cb.failures = 0
+		cb.setState(circuitClosed)
+		return
+	}
+	cb.failures++
+	if cb.state == circuitHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.openedAt = time.Now()
+		cb.setState(circuitOpen)
+	}
+}
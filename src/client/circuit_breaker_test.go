@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type circuitBreakerSuit struct {
+	suite.Suite
+	testServer    *httptest.Server
+	clientMetrics *metrics.DefaultClientMetrics
+	healthy       int32
+	requests      int32
+}
+
+func (suite *circuitBreakerSuit) SetupTest() {
+	// NOTE(denisacostaq@gmail.com): each test start with the circuits closed
+	resetCircuitBreakers()
+	suite.clientMetrics = metrics.NewDefaultClientMetrics()
+	suite.healthy = 0
+	suite.requests = 0
+	suite.testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&suite.requests, 1)
+		if atomic.LoadInt32(&suite.healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+}
+
+func (suite *circuitBreakerSuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestCircuitBreakerSuit(t *testing.T) {
+	suite.Run(t, new(circuitBreakerSuit))
+}
+
+func (suite *circuitBreakerSuit) client(jobName string, srvOpts map[string]interface{}) Client {
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		jobName:      jobName,
+		instanceName: "localhost:6420",
+		srvOpts:      srvOpts,
+		resType:      config.ResourceTypeRestAPI,
+		resPath:      "/api/v1/health",
+		resOpts:      map[string]interface{}{config.OptKeyRextResourceDefHTTPMethod: "GET"},
+	}.build(suite.T())
+	cf, err := CreateAPIRestCreator(res, srv, desc, suite.clientMetrics)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl
+}
+
+func (suite *circuitBreakerSuit) getData(cl Client) error {
+	_, err := cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+	return err
+}
+
+func (suite *circuitBreakerSuit) state(jobName string) float64 {
+	var metric io_prometheus_client.Metric
+	gauge := suite.clientMetrics.DataSourceCircuitState.WithLabelValues(jobName, "localhost:6420", "/api/v1/health")
+	suite.Require().Nil(gauge.Write(&metric))
+	return metric.GetGauge().GetValue()
+}
+
+func circuitBreakerOpts() map[string]interface{} {
+	return map[string]interface{}{
+		config.OptKeyRextCircuitBreakerFailureThreshold: 2,
+		config.OptKeyRextCircuitBreakerCoolDown:         50 * time.Millisecond,
+	}
+}
+
+func (suite *circuitBreakerSuit) TestOpenAfterConsecutiveFailures() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("circuit_open", circuitBreakerOpts())
+
+	// NOTE(denisacostaq@gmail.com): When
+	err1 := suite.getData(cl)
+	err2 := suite.getData(cl)
+	err3 := suite.getData(cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err1)
+	suite.False(IsCircuitOpen(err1))
+	suite.NotNil(err2)
+	suite.False(IsCircuitOpen(err2))
+	suite.True(IsCircuitOpen(err3))
+	suite.Equal(int32(2), suite.requests)
+	suite.Equal(float64(circuitOpen), suite.state("circuit_open"))
+}
+
+func (suite *circuitBreakerSuit) TestCloseAfterSuccessfulProbe() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("circuit_close", circuitBreakerOpts())
+	suite.NotNil(suite.getData(cl))
+	suite.NotNil(suite.getData(cl))
+	suite.Equal(float64(circuitOpen), suite.state("circuit_close"))
+	atomic.StoreInt32(&suite.healthy, 1)
+	time.Sleep(60 * time.Millisecond)
+
+	// NOTE(denisacostaq@gmail.com): When
+	err := suite.getData(cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(int32(3), suite.requests)
+	suite.Equal(float64(circuitClosed), suite.state("circuit_close"))
+}
+
+func (suite *circuitBreakerSuit) TestReopenAfterFailedProbe() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("circuit_reopen", circuitBreakerOpts())
+	suite.NotNil(suite.getData(cl))
+	suite.NotNil(suite.getData(cl))
+	time.Sleep(60 * time.Millisecond)
+
+	// NOTE(denisacostaq@gmail.com): When
+	err1 := suite.getData(cl)
+	err2 := suite.getData(cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(IsCircuitOpen(err1))
+	suite.True(IsCircuitOpen(err2))
+	suite.Equal(int32(3), suite.requests)
+	suite.Equal(float64(circuitOpen), suite.state("circuit_reopen"))
+}
+
+func (suite *circuitBreakerSuit) TestResetSharedStateCloseCircuits() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("circuit_reset", circuitBreakerOpts())
+	suite.NotNil(suite.getData(cl))
+	suite.NotNil(suite.getData(cl))
+	suite.True(IsCircuitOpen(suite.getData(cl)))
+
+	// NOTE(denisacostaq@gmail.com): When
+	ResetSharedState()
+	err := suite.getData(suite.client("circuit_reset", circuitBreakerOpts()))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(IsCircuitOpen(err))
+	suite.Equal(int32(3), suite.requests)
+}
+
+func (suite *circuitBreakerSuit) TestOnlyTheProbeFinishProbing() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cb := &circuitBreaker{circuitBreakerDef: circuitBreakerDef{failureThreshold: 1}, mutex: &sync.Mutex{}}
+	stale, err := cb.allow()
+	suite.Nil(err)
+	failing, err := cb.allow()
+	suite.Nil(err)
+	cb.record(failing, errors.New("unavailable"), false)
+	probe, err := cb.allow()
+	suite.Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	cb.record(stale, nil, false)
+	_, errWhileProbing := cb.allow()
+	cb.record(probe, nil, false)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(stale)
+	suite.True(probe)
+	suite.True(IsCircuitOpen(errWhileProbing))
+	suite.Equal(circuitClosed, cb.state)
+}
+
+func (suite *circuitBreakerSuit) TestDisabled() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("circuit_disabled", map[string]interface{}{
+		config.OptKeyRextCircuitBreakerFailureThreshold: 0,
+	})
+
+	// NOTE(denisacostaq@gmail.com): When
+	for i := 0; i < 10; i++ {
+		suite.False(IsCircuitOpen(suite.getData(cl)))
+	}
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal(int32(10), suite.requests)
+}
@@ -1,18 +1,49 @@
 package client
 
 import (
+	"context"
+	"sync/atomic"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
 )
 
 // Client to get remote data.
 type Client interface {
-	// GetData will get tha date based on a URL(but can be a cached value for example).
-	GetData(metricsCollector chan<- prometheus.Metric) (body []byte, err error)
+	// GetData will get tha date based on a URL(but can be a cached value for example), the request is
+	// cancelled if ctx is done.
+	GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (body []byte, err error)
+}
+
+// DocumentDecoder decode a body in a document where the nodes can be found
+type DocumentDecoder func(body []byte) (doc interface{}, err error)
+
+// DocumentClient can share the document decoded from the data, so a body is decoded once for all the metrics
+// reading it in a scrape
+type DocumentClient interface {
+	Client
+	// GetDocument return the data decoded with decode, decoderKey identify the decoder between the ones used for
+	// the same data
+	GetDocument(ctx context.Context, metricsCollector chan<- prometheus.Metric, decoderKey string, decode DocumentDecoder) (doc interface{}, err error)
 }
 
 // FordwaderClient a client to get metrics from a metrics endpoint
 type FordwaderClient interface {
-	GetData() (body []byte, err error)
+	GetData(ctx context.Context) (body []byte, err error)
+}
+
+// optionFor return the value for key in resOpts(can be nil) or in srvOpts if not present, found is false if it is
+// not in any of them
+func optionFor(resOpts, srvOpts config.RextKeyValueStore, key string) (val interface{}, found bool) {
+	for _, opts := range []config.RextKeyValueStore{resOpts, srvOpts} {
+		if opts == nil {
+			continue
+		}
+		if val, err := opts.GetObject(key); err == nil {
+			return val, true
+		}
+	}
+	return nil, false
 }
 
 type baseClient struct {
@@ -57,4 +88,22 @@ func (cl baseCacheableClient) DataPath() string {
 	return string(cl)
 }
 
+// scrapeKey is the context key for the scrape number
+type scrapeKey struct{}
+
+// lastScrape is the number of the last scrape started
+var lastScrape uint64
+
+// NewScrapeContext return a copy of ctx for a new scrape, the clients sharing a request between the data sources
+// in a scrape(like the json-rpc batches) make it again for each scrape
+func NewScrapeContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scrapeKey{}, atomic.AddUint64(&lastScrape, 1))
+}
+
+// scrapeFrom return the scrape number in ctx, 0 if ctx is not from a scrape
+func scrapeFrom(ctx context.Context) uint64 {
+	scrape, _ := ctx.Value(scrapeKey{}).(uint64)
+	return scrape
+}
+
 // TODO(denisacostaq@gmail.com): check out http://localhost:6060/pkg/github.com/prometheus/client_golang/api/#NewClient
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"context"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/simelo/rextporter/src/cache"
 )
@@ -28,12 +30,17 @@ type Catcher struct {
 }
 
 // GetData return the data, can be from local cache or making the original request
-func (cl Catcher) GetData(metricsCollector chan<- prometheus.Metric) (body []byte, err error) {
+func (cl Catcher) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (body []byte, err error) {
 	if body, err = cl.cache.Get(cl.dataKey); err == nil {
 		return body, err
 	}
 	cl.cache.Lock()
 	defer cl.cache.Unlock()
+	return cl.getDataLocked(ctx, metricsCollector)
+}
+
+// getDataLocked return the data from the cache or making the original request, the cache should be locked
+func (cl Catcher) getDataLocked(ctx context.Context, metricsCollector chan<- prometheus.Metric) (body []byte, err error) {
 	if body, err = cl.cache.Get(cl.dataKey); err == nil {
 		return body, err
 	}
@@ -41,8 +48,30 @@ func (cl Catcher) GetData(metricsCollector chan<- prometheus.Metric) (body []byt
 	if ccl, err = cl.clientFactory.CreateClient(); err != nil {
 		return nil, err
 	}
-	if body, err = ccl.GetData(metricsCollector); err == nil {
+	if body, err = ccl.GetData(ctx, metricsCollector); err == nil {
 		cl.cache.Set(cl.dataKey, body)
 	}
 	return body, err
 }
+
+// GetDocument return the decoded data, can be from local cache or decoding the data once for all the metrics
+// reading it until the cache is reset
+func (cl Catcher) GetDocument(ctx context.Context, metricsCollector chan<- prometheus.Metric, decoderKey string, decode DocumentDecoder) (doc interface{}, err error) {
+	docKey := cl.dataKey + "#" + decoderKey
+	if doc, err = cl.cache.GetDocument(docKey); err == nil {
+		return doc, err
+	}
+	cl.cache.Lock()
+	defer cl.cache.Unlock()
+	if doc, err = cl.cache.GetDocument(docKey); err == nil {
+		return doc, err
+	}
+	var body []byte
+	if body, err = cl.getDataLocked(ctx, metricsCollector); err != nil {
+		return nil, err
+	}
+	if doc, err = decode(body); err == nil {
+		cl.cache.SetDocument(docKey, doc)
+	}
+	return doc, err
+}
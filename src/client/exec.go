@@ -0,0 +1,263 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// execDef describe a command to run
+type execDef struct {
+	command       string
+	args          []string
+	env           []string
+	dir           string
+	timeout       time.Duration
+	maxConcurrent int
+}
+
+// defaultExecDef run a command with up to 10 seconds and without concurrent runs
+var defaultExecDef = execDef{
+	timeout:       10 * time.Second,
+	maxConcurrent: 1,
+}
+
+// execDefFromOptions read the command settings from the resource options, defaults are used for the missing ones
+func execDefFromOptions(command string, resOpts config.RextKeyValueStore) (def execDef, err error) {
+	def = defaultExecDef
+	def.command = command
+	strSlices := []struct {
+		key string
+		val *[]string
+	}{
+		{key: config.OptKeyRextResourceDefExecArgs, val: &def.args},
+		{key: config.OptKeyRextResourceDefExecEnv, val: &def.env},
+	}
+	for _, strSlice := range strSlices {
+		if iVal, err := resOpts.GetObject(strSlice.key); err == nil {
+			var okVal bool
+			if *strSlice.val, okVal = iVal.([]string); !okVal {
+				log.WithFields(log.Fields{"key": strSlice.key, "val": iVal}).Errorln("value should be an []string")
+				return def, config.ErrKeyInvalidType
+			}
+		}
+	}
+	def.dir, _ = resOpts.GetString(config.OptKeyRextResourceDefExecDir)
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefExecTimeout); err == nil {
+		var okVal bool
+		if def.timeout, okVal = iVal.(time.Duration); !okVal {
+			log.WithField("val", iVal).Errorln("exec timeout should be a time.Duration")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefExecMaxConcurrent); err == nil {
+		var okVal bool
+		if def.maxConcurrent, okVal = iVal.(int); !okVal {
+			log.WithField("val", iVal).Errorln("exec max concurrent should be an int")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	return def, nil
+}
+
+// key identify the command, the runs with the same key are limited together
+func (def execDef) key() string {
+	return strings.Join(append([]string{def.dir, def.command}, def.args...), "\x00")
+}
+
+var (
+	execSlotsMutex = &sync.Mutex{}
+	execSlots      = make(map[string]chan struct{})
+)
+
+// resetExecSlots drop all the semaphores for the concurrent runs, the runs in progress release the old ones
+func resetExecSlots() {
+	execSlotsMutex.Lock()
+	defer execSlotsMutex.Unlock()
+	execSlots = make(map[string]chan struct{})
+}
+
+// execSlotsFor return the semaphore for the concurrent runs of a command, it is created if not exist
+func execSlotsFor(def execDef) chan struct{} {
+	execSlotsMutex.Lock()
+	defer execSlotsMutex.Unlock()
+	key := def.key()
+	if slots, found := execSlots[key]; found {
+		return slots
+	}
+	maxConcurrent := def.maxConcurrent
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	slots := make(chan struct{}, maxConcurrent)
+	execSlots[key] = slots
+	return slots
+}
+
+// ExecCreator have info to create a command execution client
+type ExecCreator struct {
+	baseFactory
+	def           execDef
+	slots         chan struct{}
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+// CreateExecCreator create an ExecCreator, the resource path is the command to run
+func CreateExecCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf CacheableFactory, err error) {
+	command := strings.TrimPrefix(resConf.GetResourcePATH(srvConf.GetBasePath()), srvConf.GetBasePath())
+	srvOpts := srvConf.GetOptions()
+	jobName, err := srvOpts.GetString(config.OptKeyRextServiceDefJobName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find jobName")
+		return cf, err
+	}
+	instanceName, err := srvOpts.GetString(config.OptKeyRextServiceDefInstanceName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find instanceName")
+		return cf, err
+	}
+	var def execDef
+	if def, err = execDefFromOptions(command, resConf.GetOptions()); err != nil {
+		log.WithError(err).Errorln("Can not read the exec settings")
+		return cf, err
+	}
+	cf = ExecCreator{
+		baseFactory: baseFactory{
+			jobName:                        jobName,
+			instanceName:                   instanceName,
+			dataSource:                     command,
+			dataSourceResponseDurationDesc: dataSourceResponseDurationDesc,
+		},
+		def:           def,
+		slots:         execSlotsFor(def),
+		clientMetrics: cDefMetrics,
+	}
+	return cf, nil
+}
+
+// CreateClient create a command execution client
+func (ec ExecCreator) CreateClient() (cl CacheableClient, err error) {
+	cl = Exec{
+		baseClient: baseClient{
+			jobName:                        ec.jobName,
+			instanceName:                   ec.instanceName,
+			dataSource:                     ec.dataSource,
+			dataSourceResponseDurationDesc: ec.dataSourceResponseDurationDesc,
+		},
+		baseCacheableClient: baseCacheableClient("exec://" + ec.jobName + "/" + ec.def.key()),
+		def:                 ec.def,
+		slots:               ec.slots,
+		clientMetrics:       ec.clientMetrics,
+	}
+	return cl, nil
+}
+
+// Exec run a command and return the standard output as the data
+type Exec struct {
+	baseClient
+	baseCacheableClient
+	def           execDef
+	slots         chan struct{}
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+// GetData run the command, an error is returned if it can not be started, it exit with a non zero code or does
+// not finish before the timeout(or ctx is done)
+func (cl Exec) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	const generalScopeErr = "error running a command to get metric"
+	select {
+	case cl.slots <- struct{}{}:
+		defer func() { <-cl.slots }()
+	case <-ctx.Done():
+		errCause := fmt.Sprintln("no time left waiting for other runs of the command: ", ctx.Err().Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	ctx, cancel := context.WithTimeout(ctx, cl.def.timeout)
+	defer cancel()
+	cmd := exec.Command(cl.def.command, cl.def.args...)
+	cmd.Env = append(os.Environ(), cl.def.env...)
+	cmd.Dir = cl.def.dir
+	startTime := time.Now()
+	var stdout, stderr bytes.Buffer
+	err = runCommand(ctx, cmd, &stdout, &stderr)
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	if cl.clientMetrics != nil {
+		labels := []string{cl.jobName, cl.instanceName, cl.dataSource}
+		cl.clientMetrics.DataSourceExecExitCode.WithLabelValues(labels...).Set(float64(exitCode))
+		cl.clientMetrics.DataSourceExecDuration.WithLabelValues(labels...).Set(time.Since(startTime).Seconds())
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":       err,
+			"command":   cl.def.command,
+			"args":      cl.def.args,
+			"exit_code": exitCode,
+			"stderr":    stderr.String(),
+		}).Errorln("command failed")
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		errCause := fmt.Sprintln("can not run the command: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return stdout.Bytes(), nil
+}
+
+// runCommand run cmd writing the output in stdout and stderr, when ctx is done the command and its children are
+// killed and the output pipes closed, so a child holding the output can not keep it running
+func runCommand(ctx context.Context, cmd *exec.Cmd, stdout, stderr io.Writer) (err error) {
+	setProcessGroup(cmd)
+	var stdoutPipe, stderrPipe io.ReadCloser
+	if stdoutPipe, err = cmd.StdoutPipe(); err != nil {
+		return err
+	}
+	if stderrPipe, err = cmd.StderrPipe(); err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	var copying sync.WaitGroup
+	copying.Add(2)
+	copyOutput := func(w io.Writer, r io.Reader) {
+		defer copying.Done()
+		if _, err := io.Copy(w, r); err != nil && ctx.Err() == nil {
+			log.WithError(err).Errorln("can not read the command output")
+		}
+	}
+	go copyOutput(stdout, stdoutPipe)
+	go copyOutput(stderr, stderrPipe)
+	copied := make(chan struct{})
+	go func() {
+		copying.Wait()
+		close(copied)
+	}()
+	select {
+	case <-copied:
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		// NOTE(denisacostaq@gmail.com): a child out of the process group can still hold the output
+		stdoutPipe.Close()
+		stderrPipe.Close()
+		<-copied
+	}
+	// NOTE(denisacostaq@gmail.com): Wait should be called after reading all the output
+	return cmd.Wait()
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type execSuit struct {
+	suite.Suite
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+func (suite *execSuit) SetupTest() {
+	// NOTE(denisacostaq@gmail.com): each test start without running commands
+	resetExecSlots()
+	suite.clientMetrics = metrics.NewDefaultClientMetrics()
+}
+
+func TestExecSuit(t *testing.T) {
+	suite.Run(t, new(execSuit))
+}
+
+func (suite *execSuit) client(command string, resOpts map[string]interface{}) CacheableClient {
+	srv, res, desc := clientFixture{
+		protocol:     config.ProtocolExec,
+		jobName:      "cli",
+		instanceName: "localhost",
+		resType:      config.ResourceTypeExec,
+		resPath:      command,
+		resOpts:      resOpts,
+	}.build(suite.T())
+	cf, err := CreateExecCreator(res, srv, desc, suite.clientMetrics)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl
+}
+
+func (suite *execSuit) getData(cl Client) ([]byte, error) {
+	return cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+}
+
+func (suite *execSuit) exitCode() float64 {
+	var metric io_prometheus_client.Metric
+	gauge := suite.clientMetrics.DataSourceExecExitCode.WithLabelValues("cli", "localhost", "sh")
+	suite.Require().Nil(gauge.Write(&metric))
+	return metric.GetGauge().GetValue()
+}
+
+func shOpts(script string) map[string]interface{} {
+	return map[string]interface{}{config.OptKeyRextResourceDefExecArgs: []string{"-c", script}}
+}
+
+func (suite *execSuit) TestStdoutIsTheData() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	opts := shOpts(`echo "{\"seq\": $SEQ}"; echo ignored >&2`)
+	opts[config.OptKeyRextResourceDefExecEnv] = []string{"SEQ=58"}
+	cl := suite.client("sh", opts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal("{\"seq\": 58}\n", string(data))
+	suite.Equal(float64(0), suite.exitCode())
+}
+
+func (suite *execSuit) TestWorkingDir() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	opts := shOpts("pwd")
+	opts[config.OptKeyRextResourceDefExecDir] = "/"
+	cl := suite.client("sh", opts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal("/\n", string(data))
+}
+
+func (suite *execSuit) TestExitCode() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("sh", shOpts("echo partial; exit 3"))
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Nil(data)
+	suite.Equal(float64(3), suite.exitCode())
+}
+
+func (suite *execSuit) TestTimeout() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	opts := shOpts("sleep 2")
+	opts[config.OptKeyRextResourceDefExecTimeout] = 50 * time.Millisecond
+	cl := suite.client("sh", opts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	startTime := time.Now()
+	_, err := suite.getData(cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.True(time.Since(startTime) < time.Second)
+	suite.Equal(float64(-1), suite.exitCode())
+}
+
+func (suite *execSuit) TestTimeoutKillTheChildrenHoldingTheOutput() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	dir, err := ioutil.TempDir("", "rextporter-exec")
+	suite.Require().Nil(err)
+	defer os.RemoveAll(dir)
+	mark := filepath.Join(dir, "mark")
+	opts := shOpts(`(sleep 0.3; touch "$MARK") & echo started; sleep 5`)
+	opts[config.OptKeyRextResourceDefExecEnv] = []string{"MARK=" + mark}
+	opts[config.OptKeyRextResourceDefExecTimeout] = 100 * time.Millisecond
+	cl := suite.client("sh", opts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	startTime := time.Now()
+	data, err := suite.getData(cl)
+	elapsed := time.Since(startTime)
+	time.Sleep(500 * time.Millisecond)
+	_, errMark := os.Stat(mark)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Nil(data)
+	suite.True(elapsed < time.Second, elapsed)
+	suite.True(os.IsNotExist(errMark), errMark)
+	suite.Equal(float64(-1), suite.exitCode())
+}
+
+func (suite *execSuit) TestCommandNotFound() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("rextporter-missing-command", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+}
+
+func (suite *execSuit) TestConcurrentRunsAreLimited() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("sh", shOpts("sleep 0.1"))
+
+	// NOTE(denisacostaq@gmail.com): When
+	startTime := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := suite.getData(cl)
+			suite.Nil(err)
+		}()
+	}
+	wg.Wait()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.True(time.Since(startTime) >= 300*time.Millisecond)
+}
+
+func (suite *execSuit) TestResetSharedStateApplyNewConcurrencyLimit() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	opts := shOpts("sleep 0.1")
+	_ = suite.client("sh", opts)
+	opts[config.OptKeyRextResourceDefExecMaxConcurrent] = 3
+
+	// NOTE(denisacostaq@gmail.com): When
+	ResetSharedState()
+	cl := suite.client("sh", opts)
+	startTime := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := suite.getData(cl)
+			suite.Nil(err)
+		}()
+	}
+	wg.Wait()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.True(time.Since(startTime) < 250*time.Millisecond)
+}
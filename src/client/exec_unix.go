@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package client
+
+import (
+	"os/exec"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// setProcessGroup run the command in a new process group, so it can be killed with its children
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kill the started command and its children
+func killProcessGroup(cmd *exec.Cmd) {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		log.WithError(err).Errorln("can not kill the command process group")
+	}
+}
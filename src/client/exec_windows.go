@@ -0,0 +1,18 @@
+package client
+
+import (
+	"os/exec"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// setProcessGroup do nothing, the commands are killed without their children in windows
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+// killProcessGroup kill the started command
+func killProcessGroup(cmd *exec.Cmd) {
+	if err := cmd.Process.Kill(); err != nil {
+		log.WithError(err).Errorln("can not kill the command")
+	}
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultFileMaxSize is the maximum size for a file data source if no one is configured
+const defaultFileMaxSize = 10 * 1024 * 1024
+
+// FileCreator have info to create a file client
+type FileCreator struct {
+	baseFactory
+	pattern       string
+	maxSize       int64
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+// CreateFileCreator create a FileCreator, the resource path is a file path or a glob pattern
+func CreateFileCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf CacheableFactory, err error) {
+	pattern := resConf.GetResourcePATH(srvConf.GetBasePath())
+	if _, err = filepath.Match(pattern, ""); err != nil {
+		log.WithError(err).WithField("pattern", pattern).Errorln("Invalid file pattern")
+		return cf, err
+	}
+	srvOpts := srvConf.GetOptions()
+	jobName, err := srvOpts.GetString(config.OptKeyRextServiceDefJobName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find jobName")
+		return cf, err
+	}
+	instanceName, err := srvOpts.GetString(config.OptKeyRextServiceDefInstanceName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find instanceName")
+		return cf, err
+	}
+	var maxSize int64 = defaultFileMaxSize
+	if iVal, found := optionFor(resConf.GetOptions(), srvOpts, config.OptKeyRextResourceDefFileMaxSize); found {
+		val, okVal := iVal.(int)
+		if !okVal {
+			log.WithField("val", iVal).Errorln("file max size should be an int")
+			return cf, config.ErrKeyInvalidType
+		}
+		maxSize = int64(val)
+	}
+	cf = FileCreator{
+		baseFactory: baseFactory{
+			jobName:                        jobName,
+			instanceName:                   instanceName,
+			dataSource:                     strings.TrimPrefix(pattern, srvConf.GetBasePath()),
+			dataSourceResponseDurationDesc: dataSourceResponseDurationDesc,
+		},
+		pattern:       pattern,
+		maxSize:       maxSize,
+		clientMetrics: cDefMetrics,
+	}
+	return cf, nil
+}
+
+// CreateClient create a file client
+func (fc FileCreator) CreateClient() (cl CacheableClient, err error) {
+	cl = File{
+		baseClient: baseClient{
+			jobName:                        fc.jobName,
+			instanceName:                   fc.instanceName,
+			dataSource:                     fc.dataSource,
+			dataSourceResponseDurationDesc: fc.dataSourceResponseDurationDesc,
+		},
+		baseCacheableClient: baseCacheableClient(fc.pattern),
+		pattern:             fc.pattern,
+		maxSize:             fc.maxSize,
+		clientMetrics:       fc.clientMetrics,
+	}
+	return cl, nil
+}
+
+// File read a data source from the local filesystem, if the pattern match more than one file the last
+// modified one is used
+type File struct {
+	baseClient
+	baseCacheableClient
+	pattern       string
+	maxSize       int64
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+// newestFile return the last modified file matching pattern
+func newestFile(pattern string) (path string, info os.FileInfo, err error) {
+	var matches []string
+	if matches, err = filepath.Glob(pattern); err != nil {
+		return "", nil, err
+	}
+	for _, match := range matches {
+		var matchInfo os.FileInfo
+		if matchInfo, err = os.Stat(match); err != nil {
+			return "", nil, err
+		}
+		if matchInfo.IsDir() {
+			continue
+		}
+		if info == nil || matchInfo.ModTime().After(info.ModTime()) {
+			path, info = match, matchInfo
+		}
+	}
+	if info == nil {
+		return "", nil, os.ErrNotExist
+	}
+	return path, info, nil
+}
+
+// GetData read the file content, an error is returned if it is bigger than the max size
+func (cl File) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	const generalScopeErr = "error reading a file to get metric"
+	if err = ctx.Err(); err != nil {
+		errCause := fmt.Sprintln("no time left to read the file: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	startTime := time.Now().UTC()
+	path, info, err := newestFile(cl.pattern)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err, "pattern": cl.pattern}).Errorln("can not find the file")
+		errCause := fmt.Sprintln("can not find the file: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		errCause := fmt.Sprintln("can not open the file: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	defer file.Close()
+	// NOTE(denisacostaq@gmail.com): the size is checked while reading, files like the ones in /proc report 0
+	if data, err = ioutil.ReadAll(io.LimitReader(file, cl.maxSize+1)); err != nil {
+		errCause := fmt.Sprintln("can not read the file: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if int64(len(data)) > cl.maxSize {
+		log.WithFields(log.Fields{"path": path, "max_size": cl.maxSize}).Errorln("file too big")
+		errCause := fmt.Sprintf("file %s is bigger than %d bytes", path, cl.maxSize)
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	labels := []string{cl.jobName, cl.instanceName, cl.dataSource}
+	if cl.clientMetrics != nil {
+		cl.clientMetrics.DataSourceFileAge.WithLabelValues(labels...).Set(time.Since(info.ModTime()).Seconds())
+	}
+	duration := time.Since(startTime).Seconds()
+	if metric, err := prometheus.NewConstMetric(cl.dataSourceResponseDurationDesc, prometheus.GaugeValue, duration, labels...); err == nil {
+		metricsCollector <- metric
+	} else {
+		log.WithFields(log.Fields{"err": err, "labels": labels}).Errorln("can not send dataSource response duration reading a file")
+	}
+	return data, nil
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type fileSuit struct {
+	suite.Suite
+	dir           string
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+func (suite *fileSuit) SetupTest() {
+	var err error
+	suite.dir, err = ioutil.TempDir("", "rextporter")
+	suite.Require().Nil(err)
+	suite.clientMetrics = metrics.NewDefaultClientMetrics()
+}
+
+func (suite *fileSuit) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func TestFileSuit(t *testing.T) {
+	suite.Run(t, new(fileSuit))
+}
+
+func (suite *fileSuit) writeFile(name, content string, modTime time.Time) {
+	path := filepath.Join(suite.dir, name)
+	suite.Require().Nil(ioutil.WriteFile(path, []byte(content), 0600))
+	suite.Require().Nil(os.Chtimes(path, modTime, modTime))
+}
+
+func (suite *fileSuit) getData(resPath string, resOpts map[string]interface{}) ([]byte, error) {
+	srv, res, desc := clientFixture{
+		location:     suite.dir,
+		protocol:     config.ProtocolFile,
+		jobName:      "batch",
+		instanceName: "localhost",
+		resType:      config.ResourceTypeFile,
+		resPath:      resPath,
+		resOpts:      resOpts,
+	}.build(suite.T())
+	cf, err := CreateFileCreator(res, srv, desc, suite.clientMetrics)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+}
+
+func (suite *fileSuit) age(dataSource string) float64 {
+	var metric io_prometheus_client.Metric
+	gauge := suite.clientMetrics.DataSourceFileAge.WithLabelValues("batch", "localhost", dataSource)
+	suite.Require().Nil(gauge.Write(&metric))
+	return metric.GetGauge().GetValue()
+}
+
+func (suite *fileSuit) TestReadFile() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.writeFile("status.json", `{"processed": 10}`, time.Now().Add(-time.Minute))
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData("/status.json", nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{"processed": 10}`, string(data))
+	suite.True(suite.age("/status.json") >= 60)
+}
+
+func (suite *fileSuit) TestGlobUseNewestFile() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.writeFile("report-1.json", `{"run": 1}`, time.Now().Add(-2*time.Hour))
+	suite.writeFile("report-3.json", `{"run": 3}`, time.Now().Add(-time.Minute))
+	suite.writeFile("report-2.json", `{"run": 2}`, time.Now().Add(-time.Hour))
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData("/report-*.json", nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{"run": 3}`, string(data))
+	suite.True(suite.age("/report-*.json") < 3600)
+}
+
+func (suite *fileSuit) TestMaxSize() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.writeFile("big.json", `{"processed": 10}`, time.Now())
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err1 := suite.getData("/big.json", map[string]interface{}{config.OptKeyRextResourceDefFileMaxSize: 8})
+	_, err2 := suite.getData("/big.json", map[string]interface{}{config.OptKeyRextResourceDefFileMaxSize: 17})
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err1)
+	suite.Nil(err2)
+}
+
+func (suite *fileSuit) TestFileNotFound() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.writeFile("status.json", `{}`, time.Now())
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData("/missing-*.json", nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// GraphQLErrorEntry is an item in the errors list of a GraphQL response
+type GraphQLErrorEntry struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// GraphQLError is returned when the server answer a query with errors, even if some data was received
+type GraphQLError struct {
+	Errors []GraphQLErrorEntry
+}
+
+func (err GraphQLError) Error() string {
+	messages := make([]string, len(err.Errors))
+	for idx, entry := range err.Errors {
+		messages[idx] = entry.Message
+	}
+	return "graphql errors: " + strings.Join(messages, "; ")
+}
+
+// IsGraphQLError return true if err is a GraphQLError
+func IsGraphQLError(err error) bool {
+	var graphQLErr GraphQLError
+	return errors.As(err, &graphQLErr)
+}
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName,omitempty"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+}
+
+// graphQLResponse is the body of a GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage     `json:"data"`
+	Errors []GraphQLErrorEntry `json:"errors"`
+}
+
+// GraphQLCreator have info to create a GraphQL client
+type GraphQLCreator struct {
+	rest    APIRestCreator
+	request graphQLRequest
+}
+
+// CreateGraphQLCreator create a GraphQLCreator, the resource path is the GraphQL endpoint
+func CreateGraphQLCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf CacheableFactory, err error) {
+	resOpts := resConf.GetOptions()
+	var request graphQLRequest
+	if request.Query, err = resOpts.GetString(config.OptKeyRextResourceDefGraphQLQuery); err != nil {
+		log.WithError(err).Errorln("Can not find the graphql query")
+		return cf, err
+	}
+	request.OperationName, _ = resOpts.GetString(config.OptKeyRextResourceDefGraphQLOperationName)
+	if variables, err := resOpts.GetString(config.OptKeyRextResourceDefGraphQLVariables); err == nil && len(variables) != 0 {
+		request.Variables = json.RawMessage(variables)
+	}
+	var rest APIRestCreator
+	if rest, err = newAPIRestCreator(resConf, srvConf, http.MethodPost, dataSourceResponseDurationDesc, cDefMetrics); err != nil {
+		return cf, err
+	}
+	rest.contentType = "application/json"
+	if rest.body, err = json.Marshal(request); err != nil {
+		log.WithError(err).Errorln("Can not encode the graphql request")
+		return cf, err
+	}
+	return GraphQLCreator{rest: rest, request: request}, nil
+}
+
+// CreateClient create a GraphQL client
+func (gc GraphQLCreator) CreateClient() (cl CacheableClient, err error) {
+	var restCl CacheableClient
+	if restCl, err = gc.rest.CreateClient(); err != nil {
+		return cl, err
+	}
+	cl = GraphQL{
+		baseCacheableClient: baseCacheableClient(gc.rest.dataPath + "#" + string(gc.rest.body)),
+		rest:                restCl,
+	}
+	return cl, nil
+}
+
+// GraphQL post a query and return the data in the response
+type GraphQL struct {
+	baseCacheableClient
+	rest CacheableClient
+}
+
+// GetData post the query and return the data, a GraphQLError is returned if the response have errors
+func (cl GraphQL) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	const generalScopeErr = "error making a graphql request"
+	if data, err = cl.rest.GetData(ctx, metricsCollector); err != nil {
+		return nil, err
+	}
+	var resp graphQLResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		errCause := fmt.Sprintln("can not decode the response: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if len(resp.Errors) != 0 {
+		graphQLErr := GraphQLError{Errors: resp.Errors}
+		log.WithError(graphQLErr).Errorln("graphql error response")
+		return nil, graphQLErr
+	}
+	return resp.Data, nil
+}
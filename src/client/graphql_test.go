@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type graphQLSuit struct {
+	suite.Suite
+	testServer  *httptest.Server
+	lastRequest graphQLRequest
+	contentType string
+}
+
+func (suite *graphQLSuit) SetupTest() {
+	suite.testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.contentType = r.Header.Get("Content-Type")
+		suite.Nil(json.NewDecoder(r.Body).Decode(&suite.lastRequest))
+		if suite.lastRequest.Query == "{ broken }" {
+			w.Write([]byte(`{"data": {"broken": null}, "errors": [{"message": "cannot query field broken", "path": ["broken"]}]}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"blocks": [{"seq": 57}, {"seq": 58}]}}`))
+	}))
+}
+
+func (suite *graphQLSuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestGraphQLSuit(t *testing.T) {
+	suite.Run(t, new(graphQLSuit))
+}
+
+func (suite *graphQLSuit) getData(resOpts map[string]interface{}) ([]byte, error) {
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		jobName:      "explorer",
+		instanceName: "localhost:8001",
+		resType:      config.ResourceTypeGraphQL,
+		resPath:      "/graphql",
+		resOpts:      resOpts,
+	}.build(suite.T())
+	cf, err := CreateGraphQLCreator(res, srv, desc, metrics.NewDefaultClientMetrics())
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+}
+
+func (suite *graphQLSuit) TestDataIsTheResult() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefGraphQLQuery:         "query Blocks($last: Int) { blocks(last: $last) { seq } }",
+		config.OptKeyRextResourceDefGraphQLVariables:     `{"last": 2}`,
+		config.OptKeyRextResourceDefGraphQLOperationName: "Blocks",
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`{"blocks": [{"seq": 57}, {"seq": 58}]}`, string(data))
+	suite.Equal("application/json", suite.contentType)
+	suite.Equal("query Blocks($last: Int) { blocks(last: $last) { seq } }", suite.lastRequest.Query)
+	suite.Equal("Blocks", suite.lastRequest.OperationName)
+	suite.JSONEq(`{"last": 2}`, string(suite.lastRequest.Variables))
+}
+
+func (suite *graphQLSuit) TestErrorsFailTheScrape() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefGraphQLQuery: "{ broken }"}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(data)
+	suite.True(IsGraphQLError(err))
+	var graphQLErr GraphQLError
+	suite.True(errors.As(err, &graphQLErr))
+	suite.Len(graphQLErr.Errors, 1)
+	suite.Equal("cannot query field broken", graphQLErr.Errors[0].Message)
+}
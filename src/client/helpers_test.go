@@ -0,0 +1,43 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/require"
+)
+
+// clientFixture describe the service and the resource used to create a client in the tests
+type clientFixture struct {
+	location     string
+	protocol     string
+	auth         config.RextAuthDef
+	jobName      string
+	instanceName string
+	srvOpts      map[string]interface{}
+	resType      string
+	resPath      string
+	resOpts      map[string]interface{}
+}
+
+// build create the service, the resource and the response duration metric description of the fixture
+func (f clientFixture) build(t *testing.T) (srv config.RextServiceDef, res config.RextResourceDef, desc *prometheus.Desc) {
+	srv = memconfig.NewServiceConf(f.location, f.protocol, f.auth, nil, memconfig.NewOptionsMap())
+	_, err := srv.GetOptions().SetString(config.OptKeyRextServiceDefJobName, f.jobName)
+	require.Nil(t, err)
+	_, err = srv.GetOptions().SetString(config.OptKeyRextServiceDefInstanceName, f.instanceName)
+	require.Nil(t, err)
+	for k, v := range f.srvOpts {
+		_, err = srv.GetOptions().SetObject(k, v)
+		require.Nil(t, err)
+	}
+	res = memconfig.NewResourceDef(f.resType, f.resPath, nil, nil, nil, memconfig.NewOptionsMap())
+	for k, v := range f.resOpts {
+		_, err = res.GetOptions().SetObject(k, v)
+		require.Nil(t, err)
+	}
+	desc = prometheus.NewDesc("data_source_response_duration_seconds", "", []string{"job", "instance", "data_source"}, nil)
+	return srv, res, desc
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	hmacDefaultSignatureHeader = "X-Signature"
+	hmacDefaultTimestampHeader = "X-Timestamp"
+	hmacDefaultBodyHashHeader  = "X-Content-SHA256"
+)
+
+// hmacNow return the time used as timestamp to sign the requests
+var hmacNow = time.Now
+
+// signingStringData is the data available to render the signing string template
+type signingStringData struct {
+	Method    string
+	Path      string
+	Timestamp string
+	BodyHash  string
+}
+
+// hmacAuth implements the authStrategy for the HMAC auth type, each request is signed with a shared secret
+type hmacAuth struct {
+	secret          []byte
+	signingString   *template.Template
+	signatureHeader string
+	timestampHeader string
+	bodyHashHeader  string
+}
+
+func createHMACAuth(auth config.RextAuthDef) (strategy authStrategy, err error) {
+	const generalScopeErr = "error creating hmac auth"
+	authOpts := auth.GetOptions()
+	secret, err := authOpts.GetString(config.OptKeyRextAuthDefHMACSecret)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find hmac secret")
+		return strategy, err
+	}
+	// NOTE(denisacostaq@gmail.com): the options below are optional, a default value is used if not present
+	signingString, _ := authOpts.GetString(config.OptKeyRextAuthDefHMACSigningString)
+	if len(signingString) == 0 {
+		signingString = config.AuthHMACDefaultSigningString
+	}
+	var tmpl *template.Template
+	if tmpl, err = template.New("signingString").Parse(signingString); err != nil {
+		errCause := fmt.Sprintln("can not parse the signing string template: ", err.Error())
+		return strategy, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	ha := hmacAuth{
+		secret:          []byte(secret),
+		signingString:   tmpl,
+		signatureHeader: hmacDefaultSignatureHeader,
+		timestampHeader: hmacDefaultTimestampHeader,
+		bodyHashHeader:  hmacDefaultBodyHashHeader,
+	}
+	if header, _ := authOpts.GetString(config.OptKeyRextAuthDefHMACSignatureHeader); len(header) != 0 {
+		ha.signatureHeader = header
+	}
+	if header, _ := authOpts.GetString(config.OptKeyRextAuthDefHMACTimestampHeader); len(header) != 0 {
+		ha.timestampHeader = header
+	}
+	if header, _ := authOpts.GetString(config.OptKeyRextAuthDefHMACBodyHashHeader); len(header) != 0 {
+		ha.bodyHashHeader = header
+	}
+	return ha, nil
+}
+
+// sign return the hex encoded HMAC-SHA256 of the signing string and the body hash
+func (ha hmacAuth) sign(method, path string, timestamp int64, body []byte) (signature, bodyHash string, err error) {
+	const generalScopeErr = "error signing the request"
+	sum := sha256.Sum256(body)
+	bodyHash = hex.EncodeToString(sum[:])
+	data := signingStringData{
+		Method:    method,
+		Path:      path,
+		Timestamp: strconv.FormatInt(timestamp, 10),
+		BodyHash:  bodyHash,
+	}
+	var buff bytes.Buffer
+	if err = ha.signingString.Execute(&buff, data); err != nil {
+		errCause := fmt.Sprintln("can not execute the signing string template: ", err.Error())
+		return "", "", util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	mac := hmac.New(sha256.New, ha.secret)
+	mac.Write(buff.Bytes()) // nolint errcheck
+	return hex.EncodeToString(mac.Sum(nil)), bodyHash, nil
+}
+
+func (ha hmacAuth) prepareClient(httpClient *http.Client) {
+}
+
+func (ha hmacAuth) authenticate(ctx context.Context, req *http.Request, metricsCollector chan<- prometheus.Metric) (err error) {
+	const generalScopeErr = "error authenticating the request with hmac"
+	var body []byte
+	if req.GetBody != nil {
+		var bodyReader io.ReadCloser
+		if bodyReader, err = req.GetBody(); err != nil {
+			errCause := fmt.Sprintln("can not get the body: ", err.Error())
+			return util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		defer bodyReader.Close()
+		if body, err = ioutil.ReadAll(bodyReader); err != nil {
+			errCause := fmt.Sprintln("can not read the body: ", err.Error())
+			return util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	timestamp := hmacNow().Unix()
+	var signature, bodyHash string
+	if signature, bodyHash, err = ha.sign(req.Method, req.URL.RequestURI(), timestamp, body); err != nil {
+		errCause := fmt.Sprintln("can not sign the request: ", err.Error())
+		return util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	req.Header.Set(ha.timestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(ha.bodyHashHeader, bodyHash)
+	req.Header.Set(ha.signatureHeader, signature)
+	return nil
+}
+
+// rejected return false because a signature can not be renewed
+func (ha hmacAuth) rejected(resp *http.Response) bool {
+	return false
+}
+
+func (ha hmacAuth) renew(ctx context.Context, metricsCollector chan<- prometheus.Metric) error {
+	return nil
+}
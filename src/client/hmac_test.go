@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func newHMACAuth(t *testing.T, opts map[string]string) hmacAuth {
+	auth := memconfig.NewHTTPAuth(config.AuthTypeHMAC, "", memconfig.NewOptionsMap())
+	for k, v := range opts {
+		_, err := auth.GetOptions().SetString(k, v)
+		require.Nil(t, err)
+	}
+	strategy, err := createHMACAuth(auth)
+	require.Nil(t, err)
+	return strategy.(hmacAuth)
+}
+
+func TestHMACSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      map[string]string
+		method    string
+		path      string
+		body      string
+		signature string
+		bodyHash  string
+	}{
+		{
+			name:      "default signing string without body",
+			opts:      map[string]string{config.OptKeyRextAuthDefHMACSecret: "secret"},
+			method:    http.MethodGet,
+			path:      "/api/v1/health",
+			signature: "bc0da7a30858db25a679d07b996a21136ae2304da56274205afc7af5e3b49ab1",
+			bodyHash:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		{
+			name:      "default signing string with body and query",
+			opts:      map[string]string{config.OptKeyRextAuthDefHMACSecret: "secret"},
+			method:    http.MethodPost,
+			path:      "/api/v1/data?limit=10",
+			body:      `{"id":1}`,
+			signature: "fcf03864016efcbe65b5464ee1e80c111705eef98da22050f33a6a18601f32a6",
+			bodyHash:  "037c9214eef74cc3887f3a4f085b4e17d76280dafd273b0ee160c09c4ba1cfd4",
+		},
+		{
+			name: "custom signing string",
+			opts: map[string]string{
+				config.OptKeyRextAuthDefHMACSecret:        "another-secret",
+				config.OptKeyRextAuthDefHMACSigningString: "{{.Method}} {{.Path}} {{.Timestamp}}",
+			},
+			method:    http.MethodGet,
+			path:      "/api/v1/health",
+			signature: "8fbb77b2452b503175a44a16e10f992dd698efd18a816c9f59262e4134999a73",
+			bodyHash:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// NOTE(denisacostaq@gmail.com): Giving
+			ha := newHMACAuth(t, tc.opts)
+
+			// NOTE(denisacostaq@gmail.com): When
+			signature, bodyHash, err := ha.sign(tc.method, tc.path, 1548979200, []byte(tc.body))
+
+			// NOTE(denisacostaq@gmail.com): Assert
+			require.Nil(t, err)
+			require.Equal(t, tc.signature, signature)
+			require.Equal(t, tc.bodyHash, bodyHash)
+		})
+	}
+}
+
+func TestHMACHeadersInAPIRestRequest(t *testing.T) {
+	// NOTE(denisacostaq@gmail.com): Giving
+	hmacNow = func() time.Time { return time.Unix(1548979200, 0) }
+	defer func() { hmacNow = time.Now }()
+	var headers http.Header
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.Write([]byte(`{}`))
+	}))
+	defer testServer.Close()
+	auth := memconfig.NewHTTPAuth(config.AuthTypeHMAC, "", memconfig.NewOptionsMap())
+	_, err := auth.GetOptions().SetString(config.OptKeyRextAuthDefHMACSecret, "secret")
+	require.Nil(t, err)
+	_, err = auth.GetOptions().SetString(config.OptKeyRextAuthDefHMACSignatureHeader, "X-Api-Signature")
+	require.Nil(t, err)
+	srv, res, desc := clientFixture{
+		location:     testServer.URL,
+		protocol:     "http",
+		auth:         auth,
+		jobName:      "internal",
+		instanceName: "localhost:9000",
+		resType:      config.ResourceTypeRestAPI,
+		resPath:      "/api/v1/health",
+		resOpts:      map[string]interface{}{config.OptKeyRextResourceDefHTTPMethod: "GET"},
+	}.build(t)
+	cf, err := CreateAPIRestCreator(res, srv, desc, nil)
+	require.Nil(t, err)
+	cl, err := cf.CreateClient()
+	require.Nil(t, err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err = cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	require.Nil(t, err)
+	require.Equal(t, "1548979200", headers.Get("X-Timestamp"))
+	require.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", headers.Get("X-Content-SHA256"))
+	require.Equal(t, "bc0da7a30858db25a679d07b996a21136ae2304da56274205afc7af5e3b49ab1", headers.Get("X-Api-Signature"))
+}
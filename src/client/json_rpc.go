@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// JSONRPCError is returned when the server answer a call with a JSON-RPC error object
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Method  string          `json:"-"`
+}
+
+func (err JSONRPCError) Error() string {
+	return fmt.Sprintf("json-rpc error calling %s: %d %s", err.Method, err.Code, err.Message)
+}
+
+// IsJSONRPCError return true if err is a JSONRPCError
+func IsJSONRPCError(err error) bool {
+	var jsonRPCErr JSONRPCError
+	return errors.As(err, &jsonRPCErr)
+}
+
+// jsonRPCCall is a JSON-RPC 2.0 request
+type jsonRPCCall struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// jsonRPCResponse is a JSON-RPC 2.0 response, only one of Result and Error is present
+type jsonRPCResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *JSONRPCError   `json:"error"`
+}
+
+// jsonRPCBatch group the calls to the same endpoint to make them in a single http request per scrape, the responses
+// are kept until the next scrape
+type jsonRPCBatch struct {
+	mutex     *sync.Mutex
+	calls     []jsonRPCCall
+	scrape    uint64
+	responses map[int]jsonRPCResponse
+}
+
+var (
+	jsonRPCBatchesMutex = &sync.Mutex{}
+	jsonRPCBatches      = make(map[string]*jsonRPCBatch)
+)
+
+// jsonRPCBatchFor add the call to the batch for an endpoint and return the batch and the call id, the batch is
+// created if not exist
+func jsonRPCBatchFor(jobName, instanceName, endpoint string, call jsonRPCCall) (b *jsonRPCBatch, id int) {
+	jsonRPCBatchesMutex.Lock()
+	defer jsonRPCBatchesMutex.Unlock()
+	key := jobName + "|" + instanceName + "|" + endpoint
+	b, found := jsonRPCBatches[key]
+	if !found {
+		b = &jsonRPCBatch{mutex: &sync.Mutex{}}
+		jsonRPCBatches[key] = b
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, c := range b.calls {
+		if c.Method == call.Method && string(c.Params) == string(call.Params) {
+			return b, c.ID
+		}
+	}
+	call.ID = len(b.calls) + 1
+	b.calls = append(b.calls, call)
+	return b, call.ID
+}
+
+// resetJSONRPCBatches drop the batches, the clients of the next config make their own ones
+func resetJSONRPCBatches() {
+	jsonRPCBatchesMutex.Lock()
+	defer jsonRPCBatchesMutex.Unlock()
+	jsonRPCBatches = make(map[string]*jsonRPCBatch)
+}
+
+// JSONRPCCreator have info to create a JSON-RPC client
+type JSONRPCCreator struct {
+	rest  APIRestCreator
+	call  jsonRPCCall
+	batch *jsonRPCBatch
+}
+
+// CreateJSONRPCCreator create a JSONRPCCreator, the resource path is the JSON-RPC endpoint
+func CreateJSONRPCCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf CacheableFactory, err error) {
+	resOpts := resConf.GetOptions()
+	call := jsonRPCCall{JSONRPC: "2.0", ID: 1}
+	if call.Method, err = resOpts.GetString(config.OptKeyRextResourceDefJSONRPCMethod); err != nil {
+		log.WithError(err).Errorln("Can not find the json-rpc method")
+		return cf, err
+	}
+	if params, err := resOpts.GetString(config.OptKeyRextResourceDefJSONRPCParams); err == nil && len(params) != 0 {
+		call.Params = json.RawMessage(params)
+	}
+	batch := false
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefJSONRPCBatch); err == nil {
+		var okVal bool
+		if batch, okVal = iVal.(bool); !okVal {
+			log.WithField("val", iVal).Errorln("json-rpc batch should be a bool")
+			return cf, config.ErrKeyInvalidType
+		}
+	}
+	var rest APIRestCreator
+	if rest, err = newAPIRestCreator(resConf, srvConf, http.MethodPost, dataSourceResponseDurationDesc, cDefMetrics); err != nil {
+		return cf, err
+	}
+	rest.contentType = "application/json"
+	jc := JSONRPCCreator{rest: rest, call: call}
+	if batch {
+		jc.batch, jc.call.ID = jsonRPCBatchFor(rest.jobName, rest.instanceName, rest.dataPath, call)
+	}
+	return jc, nil
+}
+
+// CreateClient create a JSON-RPC client
+func (jc JSONRPCCreator) CreateClient() (cl CacheableClient, err error) {
+	params := ""
+	if jc.call.Params != nil {
+		params = string(jc.call.Params)
+	}
+	cl = JSONRPC{
+		baseCacheableClient: baseCacheableClient(jc.rest.dataPath + "#" + jc.call.Method + params),
+		rest:                jc.rest,
+		call:                jc.call,
+		batch:               jc.batch,
+	}
+	return cl, nil
+}
+
+// JSONRPC make a JSON-RPC 2.0 call and return the result as the data, the calls in the same batch are made in a
+// single http request per scrape
+type JSONRPC struct {
+	baseCacheableClient
+	rest  APIRestCreator
+	call  jsonRPCCall
+	batch *jsonRPCBatch
+}
+
+// post send the payload and return the body of the response
+func (cl JSONRPC) post(ctx context.Context, payload interface{}, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	const generalScopeErr = "error making a json-rpc request"
+	rest := cl.rest
+	if rest.body, err = json.Marshal(payload); err != nil {
+		errCause := fmt.Sprintln("can not encode the request: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	var restCl CacheableClient
+	if restCl, err = rest.CreateClient(); err != nil {
+		errCause := fmt.Sprintln("can not create the http client: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return restCl.GetData(ctx, metricsCollector)
+}
+
+// batchResponse return the response for the call in the batch, a new batch request is made if it was not
+// received in the current scrape(see NewScrapeContext) yet, a ctx not from a scrape always make the request
+func (cl JSONRPC) batchResponse(ctx context.Context, metricsCollector chan<- prometheus.Metric) (resp jsonRPCResponse, err error) {
+	const generalScopeErr = "error making a json-rpc batch request"
+	cl.batch.mutex.Lock()
+	defer cl.batch.mutex.Unlock()
+	scrape := scrapeFrom(ctx)
+	if scrape != 0 && cl.batch.scrape == scrape {
+		var found bool
+		if resp, found = cl.batch.responses[cl.call.ID]; found {
+			return resp, nil
+		}
+	}
+	var data []byte
+	if data, err = cl.post(ctx, cl.batch.calls, metricsCollector); err != nil {
+		return resp, err
+	}
+	var responses []jsonRPCResponse
+	if err = json.Unmarshal(data, &responses); err != nil {
+		errCause := fmt.Sprintln("can not decode the batch response: ", err.Error())
+		return resp, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	cl.batch.scrape = scrape
+	cl.batch.responses = make(map[int]jsonRPCResponse)
+	for _, response := range responses {
+		cl.batch.responses[response.ID] = response
+	}
+	var found bool
+	if resp, found = cl.batch.responses[cl.call.ID]; !found {
+		errCause := fmt.Sprintf("no response for the call %d(%s) in the batch", cl.call.ID, cl.call.Method)
+		return resp, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return resp, nil
+}
+
+// GetData make the call and return the result, a JSONRPCError is returned if the server answer with an error
+func (cl JSONRPC) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	const generalScopeErr = "error making a json-rpc request"
+	var resp jsonRPCResponse
+	if cl.batch != nil {
+		if resp, err = cl.batchResponse(ctx, metricsCollector); err != nil {
+			return nil, err
+		}
+	} else {
+		if data, err = cl.post(ctx, cl.call, metricsCollector); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &resp); err != nil {
+			errCause := fmt.Sprintln("can not decode the response: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	if resp.Error != nil {
+		resp.Error.Method = cl.call.Method
+		log.WithError(*resp.Error).Errorln("json-rpc error response")
+		return nil, *resp.Error
+	}
+	return resp.Result, nil
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type jsonRPCSuit struct {
+	suite.Suite
+	testServer *httptest.Server
+	requests   int32
+	lastBody   []byte
+}
+
+func (suite *jsonRPCSuit) SetupTest() {
+	// NOTE(denisacostaq@gmail.com): each test start with empty batches
+	resetJSONRPCBatches()
+	suite.requests = 0
+	suite.testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&suite.requests, 1)
+		body, err := ioutil.ReadAll(r.Body)
+		suite.Nil(err)
+		suite.lastBody = body
+		answer := func(call jsonRPCCall) interface{} {
+			switch call.Method {
+			case "get_height":
+				return map[string]interface{}{"jsonrpc": "2.0", "id": call.ID, "result": map[string]int{"height": 58}}
+			case "echo":
+				return map[string]interface{}{"jsonrpc": "2.0", "id": call.ID, "result": call.Params}
+			default:
+				return map[string]interface{}{"jsonrpc": "2.0", "id": call.ID, "error": map[string]interface{}{"code": -32601, "message": "Method not found"}}
+			}
+		}
+		var calls []jsonRPCCall
+		if err := json.Unmarshal(body, &calls); err == nil {
+			var responses []interface{}
+			for i := len(calls) - 1; i >= 0; i-- {
+				responses = append(responses, answer(calls[i]))
+			}
+			json.NewEncoder(w).Encode(responses)
+			return
+		}
+		var call jsonRPCCall
+		suite.Nil(json.Unmarshal(body, &call))
+		json.NewEncoder(w).Encode(answer(call))
+	}))
+}
+
+func (suite *jsonRPCSuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestJSONRPCSuit(t *testing.T) {
+	suite.Run(t, new(jsonRPCSuit))
+}
+
+func (suite *jsonRPCSuit) client(method, params string, batch bool) CacheableClient {
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		jobName:      "node",
+		instanceName: "localhost:6420",
+		resType:      config.ResourceTypeJSONRPC,
+		resPath:      "/rpc",
+		resOpts: map[string]interface{}{
+			config.OptKeyRextResourceDefJSONRPCMethod: method,
+			config.OptKeyRextResourceDefJSONRPCParams: params,
+			config.OptKeyRextResourceDefJSONRPCBatch:  batch,
+		},
+	}.build(suite.T())
+	cf, err := CreateJSONRPCCreator(res, srv, desc, metrics.NewDefaultClientMetrics())
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl
+}
+
+func (suite *jsonRPCSuit) getData(ctx context.Context, cl Client) ([]byte, error) {
+	return cl.GetData(ctx, make(chan prometheus.Metric, 10))
+}
+
+func (suite *jsonRPCSuit) TestResultIsTheData() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("get_height", "", false)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(context.Background(), cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`{"height": 58}`, string(data))
+	suite.JSONEq(`{"jsonrpc": "2.0", "id": 1, "method": "get_height"}`, string(suite.lastBody))
+}
+
+func (suite *jsonRPCSuit) TestParams() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("echo", `["0.25.0", {"verbose": true}]`, false)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(context.Background(), cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`["0.25.0", {"verbose": true}]`, string(data))
+}
+
+func (suite *jsonRPCSuit) TestErrorObject() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("missing_method", "", false)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(context.Background(), cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(data)
+	suite.True(IsJSONRPCError(err))
+	var jsonRPCErr JSONRPCError
+	suite.True(errors.As(err, &jsonRPCErr))
+	suite.Equal(-32601, jsonRPCErr.Code)
+	suite.Equal("missing_method", jsonRPCErr.Method)
+}
+
+func (suite *jsonRPCSuit) TestBatchOneRequestPerScrape() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl1 := suite.client("get_height", "", true)
+	cl2 := suite.client("echo", `[1]`, true)
+	cl3 := suite.client("missing_method", "", true)
+	scrape1 := NewScrapeContext(context.Background())
+	scrape2 := NewScrapeContext(context.Background())
+
+	// NOTE(denisacostaq@gmail.com): When
+	data1, err1 := suite.getData(scrape1, cl1)
+	data2, err2 := suite.getData(scrape1, cl2)
+	_, err3 := suite.getData(scrape1, cl3)
+	requestsInScrape1 := suite.requests
+	_, err4 := suite.getData(scrape2, cl2)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.JSONEq(`{"height": 58}`, string(data1))
+	suite.Nil(err2)
+	suite.JSONEq(`[1]`, string(data2))
+	suite.True(IsJSONRPCError(err3))
+	suite.Equal(int32(1), requestsInScrape1)
+	suite.Nil(err4)
+	suite.Equal(int32(2), suite.requests)
+}
+
+func (suite *jsonRPCSuit) TestBatchResponsesKeptUntilTheNextScrape() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("get_height", "", true)
+	scrape1 := NewScrapeContext(context.Background())
+	scrape1WithTimeout, cancel := context.WithTimeout(scrape1, time.Second)
+	defer cancel()
+
+	// NOTE(denisacostaq@gmail.com): When
+	data1, err1 := suite.getData(scrape1, cl)
+	data2, err2 := suite.getData(scrape1WithTimeout, cl)
+	requestsInScrape1 := suite.requests
+	_, err3 := suite.getData(NewScrapeContext(context.Background()), cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.Nil(err2)
+	suite.JSONEq(`{"height": 58}`, string(data1))
+	suite.JSONEq(`{"height": 58}`, string(data2))
+	suite.Equal(int32(1), requestsInScrape1)
+	suite.Nil(err3)
+	suite.Equal(int32(2), suite.requests)
+}
+
+func (suite *jsonRPCSuit) TestBatchRequestOutsideAScrape() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("get_height", "", true)
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err1 := suite.getData(context.Background(), cl)
+	_, err2 := suite.getData(context.Background(), cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.Nil(err2)
+	suite.Equal(int32(2), suite.requests)
+}
+
+func (suite *jsonRPCSuit) TestResetSharedStateDropTheBatches() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.client("get_height", "", true)
+	suite.client("echo", `[1]`, true)
+
+	// NOTE(denisacostaq@gmail.com): When
+	ResetSharedState()
+	cl := suite.client("echo", `[2]`, true)
+	_, err := suite.getData(NewScrapeContext(context.Background()), cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Len(jsonRPCBatches, 1)
+	var calls []jsonRPCCall
+	suite.Nil(json.Unmarshal(suite.lastBody, &calls))
+	suite.Len(calls, 1)
+}
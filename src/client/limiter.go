@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// limiterDef describe the limits for the requests to a service, zero means no limit
+type limiterDef struct {
+	requestsPerSecond     float64
+	burst                 int
+	maxConcurrentRequests int
+}
+
+// limiterDefFromOptions read the limits from the service options
+func limiterDefFromOptions(srvOpts config.RextKeyValueStore) (def limiterDef, err error) {
+	if iVal, err := srvOpts.GetObject(config.OptKeyRextServiceDefLimitRequestsPerSecond); err == nil {
+		var okVal bool
+		if def.requestsPerSecond, okVal = iVal.(float64); !okVal {
+			log.WithField("val", iVal).Errorln("requests per second should be a float64")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	ints := []struct {
+		key string
+		val *int
+	}{
+		{key: config.OptKeyRextServiceDefLimitBurst, val: &def.burst},
+		{key: config.OptKeyRextServiceDefLimitMaxConcurrentRequests, val: &def.maxConcurrentRequests},
+	}
+	for _, intVal := range ints {
+		if iVal, err := srvOpts.GetObject(intVal.key); err == nil {
+			var okVal bool
+			if *intVal.val, okVal = iVal.(int); !okVal {
+				log.WithFields(log.Fields{"key": intVal.key, "val": iVal}).Errorln("value should be an int")
+				return def, config.ErrKeyInvalidType
+			}
+		}
+	}
+	if def.burst <= 0 {
+		def.burst = 1
+	}
+	return def, nil
+}
+
+// limiter keep the requests to a service under the configured rate(a token bucket) and concurrency, it is
+// shared by all the clients of the service
+type limiter struct {
+	limiterDef
+	jobName       string
+	instanceName  string
+	clientMetrics *metrics.DefaultClientMetrics
+	mutex         *sync.Mutex
+	tokens        float64
+	last          time.Time
+	slots         chan struct{}
+}
+
+var (
+	limitersMutex = &sync.Mutex{}
+	limiters      = make(map[string]*limiter)
+)
+
+// resetLimiters drop all the limiters
+func resetLimiters() {
+	limitersMutex.Lock()
+	defer limitersMutex.Unlock()
+	limiters = make(map[string]*limiter)
+}
+
+// limiterFor return the limiter for a service, it is created if not exist, clientMetrics can be nil
+func limiterFor(srvOpts config.RextKeyValueStore, jobName, instanceName string, clientMetrics *metrics.DefaultClientMetrics) (l *limiter, err error) {
+	var def limiterDef
+	if def, err = limiterDefFromOptions(srvOpts); err != nil {
+		return nil, err
+	}
+	limitersMutex.Lock()
+	defer limitersMutex.Unlock()
+	key := jobName + "|" + instanceName
+	if l, found := limiters[key]; found {
+		return l, nil
+	}
+	l = &limiter{
+		limiterDef:    def,
+		jobName:       jobName,
+		instanceName:  instanceName,
+		clientMetrics: clientMetrics,
+		mutex:         &sync.Mutex{},
+		tokens:        float64(def.burst),
+		last:          time.Now(),
+	}
+	if def.maxConcurrentRequests > 0 {
+		l.slots = make(chan struct{}, def.maxConcurrentRequests)
+	}
+	limiters[key] = l
+	return l, nil
+}
+
+// enabled return true if some limit is defined
+func (l *limiter) enabled() bool {
+	return l.requestsPerSecond > 0 || l.maxConcurrentRequests > 0
+}
+
+// reserve take a token from the bucket and return how long to wait before using it
+func (l *limiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.requestsPerSecond
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.requestsPerSecond * float64(time.Second))
+}
+
+// cancelReservation give back a token not used
+func (l *limiter) cancelReservation() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.tokens++
+}
+
+func (l *limiter) throttled() {
+	if l.clientMetrics != nil {
+		l.clientMetrics.DataSourceThrottledRequests.WithLabelValues(l.jobName, l.instanceName).Inc()
+	}
+}
+
+// acquire wait until a request can be made, the returned release func should be called once the request
+// finish. An error is returned if ctx is done(or will be) before
+func (l *limiter) acquire(ctx context.Context) (release func(), err error) {
+	const generalScopeErr = "error waiting for the requests limits"
+	release = func() {}
+	throttled := false
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			throttled = true
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				l.throttled()
+				return nil, fmt.Errorf("%s: too many concurrent requests: %s", generalScopeErr, ctx.Err().Error())
+			}
+		}
+		var once sync.Once
+		release = func() {
+			once.Do(func() { <-l.slots })
+		}
+	}
+	if l.requestsPerSecond > 0 {
+		if wait := l.reserve(); wait > 0 {
+			throttled = true
+			// NOTE(denisacostaq@gmail.com): give up now if the request can not be made before the deadline
+			if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Now().Add(wait).After(deadline) {
+				l.cancelReservation()
+				release()
+				l.throttled()
+				return nil, fmt.Errorf("%s: rate limit exceeds the deadline", generalScopeErr)
+			}
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				l.cancelReservation()
+				release()
+				l.throttled()
+				return nil, fmt.Errorf("%s: rate limit: %s", generalScopeErr, ctx.Err().Error())
+			}
+		}
+	}
+	if throttled {
+		l.throttled()
+	}
+	return release, nil
+}
+
+// limitedRoundTripper wait for the service limits before each request
+type limitedRoundTripper struct {
+	base    http.RoundTripper
+	limiter *limiter
+}
+
+// releaseBody release the limiter slot when the response body is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (body releaseBody) Close() error {
+	defer body.release()
+	return body.ReadCloser.Close()
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (rt limitedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := rt.limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type limiterSuit struct {
+	suite.Suite
+	testServer    *httptest.Server
+	clientMetrics *metrics.DefaultClientMetrics
+	delay         time.Duration
+	inFlight      int32
+	maxInFlight   int32
+	requests      int32
+}
+
+func (suite *limiterSuit) SetupTest() {
+	// NOTE(denisacostaq@gmail.com): each test start with new limiters and transports
+	resetLimiters()
+	resetTransportSources()
+	suite.clientMetrics = metrics.NewDefaultClientMetrics()
+	suite.delay = 0
+	suite.inFlight = 0
+	suite.maxInFlight = 0
+	suite.requests = 0
+	suite.testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&suite.requests, 1)
+		inFlight := atomic.AddInt32(&suite.inFlight, 1)
+		defer atomic.AddInt32(&suite.inFlight, -1)
+		for {
+			maxInFlight := atomic.LoadInt32(&suite.maxInFlight)
+			if inFlight <= maxInFlight || atomic.CompareAndSwapInt32(&suite.maxInFlight, maxInFlight, inFlight) {
+				break
+			}
+		}
+		time.Sleep(suite.delay)
+		w.Write([]byte(`{}`))
+	}))
+}
+
+func (suite *limiterSuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestLimiterSuit(t *testing.T) {
+	suite.Run(t, new(limiterSuit))
+}
+
+func (suite *limiterSuit) client(srvOpts map[string]interface{}) Client {
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		jobName:      "skycoin",
+		instanceName: "localhost:6420",
+		srvOpts:      srvOpts,
+		resType:      config.ResourceTypeRestAPI,
+		resPath:      "/api/v1/health",
+		resOpts: map[string]interface{}{
+			config.OptKeyRextResourceDefHTTPMethod:          "GET",
+			config.OptKeyRextCircuitBreakerFailureThreshold: 0,
+		},
+	}.build(suite.T())
+	cf, err := CreateAPIRestCreator(res, srv, desc, suite.clientMetrics)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl
+}
+
+func (suite *limiterSuit) getData(ctx context.Context, cl Client) error {
+	_, err := cl.GetData(ctx, make(chan prometheus.Metric, 10))
+	return err
+}
+
+func (suite *limiterSuit) throttled() float64 {
+	var metric io_prometheus_client.Metric
+	counter := suite.clientMetrics.DataSourceThrottledRequests.WithLabelValues("skycoin", "localhost:6420")
+	suite.Require().Nil(counter.Write(&metric))
+	return metric.GetCounter().GetValue()
+}
+
+func (suite *limiterSuit) TestMaxConcurrentRequests() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.delay = 20 * time.Millisecond
+	cl := suite.client(map[string]interface{}{config.OptKeyRextServiceDefLimitMaxConcurrentRequests: 2})
+
+	// NOTE(denisacostaq@gmail.com): When
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			suite.Nil(suite.getData(context.Background(), cl))
+		}()
+	}
+	wg.Wait()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal(int32(6), suite.requests)
+	suite.Equal(int32(2), suite.maxInFlight)
+	suite.True(suite.throttled() > 0)
+}
+
+func (suite *limiterSuit) TestRequestsPerSecond() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client(map[string]interface{}{config.OptKeyRextServiceDefLimitRequestsPerSecond: float64(50)})
+
+	// NOTE(denisacostaq@gmail.com): When
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		suite.Nil(suite.getData(context.Background(), cl))
+	}
+	elapsed := time.Since(start)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.True(elapsed >= 55*time.Millisecond, elapsed.String())
+	suite.Equal(float64(3), suite.throttled())
+}
+
+func (suite *limiterSuit) TestRejectedBeforeDeadline() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client(map[string]interface{}{config.OptKeyRextServiceDefLimitRequestsPerSecond: float64(1)})
+	suite.Nil(suite.getData(context.Background(), cl))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// NOTE(denisacostaq@gmail.com): When
+	start := time.Now()
+	err := suite.getData(ctx, cl)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.True(time.Since(start) < 50*time.Millisecond)
+	suite.Equal(int32(1), suite.requests)
+	suite.Equal(float64(1), suite.throttled())
+}
+
+func (suite *limiterSuit) TestResetSharedStateApplyNewLimits() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client(map[string]interface{}{config.OptKeyRextServiceDefLimitRequestsPerSecond: float64(1)})
+	suite.Nil(suite.getData(context.Background(), cl))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// NOTE(denisacostaq@gmail.com): When
+	ResetSharedState()
+	err := suite.getData(ctx, suite.client(nil))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(int32(2), suite.requests)
+	suite.Equal(float64(0), suite.throttled())
+}
+
+func (suite *limiterSuit) TestNoLimitsByDefault() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client(nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	for i := 0; i < 10; i++ {
+		suite.Nil(suite.getData(context.Background(), cl))
+	}
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal(int32(10), suite.requests)
+	suite.Equal(float64(0), suite.throttled())
+}
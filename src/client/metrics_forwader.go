@@ -2,6 +2,7 @@ package client
 
 import (
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -20,10 +21,11 @@ type ProxyMetricClientCreator struct {
 	dataPath            string
 	JobName             string
 	InstanceName        string
+	transport           *transportSource
 }
 
 // CreateProxyMetricClientCreator create a ProxyMetricClientCreator with required info to create a metrics fordwader client
-func CreateProxyMetricClientCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, fDefMetrics *metrics.DefaultFordwaderMetrics) (cf ProxyMetricClientCreator, err error) {
+func CreateProxyMetricClientCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, fDefMetrics *metrics.DefaultFordwaderMetrics, cDefMetrics *metrics.DefaultClientMetrics) (cf ProxyMetricClientCreator, err error) {
 	srvOpts := srvConf.GetOptions()
 	jobName, err := srvOpts.GetString(config.OptKeyRextServiceDefJobName)
 	if err != nil {
@@ -36,11 +38,17 @@ func CreateProxyMetricClientCreator(resConf config.RextResourceDef, srvConf conf
 		return cf, err
 	}
 	resPath := resConf.GetResourcePATH(srvConf.GetBasePath())
+	ts, err := transportSourceFor(resConf.GetOptions(), srvOpts, jobName, instanceName, cDefMetrics)
+	if err != nil {
+		log.WithError(err).Errorln("Can not read the http settings")
+		return cf, err
+	}
 	cf = ProxyMetricClientCreator{
 		defFordwaderMetrics: fDefMetrics,
 		dataPath:            resPath,
 		JobName:             jobName,
 		InstanceName:        instanceName,
+		transport:           ts,
 	}
 	return cf, err
 }
@@ -58,6 +66,7 @@ func (pmc ProxyMetricClientCreator) CreateClient() (cl FordwaderClient, err erro
 		defFordwaderMetrics: pmc.defFordwaderMetrics,
 		jobName:             pmc.JobName,
 		instanceName:        pmc.InstanceName,
+		transport:           pmc.transport,
 	}
 	return cl, err
 }
@@ -69,12 +78,17 @@ type ProxyMetricClient struct {
 	defFordwaderMetrics *metrics.DefaultFordwaderMetrics
 	jobName             string
 	instanceName        string
+	transport           *transportSource
 }
 
 // GetData can get raw metrics from a endpoint
-func (client ProxyMetricClient) GetData() (data []byte, err error) {
+func (client ProxyMetricClient) GetData(ctx context.Context) (data []byte, err error) {
 	const generalScopeErr = "error making a server request to get the metrics from remote endpoint"
-	httpClient := &http.Client{}
+	httpClient, err := newHTTPClient(client.transport)
+	if err != nil {
+		errCause := fmt.Sprintln("can not create the http client: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
 	var resp *http.Response
 	{
 		successResponse := false
@@ -85,7 +99,7 @@ func (client ProxyMetricClient) GetData() (data []byte, err error) {
 				client.defFordwaderMetrics.FordwaderResponseDuration.WithLabelValues(labels...).Set(duration)
 			}
 		}(time.Now().UTC())
-		if resp, err = httpClient.Do(client.req); err != nil {
+		if resp, err = httpClient.Do(client.req.WithContext(ctx)); err != nil {
 			log.WithFields(log.Fields{"err": err, "req": client.req}).Errorln("no success response")
 			errCause := fmt.Sprintln("can not do the request: ", err.Error())
 			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
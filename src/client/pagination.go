@@ -0,0 +1,251 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// paginationDef describe how to request all the pages of a resource
+type paginationDef struct {
+	strategy    string
+	itemsPath   string
+	pageParam   string
+	limitParam  string
+	pageSize    int
+	firstPage   int
+	cursorPath  string
+	cursorParam string
+	maxPages    int
+}
+
+// defaultPaginationDef request up to 10 pages with the usual query params
+var defaultPaginationDef = paginationDef{
+	pageParam:   "page",
+	limitParam:  "limit",
+	firstPage:   1,
+	cursorParam: "cursor",
+	maxPages:    10,
+}
+
+// paginationDefFromOptions read the pagination settings from the resource options, nil is returned if the resource
+// is not paginated
+func paginationDefFromOptions(resOpts config.RextKeyValueStore) (def *paginationDef, err error) {
+	strategy, err := resOpts.GetString(config.OptKeyRextResourceDefPaginationStrategy)
+	if err != nil || len(strategy) == 0 {
+		return nil, nil
+	}
+	pagination := defaultPaginationDef
+	pagination.strategy = strategy
+	strs := []struct {
+		key string
+		val *string
+	}{
+		{key: config.OptKeyRextResourceDefPaginationItemsPath, val: &pagination.itemsPath},
+		{key: config.OptKeyRextResourceDefPaginationPageParam, val: &pagination.pageParam},
+		{key: config.OptKeyRextResourceDefPaginationLimitParam, val: &pagination.limitParam},
+		{key: config.OptKeyRextResourceDefPaginationCursorPath, val: &pagination.cursorPath},
+		{key: config.OptKeyRextResourceDefPaginationCursorParam, val: &pagination.cursorParam},
+	}
+	for _, str := range strs {
+		if val, err := resOpts.GetString(str.key); err == nil {
+			*str.val = val
+		}
+	}
+	ints := []struct {
+		key string
+		val *int
+	}{
+		{key: config.OptKeyRextResourceDefPaginationPageSize, val: &pagination.pageSize},
+		{key: config.OptKeyRextResourceDefPaginationFirstPage, val: &pagination.firstPage},
+		{key: config.OptKeyRextResourceDefPaginationMaxPages, val: &pagination.maxPages},
+	}
+	for _, i := range ints {
+		if iVal, err := resOpts.GetObject(i.key); err == nil {
+			var okVal bool
+			if *i.val, okVal = iVal.(int); !okVal {
+				log.WithFields(log.Fields{"key": i.key, "val": iVal}).Errorln("value should be an int")
+				return nil, config.ErrKeyInvalidType
+			}
+		}
+	}
+	return &pagination, nil
+}
+
+// nodeKeys split a path like /connections/items in its keys
+func nodeKeys(path string) []string {
+	var keys []string
+	for _, key := range strings.Split(path, "/") {
+		if len(key) != 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// nodeAt return the node in path inside a decoded json document, only objects are traversed
+func nodeAt(doc interface{}, path string) (node interface{}, found bool) {
+	node = doc
+	for _, key := range nodeKeys(path) {
+		obj, isObj := node.(map[string]interface{})
+		if !isObj {
+			return nil, false
+		}
+		if node, found = obj[key]; !found {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// setNodeAt replace the node in path inside a decoded json document, the document itself is replaced for an empty path
+func setNodeAt(doc interface{}, path string, node interface{}) interface{} {
+	keys := nodeKeys(path)
+	if len(keys) == 0 {
+		return node
+	}
+	parent, _ := nodeAt(doc, strings.Join(keys[:len(keys)-1], "/"))
+	if obj, isObj := parent.(map[string]interface{}); isObj {
+		obj[keys[len(keys)-1]] = node
+	}
+	return doc
+}
+
+// nextLink return the url in the Link header with rel="next", empty if not present
+func nextLink(header http.Header) string {
+	for _, link := range header["Link"] {
+		for _, part := range strings.Split(link, ",") {
+			fields := strings.Split(part, ";")
+			target := strings.TrimSpace(fields[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range fields[1:] {
+				param = strings.Replace(strings.TrimSpace(param), " ", "", -1)
+				if param == `rel="next"` || param == "rel=next" {
+					return strings.Trim(target, "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// withQuery return a copy of req with the query params replaced by vals
+func withQuery(req *http.Request, vals map[string]string) *http.Request {
+	next := req.Clone(req.Context())
+	query := next.URL.Query()
+	for key, val := range vals {
+		query.Set(key, val)
+	}
+	next.URL.RawQuery = query.Encode()
+	return next
+}
+
+// cursorValue return the cursor as a query param value, the numbers are decoded as float64 so they are written
+// without exponent to keep big ids like 1000000 as they come
+func cursorValue(cursor interface{}) string {
+	if val, isFloat := cursor.(float64); isFloat {
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return fmt.Sprint(cursor)
+}
+
+// firstRequest return the request for the first page
+func (def paginationDef) firstRequest(req *http.Request) *http.Request {
+	if def.strategy != config.PaginationPage {
+		return req
+	}
+	vals := map[string]string{def.pageParam: strconv.Itoa(def.firstPage)}
+	if def.pageSize > 0 {
+		vals[def.limitParam] = strconv.Itoa(def.pageSize)
+	}
+	return withQuery(req, vals)
+}
+
+// nextRequest return the request for the page after the one received with req, nil if it was the last one
+func (def paginationDef) nextRequest(req *http.Request, page interface{}, header http.Header, items int) *http.Request {
+	switch def.strategy {
+	case config.PaginationPage:
+		if items == 0 || (def.pageSize > 0 && items < def.pageSize) {
+			return nil
+		}
+		current, err := strconv.Atoi(req.URL.Query().Get(def.pageParam))
+		if err != nil {
+			return nil
+		}
+		return withQuery(req, map[string]string{def.pageParam: strconv.Itoa(current + 1)})
+	case config.PaginationCursor:
+		cursor, found := nodeAt(page, def.cursorPath)
+		if !found || cursor == nil || cursor == "" || cursor == false {
+			return nil
+		}
+		return withQuery(req, map[string]string{def.cursorParam: cursorValue(cursor)})
+	case config.PaginationLink:
+		link := nextLink(header)
+		if len(link) == 0 {
+			return nil
+		}
+		target, err := url.Parse(link)
+		if err != nil {
+			log.WithError(err).WithField("link", link).Errorln("can not parse the next page link")
+			return nil
+		}
+		next := req.Clone(req.Context())
+		next.URL = req.URL.ResolveReference(target)
+		next.Host = next.URL.Host
+		return next
+	}
+	return nil
+}
+
+// fetchAll request the pages with fetchPage and merge their items in the first page, the number of pages fetched
+// is returned too
+func (def paginationDef) fetchAll(req *http.Request, fetchPage func(req *http.Request) ([]byte, http.Header, error)) (data []byte, pages int, err error) {
+	const generalScopeErr = "error requesting a paginated resource"
+	var doc interface{}
+	var items []interface{}
+	for pageReq := def.firstRequest(req); pageReq != nil; {
+		if pages == def.maxPages {
+			log.WithFields(log.Fields{"max_pages": def.maxPages, "url": req.URL.String()}).Warnln("max pages reached, the next ones are ignored")
+			break
+		}
+		var body []byte
+		var header http.Header
+		if body, header, err = fetchPage(pageReq); err != nil {
+			return nil, pages, err
+		}
+		pages++
+		var page interface{}
+		if err = json.Unmarshal(body, &page); err != nil {
+			errCause := fmt.Sprintf("can not decode the page %d: %s", pages, err.Error())
+			return nil, pages, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		node, _ := nodeAt(page, def.itemsPath)
+		pageItems, isArray := node.([]interface{})
+		if node != nil && !isArray {
+			errCause := fmt.Sprintf("the node %s in the page %d is not an array", def.itemsPath, pages)
+			return nil, pages, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		if doc == nil {
+			doc = page
+		}
+		items = append(items, pageItems...)
+		pageReq = def.nextRequest(pageReq, page, header, len(pageItems))
+	}
+	if items == nil {
+		items = []interface{}{}
+	}
+	if data, err = json.Marshal(setNodeAt(doc, def.itemsPath, items)); err != nil {
+		errCause := fmt.Sprintln("can not encode the merged pages: ", err.Error())
+		return nil, pages, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return data, pages, nil
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+// paginatedItems are the items served by the test server, 2 per page
+var paginatedItems = []int{1, 2, 3, 4, 5}
+
+type paginationSuit struct {
+	suite.Suite
+	testServer    *httptest.Server
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+func itemsFrom(idx int) []int {
+	if idx >= len(paginatedItems) {
+		return []int{}
+	}
+	end := idx + 2
+	if end > len(paginatedItems) {
+		end = len(paginatedItems)
+	}
+	return paginatedItems[idx:end]
+}
+
+func (suite *paginationSuit) SetupTest() {
+	suite.clientMetrics = metrics.NewDefaultClientMetrics()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		suite.Equal(2, size)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"total": len(paginatedItems),
+			"data":  map[string]interface{}{"items": itemsFrom(page * size)},
+		})
+	})
+	mux.HandleFunc("/cursor", func(w http.ResponseWriter, r *http.Request) {
+		idx, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		resp := map[string]interface{}{"items": itemsFrom(idx)}
+		if idx+2 < len(paginatedItems) {
+			resp["next"] = strconv.Itoa(idx + 2)
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/numeric-cursor", func(w http.ResponseWriter, r *http.Request) {
+		resp := map[string]interface{}{"items": []int{}}
+		switch r.URL.Query().Get("after") {
+		case "":
+			resp["items"] = []int{1, 2}
+			resp["next"] = 1234567
+		case "1234567":
+			resp["items"] = []int{3}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/link", func(w http.ResponseWriter, r *http.Request) {
+		idx, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		if idx+2 < len(paginatedItems) {
+			w.Header().Set("Link", fmt.Sprintf(`</link?from=0>; rel="first", </link?from=%d>; rel="next"`, idx+2))
+		}
+		json.NewEncoder(w).Encode(itemsFrom(idx))
+	})
+	suite.testServer = httptest.NewServer(mux)
+}
+
+func (suite *paginationSuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestPaginationSuit(t *testing.T) {
+	suite.Run(t, new(paginationSuit))
+}
+
+func (suite *paginationSuit) getData(resPath string, resOpts map[string]interface{}) ([]byte, error) {
+	opts := map[string]interface{}{config.OptKeyRextResourceDefHTTPMethod: http.MethodGet}
+	for k, v := range resOpts {
+		opts[k] = v
+	}
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		jobName:      "explorer",
+		instanceName: "localhost:8001",
+		resType:      config.ResourceTypeRestAPI,
+		resPath:      resPath,
+		resOpts:      opts,
+	}.build(suite.T())
+	cf, err := CreateAPIRestCreator(res, srv, desc, suite.clientMetrics)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+}
+
+func (suite *paginationSuit) pagesFetched(dataSource string) float64 {
+	var metric io_prometheus_client.Metric
+	counter := suite.clientMetrics.DataSourcePagesFetched.WithLabelValues("explorer", "localhost:8001", dataSource)
+	suite.Require().Nil(counter.Write(&metric))
+	return metric.GetCounter().GetValue()
+}
+
+func (suite *paginationSuit) TestPageStrategy() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefPaginationStrategy:   config.PaginationPage,
+		config.OptKeyRextResourceDefPaginationItemsPath:  "/data/items",
+		config.OptKeyRextResourceDefPaginationPageParam:  "p",
+		config.OptKeyRextResourceDefPaginationLimitParam: "size",
+		config.OptKeyRextResourceDefPaginationPageSize:   2,
+		config.OptKeyRextResourceDefPaginationFirstPage:  0,
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData("/page", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`{"total": 5, "data": {"items": [1, 2, 3, 4, 5]}}`, string(data))
+	suite.Equal(float64(3), suite.pagesFetched("/page"))
+}
+
+func (suite *paginationSuit) TestCursorStrategy() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefPaginationStrategy:    config.PaginationCursor,
+		config.OptKeyRextResourceDefPaginationItemsPath:   "/items",
+		config.OptKeyRextResourceDefPaginationCursorPath:  "/next",
+		config.OptKeyRextResourceDefPaginationCursorParam: "after",
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData("/cursor", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`{"items": [1, 2, 3, 4, 5], "next": "2"}`, string(data))
+	suite.Equal(float64(3), suite.pagesFetched("/cursor"))
+}
+
+func (suite *paginationSuit) TestLinkStrategy() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefPaginationStrategy: config.PaginationLink}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData("/link", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`[1, 2, 3, 4, 5]`, string(data))
+	suite.Equal(float64(3), suite.pagesFetched("/link"))
+}
+
+func (suite *paginationSuit) TestMaxPages() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefPaginationStrategy: config.PaginationLink,
+		config.OptKeyRextResourceDefPaginationMaxPages: 2,
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData("/link", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`[1, 2, 3, 4]`, string(data))
+	suite.Equal(float64(2), suite.pagesFetched("/link"))
+}
+
+func (suite *paginationSuit) TestNextLink() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	header := http.Header{}
+	header.Add("Link", `<https://api.example.com/items?page=1>; rel="prev"`)
+	header.Add("Link", `<https://api.example.com/items?page=3>; rel=next`)
+
+	// NOTE(denisacostaq@gmail.com): When
+	link := nextLink(header)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal("https://api.example.com/items?page=3", link)
+	suite.Empty(nextLink(http.Header{}))
+}
+
+func (suite *paginationSuit) TestCursorStrategyWithBigNumericCursor() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefPaginationStrategy:    config.PaginationCursor,
+		config.OptKeyRextResourceDefPaginationItemsPath:   "/items",
+		config.OptKeyRextResourceDefPaginationCursorPath:  "/next",
+		config.OptKeyRextResourceDefPaginationCursorParam: "after",
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData("/numeric-cursor", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`{"items": [1, 2, 3], "next": 1234567}`, string(data))
+	suite.Equal(float64(2), suite.pagesFetched("/numeric-cursor"))
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/simelo/rextporter/src/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// retryPolicy describe when and how a failed request is retried
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	timeBudget     time.Duration
+	statusCodes    map[int]bool
+	errorClasses   map[string]bool
+}
+
+// defaultRetryPolicy make a single attempt, if the retries are enabled only the transient failures are retried
+var defaultRetryPolicy = retryPolicy{
+	maxAttempts:    1,
+	initialBackoff: 100 * time.Millisecond,
+	maxBackoff:     2 * time.Second,
+	statusCodes: map[int]bool{
+		http.StatusBadGateway:         true,
+		http.StatusServiceUnavailable: true,
+		http.StatusGatewayTimeout:     true,
+	},
+	errorClasses: map[string]bool{
+		config.RetryErrorConnection: true,
+		config.RetryErrorTimeout:    true,
+	},
+}
+
+// retryPolicyFromOptions read the retry policy, a value in resOpts(can be nil) override the one in srvOpts,
+// defaults are used for the missing ones
+func retryPolicyFromOptions(resOpts, srvOpts config.RextKeyValueStore) (rp retryPolicy, err error) {
+	rp = defaultRetryPolicy
+	if iVal, found := optionFor(resOpts, srvOpts, config.OptKeyRextRetryMaxAttempts); found {
+		var okVal bool
+		if rp.maxAttempts, okVal = iVal.(int); !okVal {
+			log.WithField("val", iVal).Errorln("retry max attempts should be an int")
+			return rp, config.ErrKeyInvalidType
+		}
+	}
+	durations := []struct {
+		key string
+		val *time.Duration
+	}{
+		{key: config.OptKeyRextRetryInitialBackoff, val: &rp.initialBackoff},
+		{key: config.OptKeyRextRetryMaxBackoff, val: &rp.maxBackoff},
+		{key: config.OptKeyRextRetryTimeBudget, val: &rp.timeBudget},
+	}
+	for _, duration := range durations {
+		if iVal, found := optionFor(resOpts, srvOpts, duration.key); found {
+			var okVal bool
+			if *duration.val, okVal = iVal.(time.Duration); !okVal {
+				log.WithFields(log.Fields{"key": duration.key, "val": iVal}).Errorln("value should be a time.Duration")
+				return rp, config.ErrKeyInvalidType
+			}
+		}
+	}
+	if iVal, found := optionFor(resOpts, srvOpts, config.OptKeyRextRetryStatusCodes); found {
+		codes, okCodes := iVal.([]int)
+		if !okCodes {
+			log.WithField("val", iVal).Errorln("retry status codes should be an []int")
+			return rp, config.ErrKeyInvalidType
+		}
+		rp.statusCodes = make(map[int]bool)
+		for _, code := range codes {
+			rp.statusCodes[code] = true
+		}
+	}
+	if iVal, found := optionFor(resOpts, srvOpts, config.OptKeyRextRetryErrors); found {
+		errorClasses, okErrorClasses := iVal.([]string)
+		if !okErrorClasses {
+			log.WithField("val", iVal).Errorln("retry errors should be an []string")
+			return rp, config.ErrKeyInvalidType
+		}
+		rp.errorClasses = make(map[string]bool)
+		for _, errorClass := range errorClasses {
+			rp.errorClasses[errorClass] = true
+		}
+	}
+	return rp, nil
+}
+
+// errorClass return the class of a request error, an empty string if it is not a known one
+func errorClass(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return config.RetryErrorDNS
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return config.RetryErrorTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return config.RetryErrorConnection
+	}
+	return ""
+}
+
+// retryableError return true if a failed request(without response) should be retried
+func (rp retryPolicy) retryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return rp.errorClasses[errorClass(err)]
+}
+
+// retryableStatus return true if a request with the statusCode response should be retried
+func (rp retryPolicy) retryableStatus(statusCode int) bool {
+	return rp.statusCodes[statusCode]
+}
+
+// budget return a context limited by the time budget, the scrape deadline in ctx is kept if it is sooner
+func (rp retryPolicy) budget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rp.timeBudget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, rp.timeBudget)
+}
+
+// backoff return the wait before the retry number attempt(starting at 1), it grows exponentially up to
+// maxBackoff and a random jitter of up to the half of it is subtracted
+func (rp retryPolicy) backoff(attempt int) time.Duration {
+	backoff := rp.initialBackoff
+	for i := 1; i < attempt && backoff < rp.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > rp.maxBackoff {
+		backoff = rp.maxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff - time.Duration(rand.Int63n(int64(backoff)/2+1))
+}
+
+// wait sleep the backoff for the retry number attempt, an error is returned if ctx is done before
+func (rp retryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(rp.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
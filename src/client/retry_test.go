@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type retrySuit struct {
+	suite.Suite
+	testServer    *httptest.Server
+	clientMetrics *metrics.DefaultClientMetrics
+	failures      int32
+	failStatus    int
+	requests      int32
+	tokenRequests int32
+}
+
+func (suite *retrySuit) SetupTest() {
+	// NOTE(denisacostaq@gmail.com): each test start with the circuits closed
+	circuitBreakersMutex.Lock()
+	circuitBreakers = make(map[string]*circuitBreaker)
+	circuitBreakersMutex.Unlock()
+	suite.clientMetrics = metrics.NewDefaultClientMetrics()
+	suite.failures = 0
+	suite.failStatus = http.StatusServiceUnavailable
+	suite.requests = 0
+	suite.tokenRequests = 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/csrf", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&suite.tokenRequests, 1)
+		w.Write([]byte(`{"csrf_token": "tk1"}`))
+	})
+	mux.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&suite.requests, 1) <= atomic.LoadInt32(&suite.failures) {
+			w.WriteHeader(suite.failStatus)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	suite.testServer = httptest.NewServer(mux)
+}
+
+func (suite *retrySuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestRetrySuit(t *testing.T) {
+	suite.Run(t, new(retrySuit))
+}
+
+func (suite *retrySuit) getData(auth config.RextAuthDef, srvRetry, resRetry map[string]interface{}) ([]byte, error) {
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefHTTPMethod: "GET"}
+	for k, v := range resRetry {
+		resOpts[k] = v
+	}
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		auth:         auth,
+		jobName:      "skycoin",
+		instanceName: "localhost:6420",
+		srvOpts:      srvRetry,
+		resType:      config.ResourceTypeRestAPI,
+		resPath:      "/api/v1/health",
+		resOpts:      resOpts,
+	}.build(suite.T())
+	cf, err := CreateAPIRestCreator(res, srv, desc, suite.clientMetrics)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+}
+
+func (suite *retrySuit) retries() float64 {
+	var metric io_prometheus_client.Metric
+	counter := suite.clientMetrics.DataSourceRequestRetries.WithLabelValues("skycoin", "localhost:6420", "/api/v1/health")
+	suite.Require().Nil(counter.Write(&metric))
+	return metric.GetCounter().GetValue()
+}
+
+func retryOpts(maxAttempts int) map[string]interface{} {
+	return map[string]interface{}{
+		config.OptKeyRextRetryMaxAttempts:    maxAttempts,
+		config.OptKeyRextRetryInitialBackoff: time.Millisecond,
+	}
+}
+
+func (suite *retrySuit) TestNoRetriesByDefault() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.failures = 1
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(nil, nil, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Equal(int32(1), suite.requests)
+	suite.Equal(float64(0), suite.retries())
+}
+
+func (suite *retrySuit) TestRetryUntilSuccess() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.failures = 2
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(nil, retryOpts(3), nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal("{}", string(data))
+	suite.Equal(int32(3), suite.requests)
+	suite.Equal(float64(2), suite.retries())
+}
+
+func (suite *retrySuit) TestMaxAttempts() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.failures = 10
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(nil, retryOpts(3), nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Equal(int32(3), suite.requests)
+	suite.Equal(float64(2), suite.retries())
+}
+
+func (suite *retrySuit) TestResourceOverrideService() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.failures = 10
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(nil, retryOpts(5), map[string]interface{}{config.OptKeyRextRetryMaxAttempts: 2})
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Equal(int32(2), suite.requests)
+}
+
+func (suite *retrySuit) TestStatusNotRetryable() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.failures = 1
+	suite.failStatus = http.StatusInternalServerError
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(nil, retryOpts(3), nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Equal(int32(1), suite.requests)
+}
+
+func (suite *retrySuit) TestCustomStatusCodes() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.failures = 1
+	suite.failStatus = http.StatusInternalServerError
+	opts := retryOpts(3)
+	opts[config.OptKeyRextRetryStatusCodes] = []int{http.StatusInternalServerError}
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(nil, opts, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(int32(2), suite.requests)
+}
+
+func (suite *retrySuit) TestTimeBudget() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.failures = 10
+	opts := map[string]interface{}{
+		config.OptKeyRextRetryMaxAttempts:    10,
+		config.OptKeyRextRetryInitialBackoff: 40 * time.Millisecond,
+		config.OptKeyRextRetryTimeBudget:     50 * time.Millisecond,
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(nil, opts, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.True(suite.requests < 10)
+}
+
+func (suite *retrySuit) TestTokenNotResetOnServerError() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	auth := memconfig.NewHTTPAuth(config.AuthTypeCSRF, "", memconfig.NewOptionsMap())
+	authOpts := map[string]string{
+		config.OptKeyRextAuthDefTokenHeaderKey:       "X-CSRF-Token",
+		config.OptKeyRextAuthDefTokenKeyFromEndpoint: "/csrf_token",
+		config.OptKeyRextAuthDefTokenGenEndpoint:     "/csrf",
+	}
+	for k, v := range authOpts {
+		_, err := auth.GetOptions().SetString(k, v)
+		suite.Nil(err)
+	}
+	suite.failures = 2
+	suite.failStatus = http.StatusInternalServerError
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(auth, nil, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Equal(int32(1), suite.requests)
+	suite.Equal(int32(0), suite.tokenRequests)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	rp := retryPolicy{initialBackoff: 100 * time.Millisecond, maxBackoff: time.Second}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 400 * time.Millisecond},
+		{attempt: 5, max: time.Second},
+		{attempt: 50, max: time.Second},
+	}
+	for _, tt := range tests {
+		backoff := rp.backoff(tt.attempt)
+		if backoff > tt.max || backoff < tt.max/2 {
+			t.Errorf("backoff(%d) = %s, want between %s and %s", tt.attempt, backoff, tt.max/2, tt.max)
+		}
+	}
+}
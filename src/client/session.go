@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// loginGracePeriod is the time in which a login is considered fresh, this avoid a re-login for each request
+// rejected concurrently
+var loginGracePeriod = time.Second
+
+// session keep the cookies for a service, it is shared across all the resources in the same service
+type session struct {
+	jar       http.CookieJar
+	mutex     *sync.Mutex
+	lastLogin time.Time
+}
+
+var (
+	sessionsMutex = &sync.Mutex{}
+	sessions      = make(map[string]*session)
+)
+
+// resetSessions drop all the sessions, the next requests login again
+func resetSessions() {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	sessions = make(map[string]*session)
+}
+
+// sessionFor return the session shared by all resources in the same service, it is created if not exist
+func sessionFor(jobName, instanceName, basePath string) (*session, error) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	key := jobName + "|" + instanceName + "|" + basePath
+	if s, found := sessions[key]; found {
+		return s, nil
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	s := &session{jar: jar, mutex: &sync.Mutex{}}
+	sessions[key] = s
+	return s, nil
+}
+
+// sessionAuth implements the authStrategy for the SessionLogin auth type, a login request is made and the
+// cookies set by the server are sent in next requests
+type sessionAuth struct {
+	jobName      string
+	instanceName string
+	login        requestDef
+	loginPage    string
+	session      *session
+	transport    *transportSource
+}
+
+func createSessionAuth(auth config.RextAuthDef, srvConf config.RextServiceDef, jobName, instanceName string, ts *transportSource) (strategy authStrategy, err error) {
+	const generalScopeErr = "error creating session login auth"
+	authOpts := auth.GetOptions()
+	loginEndpoint, err := authOpts.GetString(config.OptKeyRextAuthDefLoginEndpoint)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find loginEndpoint")
+		return strategy, err
+	}
+	var login requestDef
+	if login, err = requestDefFromOptions(authOpts, srvConf.GetBasePath()+loginEndpoint, config.OptKeyRextAuthDefLoginHTTPMethod, http.MethodPost, config.OptKeyRextAuthDefLoginBody, config.OptKeyRextAuthDefLoginHeaders); err != nil {
+		log.WithError(err).Errorln("Can not read the login request")
+		return strategy, err
+	}
+	// NOTE(denisacostaq@gmail.com): the login page is optional, the login endpoint is used if not present
+	loginPage, _ := authOpts.GetString(config.OptKeyRextAuthDefLoginPage)
+	if len(loginPage) == 0 {
+		loginPage = loginEndpoint
+	}
+	var loginPageURL *url.URL
+	if loginPageURL, err = url.Parse(loginPage); err != nil {
+		errCause := fmt.Sprintln("can not parse the login page: ", err.Error())
+		return strategy, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	var s *session
+	if s, err = sessionFor(jobName, instanceName, srvConf.GetBasePath()); err != nil {
+		errCause := fmt.Sprintln("can not create the session: ", err.Error())
+		return strategy, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	strategy = sessionAuth{
+		jobName:      jobName,
+		instanceName: instanceName,
+		login:        login,
+		loginPage:    loginPageURL.Path,
+		session:      s,
+		transport:    ts,
+	}
+	return strategy, nil
+}
+
+func (sa sessionAuth) isLoginPage(location *url.URL) bool {
+	return location != nil && location.Path == sa.loginPage
+}
+
+func (sa sessionAuth) prepareClient(httpClient *http.Client) {
+	httpClient.Jar = sa.session.jar
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if sa.isLoginPage(req.URL) {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+}
+
+func (sa sessionAuth) authenticate(ctx context.Context, req *http.Request, metricsCollector chan<- prometheus.Metric) error {
+	// NOTE(denisacostaq@gmail.com): the http client put the cookies from the jar in the request itself, remove
+	// the ones from a previous attempt to avoid sending an expired session
+	req.Header.Del("Cookie")
+	sa.session.mutex.Lock()
+	loggedIn := !sa.session.lastLogin.IsZero()
+	sa.session.mutex.Unlock()
+	if loggedIn {
+		return nil
+	}
+	return sa.renew(ctx, metricsCollector)
+}
+
+// rejected return true if the server answer with an unauthorized status or redirect to the login page
+func (sa sessionAuth) rejected(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return true
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest {
+		location, err := resp.Location()
+		return err == nil && sa.isLoginPage(location)
+	}
+	return false
+}
+
+func (sa sessionAuth) renew(ctx context.Context, metricsCollector chan<- prometheus.Metric) (err error) {
+	const generalScopeErr = "error login in the remote service"
+	sa.session.mutex.Lock()
+	defer sa.session.mutex.Unlock()
+	if !sa.session.lastLogin.IsZero() && time.Since(sa.session.lastLogin) < loginGracePeriod {
+		return nil
+	}
+	var req *http.Request
+	if req, err = sa.login.newRequest(sa.jobName, sa.instanceName); err != nil {
+		errCause := fmt.Sprintln("can not create the login request: ", err.Error())
+		return util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	req = req.WithContext(ctx)
+	var httpClient *http.Client
+	if httpClient, err = newHTTPClient(sa.transport); err != nil {
+		errCause := fmt.Sprintln("can not create the http client: ", err.Error())
+		return util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	httpClient.Jar = sa.session.jar
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		errCause := fmt.Sprintln("can not do the login request: ", err.Error())
+		return util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body) // nolint errcheck
+	if resp.StatusCode >= http.StatusBadRequest {
+		errCause := fmt.Sprintf("no success login response, status %s", resp.Status)
+		return util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	sa.session.lastLogin = time.Now()
+	return nil
+}
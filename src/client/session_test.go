@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+type sessionSuit struct {
+	suite.Suite
+	testServer    *httptest.Server
+	logins        int
+	validSession  string
+	expiredStatus int
+}
+
+func (suite *sessionSuit) SetupTest() {
+	loginGracePeriod = 0
+	suite.logins = 0
+	suite.validSession = ""
+	suite.expiredStatus = http.StatusFound
+	mux := http.NewServeMux()
+	mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("password") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		suite.logins++
+		suite.validSession = fmt.Sprintf("sid%d", suite.logins)
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: suite.validSession, Path: "/"})
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("login form"))
+	})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("sid"); err == nil && cookie.Value == suite.validSession {
+			w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+			return
+		}
+		if suite.expiredStatus == http.StatusFound {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.WriteHeader(suite.expiredStatus)
+	}
+	mux.HandleFunc("/api/v1/health", handler)
+	mux.HandleFunc("/api/v1/network", handler)
+	suite.testServer = httptest.NewServer(mux)
+}
+
+func (suite *sessionSuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestSessionSuit(t *testing.T) {
+	suite.Run(t, new(sessionSuit))
+}
+
+func (suite *sessionSuit) apiRestCreator(fixture clientFixture, resPath string) CacheableFactory {
+	fixture.resType = config.ResourceTypeRestAPI
+	fixture.resPath = resPath
+	fixture.resOpts = map[string]interface{}{config.OptKeyRextResourceDefHTTPMethod: "GET"}
+	srv, res, desc := fixture.build(suite.T())
+	cf, err := CreateAPIRestCreator(res, srv, desc, nil)
+	suite.Require().Nil(err)
+	return cf
+}
+
+func (suite *sessionSuit) service() clientFixture {
+	auth := memconfig.NewHTTPAuth(config.AuthTypeSessionLogin, "", memconfig.NewOptionsMap())
+	authOpts := map[string]interface{}{
+		config.OptKeyRextAuthDefLoginEndpoint: "/session",
+		config.OptKeyRextAuthDefLoginBody:     "username=rextporter&password=secret",
+		config.OptKeyRextAuthDefLoginHeaders:  map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		config.OptKeyRextAuthDefLoginPage:     "/login",
+	}
+	for k, v := range authOpts {
+		_, err := auth.GetOptions().SetObject(k, v)
+		suite.Nil(err)
+	}
+	return clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		auth:         auth,
+		jobName:      "myapp",
+		instanceName: "localhost:8080",
+	}
+}
+
+func (suite *sessionSuit) getData(cf CacheableFactory) ([]byte, error) {
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	metricsCollector := make(chan prometheus.Metric, 10)
+	return cl.GetData(context.Background(), metricsCollector)
+}
+
+func (suite *sessionSuit) TestSessionIsSharedAcrossResources() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srv := suite.service()
+	cf1 := suite.apiRestCreator(srv, "/api/v1/health")
+	cf2 := suite.apiRestCreator(srv, "/api/v1/network")
+
+	// NOTE(denisacostaq@gmail.com): When
+	data1, err1 := suite.getData(cf1)
+	data2, err2 := suite.getData(cf2)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.Nil(err2)
+	suite.Equal(`{"path": "/api/v1/health"}`, string(data1))
+	suite.Equal(`{"path": "/api/v1/network"}`, string(data2))
+	suite.Equal(1, suite.logins)
+}
+
+func (suite *sessionSuit) TestResetSharedStateDropSessions() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	_, err := suite.getData(suite.apiRestCreator(suite.service(), "/api/v1/health"))
+	suite.Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	ResetSharedState()
+	_, err = suite.getData(suite.apiRestCreator(suite.service(), "/api/v1/health"))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(2, suite.logins)
+}
+
+func (suite *sessionSuit) TestRedirectToLoginPageRenewSession() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cf := suite.apiRestCreator(suite.service(), "/api/v1/health")
+	_, err := suite.getData(cf)
+	suite.Nil(err)
+	suite.validSession = "expired"
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(cf)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{"path": "/api/v1/health"}`, string(data))
+	suite.Equal(2, suite.logins)
+}
+
+func (suite *sessionSuit) TestUnauthorizedRenewSession() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.expiredStatus = http.StatusUnauthorized
+	cf := suite.apiRestCreator(suite.service(), "/api/v1/health")
+	_, err := suite.getData(cf)
+	suite.Nil(err)
+	suite.validSession = "expired"
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(cf)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{"path": "/api/v1/health"}`, string(data))
+	suite.Equal(2, suite.logins)
+}
+
+func (suite *sessionSuit) TestOtherErrorsDoNotRenewSession() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.expiredStatus = http.StatusInternalServerError
+	cf := suite.apiRestCreator(suite.service(), "/api/v1/health")
+	_, err := suite.getData(cf)
+	suite.Nil(err)
+	suite.validSession = "expired"
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err = suite.getData(cf)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Equal(1, suite.logins)
+}
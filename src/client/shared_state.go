@@ -0,0 +1,16 @@
+package client
+
+// ResetSharedState drop the state shared by the clients created from a config, like the login sessions, the
+// connections pools, the json-rpc batches, the circuit breakers, the rate limits, the limits for the concurrent
+// command runs or the stream subscriptions. It should be called before creating the clients for a new config and
+// when the exporter is stopped, so nothing from a previous config is kept.
+func ResetSharedState() {
+	resetSessions()
+	resetTransportSources()
+	resetSQLDBs()
+	resetJSONRPCBatches()
+	resetCircuitBreakers()
+	resetLimiters()
+	resetExecSlots()
+	stopStreams()
+}
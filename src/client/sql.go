@@ -0,0 +1,346 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// sqlDBDef describe the connection pool to a database
+type sqlDBDef struct {
+	driver          string
+	dsnFile         string
+	dsnEnv          string
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+}
+
+// defaultSQLDBDef use up to 2 connections to not load the database
+var defaultSQLDBDef = sqlDBDef{
+	maxOpenConns: 2,
+	maxIdleConns: 2,
+}
+
+// sqlDBDefFromOptions read the database settings from the service options, defaults are used for the missing ones
+func sqlDBDefFromOptions(srvOpts config.RextKeyValueStore) (def sqlDBDef, err error) {
+	def = defaultSQLDBDef
+	if def.driver, err = srvOpts.GetString(config.OptKeyRextServiceDefSQLDriver); err != nil {
+		log.WithError(err).Errorln("Can not find the sql driver")
+		return def, err
+	}
+	def.dsnFile, _ = srvOpts.GetString(config.OptKeyRextServiceDefSQLDSNFile)
+	def.dsnEnv, _ = srvOpts.GetString(config.OptKeyRextServiceDefSQLDSNEnv)
+	ints := []struct {
+		key string
+		val *int
+	}{
+		{key: config.OptKeyRextServiceDefSQLMaxOpenConns, val: &def.maxOpenConns},
+		{key: config.OptKeyRextServiceDefSQLMaxIdleConns, val: &def.maxIdleConns},
+	}
+	for _, i := range ints {
+		if iVal, err := srvOpts.GetObject(i.key); err == nil {
+			var okVal bool
+			if *i.val, okVal = iVal.(int); !okVal {
+				log.WithFields(log.Fields{"key": i.key, "val": iVal}).Errorln("value should be an int")
+				return def, config.ErrKeyInvalidType
+			}
+		}
+	}
+	if iVal, err := srvOpts.GetObject(config.OptKeyRextServiceDefSQLConnMaxLifetime); err == nil {
+		var okVal bool
+		if def.connMaxLifetime, okVal = iVal.(time.Duration); !okVal {
+			log.WithField("val", iVal).Errorln("sql connection max lifetime should be a time.Duration")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	return def, nil
+}
+
+// dsn read the data source name from the file or the environment variable
+func (def sqlDBDef) dsn() (dsn string, err error) {
+	if len(def.dsnFile) != 0 {
+		var content []byte
+		if content, err = ioutil.ReadFile(def.dsnFile); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(content)), nil
+	}
+	var found bool
+	if dsn, found = os.LookupEnv(def.dsnEnv); !found {
+		return "", fmt.Errorf("environment variable %s not defined", def.dsnEnv)
+	}
+	return dsn, nil
+}
+
+// sqlDBKey identify a connection pool, the data source name is kept as a hash to not have the credentials in the key
+type sqlDBKey struct {
+	sqlDBDef
+	jobName      string
+	instanceName string
+	dsnHash      [sha256.Size]byte
+}
+
+var (
+	sqlDBsMutex = &sync.Mutex{}
+	sqlDBs      = make(map[sqlDBKey]*sql.DB)
+)
+
+// sqlDBFor return the connection pool for a database, it is opened if not exist. A change in the data source name or
+// in the pool settings open a new pool
+func sqlDBFor(def sqlDBDef, jobName, instanceName string) (db *sql.DB, err error) {
+	const generalScopeErr = "error opening a database"
+	var dsn string
+	if dsn, err = def.dsn(); err != nil {
+		errCause := fmt.Sprintln("can not read the data source name: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	key := sqlDBKey{sqlDBDef: def, jobName: jobName, instanceName: instanceName, dsnHash: sha256.Sum256([]byte(dsn))}
+	sqlDBsMutex.Lock()
+	defer sqlDBsMutex.Unlock()
+	if db, found := sqlDBs[key]; found {
+		return db, nil
+	}
+	if db, err = sql.Open(def.driver, dsn); err != nil {
+		errCause := fmt.Sprintln("can not open the database: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	db.SetMaxOpenConns(def.maxOpenConns)
+	db.SetMaxIdleConns(def.maxIdleConns)
+	db.SetConnMaxLifetime(def.connMaxLifetime)
+	sqlDBs[key] = db
+	return db, nil
+}
+
+// resetSQLDBs close all the connection pools, they are opened again by the clients of the next config
+func resetSQLDBs() {
+	sqlDBsMutex.Lock()
+	defer sqlDBsMutex.Unlock()
+	for key, db := range sqlDBs {
+		if err := db.Close(); err != nil {
+			log.WithError(err).WithField("driver", key.driver).Errorln("can not close the database")
+		}
+	}
+	sqlDBs = make(map[sqlDBKey]*sql.DB)
+}
+
+// SQLCreator have info to create a sql client
+type SQLCreator struct {
+	baseFactory
+	db           *sql.DB
+	query        string
+	timeout      time.Duration
+	valueColumns map[string]bool
+}
+
+// CreateSQLCreator create a SQLCreator, the resource path is used as the data source label only
+func CreateSQLCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf CacheableFactory, err error) {
+	resURI := strings.TrimPrefix(resConf.GetResourcePATH(srvConf.GetBasePath()), srvConf.GetBasePath())
+	resOpts := resConf.GetOptions()
+	srvOpts := srvConf.GetOptions()
+	jobName, err := srvOpts.GetString(config.OptKeyRextServiceDefJobName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find jobName")
+		return cf, err
+	}
+	instanceName, err := srvOpts.GetString(config.OptKeyRextServiceDefInstanceName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find instanceName")
+		return cf, err
+	}
+	sc := SQLCreator{
+		baseFactory: baseFactory{
+			jobName:                        jobName,
+			instanceName:                   instanceName,
+			dataSource:                     resURI,
+			dataSourceResponseDurationDesc: dataSourceResponseDurationDesc,
+		},
+		timeout: 5 * time.Second,
+	}
+	if sc.query, err = resOpts.GetString(config.OptKeyRextResourceDefSQLQuery); err != nil {
+		log.WithError(err).Errorln("Can not find the sql query")
+		return cf, err
+	}
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefSQLTimeout); err == nil {
+		var okVal bool
+		if sc.timeout, okVal = iVal.(time.Duration); !okVal {
+			log.WithField("val", iVal).Errorln("sql timeout should be a time.Duration")
+			return cf, config.ErrKeyInvalidType
+		}
+	}
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefSQLValueColumns); err == nil {
+		columns, okVal := iVal.([]string)
+		if !okVal {
+			log.WithField("val", iVal).Errorln("sql value columns should be an []string")
+			return cf, config.ErrKeyInvalidType
+		}
+		sc.valueColumns = make(map[string]bool)
+		for _, column := range columns {
+			sc.valueColumns[column] = true
+		}
+	}
+	var def sqlDBDef
+	if def, err = sqlDBDefFromOptions(srvOpts); err != nil {
+		log.WithError(err).Errorln("Can not read the database settings")
+		return cf, err
+	}
+	if sc.db, err = sqlDBFor(def, jobName, instanceName); err != nil {
+		log.WithError(err).Errorln("Can not open the database")
+		return cf, err
+	}
+	return sc, nil
+}
+
+// CreateClient create a sql client
+func (sc SQLCreator) CreateClient() (cl CacheableClient, err error) {
+	cl = SQL{
+		baseClient: baseClient{
+			jobName:                        sc.jobName,
+			instanceName:                   sc.instanceName,
+			dataSource:                     sc.dataSource,
+			dataSourceResponseDurationDesc: sc.dataSourceResponseDurationDesc,
+		},
+		baseCacheableClient: baseCacheableClient("sql://" + sc.jobName + "/" + sc.instanceName + "#" + sc.query),
+		db:                  sc.db,
+		query:               sc.query,
+		timeout:             sc.timeout,
+		valueColumns:        sc.valueColumns,
+	}
+	return cl, nil
+}
+
+// SQL run a query and return the rows as a json document
+type SQL struct {
+	baseClient
+	baseCacheableClient
+	db           *sql.DB
+	query        string
+	timeout      time.Duration
+	valueColumns map[string]bool
+}
+
+// sqlNumber convert a column value to a number, nil is returned if it is not numeric
+func sqlNumber(val interface{}) interface{} {
+	switch v := val.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case bool:
+		if v {
+			return float64(1)
+		}
+		return float64(0)
+	case []byte:
+		return sqlNumber(string(v))
+	case string:
+		if num, err := strconv.ParseFloat(v, 64); err == nil {
+			return num
+		}
+	}
+	return nil
+}
+
+// sqlString convert a column value to a string
+func sqlString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// columnValue convert the value of a column, the value columns are decoded as numbers and the other ones as strings,
+// without value columns the numeric types are decoded as numbers
+func (cl SQL) columnValue(column string, val interface{}) interface{} {
+	if cl.valueColumns == nil {
+		switch val.(type) {
+		case int64, float64:
+			return sqlNumber(val)
+		}
+		return sqlString(val)
+	}
+	if cl.valueColumns[column] {
+		return sqlNumber(val)
+	}
+	return sqlString(val)
+}
+
+// GetData run the query and return a json document like {"rows": [{"column": value}], "first_row": {"column":
+// value}, "row_count": 1}
+func (cl SQL) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	const generalScopeErr = "error running a sql query to get metric"
+	ctx, cancel := context.WithTimeout(ctx, cl.timeout)
+	defer cancel()
+	startTime := time.Now().UTC()
+	var rows *sql.Rows
+	if rows, err = cl.db.QueryContext(ctx, cl.query); err != nil {
+		log.WithFields(log.Fields{"err": err, "query": cl.query}).Errorln("can not run the query")
+		errCause := fmt.Sprintln("can not run the query: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	defer rows.Close()
+	var columns []string
+	if columns, err = rows.Columns(); err != nil {
+		errCause := fmt.Sprintln("can not get the columns: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	docRows := []map[string]interface{}{}
+	for rows.Next() {
+		vals := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for idx := range vals {
+			ptrs[idx] = &vals[idx]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			errCause := fmt.Sprintln("can not read a row: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		row := make(map[string]interface{}, len(columns))
+		for idx, column := range columns {
+			row[column] = cl.columnValue(column, vals[idx])
+		}
+		docRows = append(docRows, row)
+	}
+	if err = rows.Err(); err != nil {
+		errCause := fmt.Sprintln("can not read the rows: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	doc := map[string]interface{}{"rows": docRows, "row_count": len(docRows)}
+	if len(docRows) != 0 {
+		doc["first_row"] = docRows[0]
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		errCause := fmt.Sprintln("can not encode the rows: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	labels := []string{cl.jobName, cl.instanceName, cl.dataSource}
+	duration := time.Since(startTime).Seconds()
+	if metric, err := prometheus.NewConstMetric(cl.dataSourceResponseDurationDesc, prometheus.GaugeValue, duration, labels...); err == nil {
+		metricsCollector <- metric
+	} else {
+		log.WithFields(log.Fields{"err": err, "labels": labels}).Errorln("can not send dataSource response duration running a sql query")
+	}
+	return data, nil
+}
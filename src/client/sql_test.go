@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeSQLResults are the rows returned by the fake driver for each query
+var fakeSQLResults = map[string]struct {
+	columns []string
+	rows    [][]driver.Value
+}{
+	"SELECT asset, pending FROM withdrawals": {
+		columns: []string{"asset", "pending"},
+		rows:    [][]driver.Value{{[]byte("SKY"), int64(3)}, {[]byte("BTC"), int64(1)}},
+	},
+	"SELECT id, size, updated FROM queues": {
+		columns: []string{"id", "size", "updated"},
+		rows:    [][]driver.Value{{int64(7), []byte("12.5"), time.Date(2019, 1, 25, 0, 0, 0, 0, time.UTC)}},
+	},
+}
+
+// fakeSQLDSN is the data source name received by the fake driver
+var fakeSQLDSN string
+
+type fakeSQLDriver struct{}
+
+func (fakeSQLDriver) Open(dsn string) (driver.Conn, error) {
+	fakeSQLDSN = dsn
+	return fakeSQLConn{}, nil
+}
+
+type fakeSQLConn struct{}
+
+func (fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (fakeSQLConn) Close() error {
+	return nil
+}
+
+func (fakeSQLConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (fakeSQLConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if query == "SELECT pg_sleep(1)" {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	result, found := fakeSQLResults[query]
+	if !found {
+		return nil, errors.New("syntax error")
+	}
+	return &fakeSQLRows{columns: result.columns, rows: result.rows}, nil
+}
+
+type fakeSQLRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (rows *fakeSQLRows) Columns() []string {
+	return rows.columns
+}
+
+func (rows *fakeSQLRows) Close() error {
+	return nil
+}
+
+func (rows *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(rows.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, rows.rows[0])
+	rows.rows = rows.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("rextporter-fake", fakeSQLDriver{})
+}
+
+type sqlSuit struct {
+	suite.Suite
+}
+
+func (suite *sqlSuit) SetupTest() {
+	// NOTE(denisacostaq@gmail.com): each test start without open databases
+	resetSQLDBs()
+	os.Setenv("REXTPORTER_TEST_DSN", "fake://env")
+}
+
+func TestSQLSuit(t *testing.T) {
+	suite.Run(t, new(sqlSuit))
+}
+
+func (suite *sqlSuit) creator(srvOpts, resOpts map[string]interface{}) (CacheableFactory, error) {
+	opts := map[string]interface{}{config.OptKeyRextServiceDefSQLDriver: "rextporter-fake"}
+	for k, v := range srvOpts {
+		opts[k] = v
+	}
+	srv, res, desc := clientFixture{
+		location:     "db-primary",
+		protocol:     config.ProtocolSQL,
+		jobName:      "wallet",
+		instanceName: "db-primary",
+		srvOpts:      opts,
+		resType:      config.ResourceTypeSQL,
+		resPath:      "/withdrawals",
+		resOpts:      resOpts,
+	}.build(suite.T())
+	return CreateSQLCreator(res, srv, desc, metrics.NewDefaultClientMetrics())
+}
+
+func (suite *sqlSuit) getData(srvOpts, resOpts map[string]interface{}) ([]byte, error) {
+	cf, err := suite.creator(srvOpts, resOpts)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+}
+
+func envDSN() map[string]interface{} {
+	return map[string]interface{}{config.OptKeyRextServiceDefSQLDSNEnv: "REXTPORTER_TEST_DSN"}
+}
+
+func (suite *sqlSuit) TestRowsDocument() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefSQLQuery: "SELECT asset, pending FROM withdrawals"}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(envDSN(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.JSONEq(`{
+		"rows": [{"asset": "SKY", "pending": 3}, {"asset": "BTC", "pending": 1}],
+		"first_row": {"asset": "SKY", "pending": 3},
+		"row_count": 2
+	}`, string(data))
+	suite.Equal("fake://env", fakeSQLDSN)
+}
+
+func (suite *sqlSuit) TestValueColumns() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefSQLQuery:        "SELECT id, size, updated FROM queues",
+		config.OptKeyRextResourceDefSQLValueColumns: []string{"size"},
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(envDSN(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	var doc map[string]interface{}
+	suite.Nil(json.Unmarshal(data, &doc))
+	suite.Equal(map[string]interface{}{"id": "7", "size": 12.5, "updated": "2019-01-25T00:00:00Z"}, doc["first_row"])
+}
+
+func (suite *sqlSuit) TestDSNFileAndPool() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	dsnFile, err := ioutil.TempFile("", "rextporter-dsn")
+	suite.Require().Nil(err)
+	defer os.Remove(dsnFile.Name())
+	dsnFile.WriteString("fake://file\n")
+	dsnFile.Close()
+	srvOpts := map[string]interface{}{
+		config.OptKeyRextServiceDefSQLDSNFile:      dsnFile.Name(),
+		config.OptKeyRextServiceDefSQLMaxOpenConns: 5,
+	}
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefSQLQuery: "SELECT asset, pending FROM withdrawals"}
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err = suite.getData(srvOpts, resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal("fake://file", fakeSQLDSN)
+	suite.Len(sqlDBs, 1)
+	for _, db := range sqlDBs {
+		suite.Equal(5, db.Stats().MaxOpenConnections)
+	}
+}
+
+func (suite *sqlSuit) TestDSNChangeOpenANewPool() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefSQLQuery: "SELECT asset, pending FROM withdrawals"}
+	_, err := suite.getData(envDSN(), resOpts)
+	suite.Require().Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	os.Setenv("REXTPORTER_TEST_DSN", "fake://other-env")
+	_, err = suite.getData(envDSN(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal("fake://other-env", fakeSQLDSN)
+	suite.Len(sqlDBs, 2)
+}
+
+func (suite *sqlSuit) TestPoolSettingsChangeOpenANewPool() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srvOpts := envDSN()
+	srvOpts[config.OptKeyRextServiceDefSQLMaxOpenConns] = 5
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefSQLQuery: "SELECT asset, pending FROM withdrawals"}
+	_, err := suite.getData(srvOpts, resOpts)
+	suite.Require().Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	srvOpts[config.OptKeyRextServiceDefSQLMaxOpenConns] = 7
+	_, err = suite.getData(srvOpts, resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Len(sqlDBs, 2)
+	maxOpenConns := make(map[int]bool)
+	for _, db := range sqlDBs {
+		maxOpenConns[db.Stats().MaxOpenConnections] = true
+	}
+	suite.Equal(map[int]bool{5: true, 7: true}, maxOpenConns)
+}
+
+func (suite *sqlSuit) TestResetSharedStateCloseThePools() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefSQLQuery: "SELECT asset, pending FROM withdrawals"}
+	_, err := suite.getData(envDSN(), resOpts)
+	suite.Require().Nil(err)
+	suite.Require().Len(sqlDBs, 1)
+	var db *sql.DB
+	for _, opened := range sqlDBs {
+		db = opened
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	ResetSharedState()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Len(sqlDBs, 0)
+	suite.NotNil(db.Ping())
+}
+
+func (suite *sqlSuit) TestTimeout() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefSQLQuery:   "SELECT pg_sleep(1)",
+		config.OptKeyRextResourceDefSQLTimeout: 50 * time.Millisecond,
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	startTime := time.Now()
+	_, err := suite.getData(envDSN(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.True(time.Since(startTime) < 500*time.Millisecond)
+}
+
+func (suite *sqlSuit) TestUnknownDriver() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srvOpts := envDSN()
+	srvOpts[config.OptKeyRextServiceDefSQLDriver] = "missing-driver"
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefSQLQuery: "SELECT 1"}
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.creator(srvOpts, resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+}
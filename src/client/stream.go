@@ -0,0 +1,440 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// streamDef describe a subscription to a stream
+type streamDef struct {
+	kind           string
+	url            string
+	subscribe      string
+	window         int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// defaultStreamDef keep the latest message and reconnect after 1 second up to 1 minute
+var defaultStreamDef = streamDef{
+	window:         1,
+	initialBackoff: time.Second,
+	maxBackoff:     time.Minute,
+}
+
+// streamDefFromOptions read the stream settings from the resource options, defaults are used for the missing ones
+func streamDefFromOptions(url string, resOpts config.RextKeyValueStore) (def streamDef, err error) {
+	def = defaultStreamDef
+	// NOTE(denisacostaq@gmail.com): the websocket handshake is an http request
+	def.url = url
+	for scheme, httpScheme := range map[string]string{"ws://": "http://", "wss://": "https://"} {
+		if strings.HasPrefix(url, scheme) {
+			def.url = httpScheme + strings.TrimPrefix(url, scheme)
+		}
+	}
+	if def.kind, err = resOpts.GetString(config.OptKeyRextResourceDefStreamKind); err != nil {
+		log.WithError(err).Errorln("Can not find the stream kind")
+		return def, err
+	}
+	def.subscribe, _ = resOpts.GetString(config.OptKeyRextResourceDefStreamSubscribe)
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefStreamWindow); err == nil {
+		var okVal bool
+		if def.window, okVal = iVal.(int); !okVal {
+			log.WithField("val", iVal).Errorln("stream window should be an int")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	durations := []struct {
+		key string
+		val *time.Duration
+	}{
+		{key: config.OptKeyRextResourceDefStreamReconnectInitialBackoff, val: &def.initialBackoff},
+		{key: config.OptKeyRextResourceDefStreamReconnectMaxBackoff, val: &def.maxBackoff},
+	}
+	for _, duration := range durations {
+		if iVal, err := resOpts.GetObject(duration.key); err == nil {
+			var okVal bool
+			if *duration.val, okVal = iVal.(time.Duration); !okVal {
+				log.WithFields(log.Fields{"key": duration.key, "val": iVal}).Errorln("value should be a time.Duration")
+				return def, config.ErrKeyInvalidType
+			}
+		}
+	}
+	return def, nil
+}
+
+// key identify the subscription, the resources with the same key share it
+func (def streamDef) key() string {
+	return strings.Join([]string{def.kind, def.url, def.subscribe, strconv.Itoa(def.window)}, "|")
+}
+
+// backoff return the wait before the reconnection number attempt(starting at 1)
+func (def streamDef) backoff(attempt int) time.Duration {
+	wait := def.initialBackoff
+	for i := 1; i < attempt && wait < def.maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > def.maxBackoff {
+		wait = def.maxBackoff
+	}
+	return wait
+}
+
+// streamSubscription keep a long-lived connection to a stream and the latest messages received
+type streamSubscription struct {
+	baseFactory
+	def           streamDef
+	transport     *transportSource
+	auth          authStrategy
+	clientMetrics *metrics.DefaultClientMetrics
+	cancel        context.CancelFunc
+	mutex         *sync.Mutex
+	messages      [][]byte
+	count         uint64
+}
+
+var (
+	streamsMutex = &sync.Mutex{}
+	streams      = make(map[string]*streamSubscription)
+)
+
+// streamFor return the subscription for a stream, it is created and started if not exist
+func streamFor(def streamDef, factory baseFactory, ts *transportSource, auth authStrategy, clientMetrics *metrics.DefaultClientMetrics) *streamSubscription {
+	streamsMutex.Lock()
+	defer streamsMutex.Unlock()
+	key := factory.jobName + "|" + factory.instanceName + "|" + def.key()
+	if s, found := streams[key]; found {
+		return s
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &streamSubscription{
+		baseFactory:   factory,
+		def:           def,
+		transport:     ts,
+		auth:          auth,
+		clientMetrics: clientMetrics,
+		cancel:        cancel,
+		mutex:         &sync.Mutex{},
+	}
+	streams[key] = s
+	go s.run(ctx)
+	return s
+}
+
+// stopStreams close all the subscriptions and stop reconnecting them
+func stopStreams() {
+	streamsMutex.Lock()
+	defer streamsMutex.Unlock()
+	for key, s := range streams {
+		s.cancel()
+		delete(streams, key)
+	}
+}
+
+// labels are the labels for the stream metrics
+func (s *streamSubscription) labels() []string {
+	return []string{s.jobName, s.instanceName, s.dataSource}
+}
+
+// push save a message, only the latest window ones are kept
+func (s *streamSubscription) push(message []byte) {
+	s.mutex.Lock()
+	s.count++
+	s.messages = append(s.messages, message)
+	if len(s.messages) > s.def.window {
+		s.messages = s.messages[len(s.messages)-s.def.window:]
+	}
+	s.mutex.Unlock()
+	if s.clientMetrics != nil {
+		s.clientMetrics.DataSourceStreamMessages.WithLabelValues(s.labels()...).Inc()
+	}
+}
+
+// latest return a copy of the latest messages and the number of messages received since the subscription started
+func (s *streamSubscription) latest() (messages [][]byte, count uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([][]byte(nil), s.messages...), s.count
+}
+
+// setConnected update the connection state metric
+func (s *streamSubscription) setConnected(connected bool) {
+	if s.clientMetrics == nil {
+		return
+	}
+	val := 0.0
+	if connected {
+		val = 1
+	}
+	s.clientMetrics.DataSourceStreamConnected.WithLabelValues(s.labels()...).Set(val)
+}
+
+// run keep the subscription until ctx is done, it reconnect with an exponential backoff after a failure, the backoff
+// start again after a connection that received some message
+func (s *streamSubscription) run(ctx context.Context) {
+	for attempt := 1; ; {
+		received, err := s.subscribe(ctx)
+		s.setConnected(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			attempt = 1
+		}
+		wait := s.def.backoff(attempt)
+		log.WithFields(log.Fields{"err": err, "url": s.def.url, "wait": wait}).Warnln("stream disconnected, reconnecting")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if s.clientMetrics != nil {
+			s.clientMetrics.DataSourceStreamReconnects.WithLabelValues(s.labels()...).Inc()
+		}
+		attempt++
+	}
+}
+
+// subscribe connect to the stream and save the messages until the connection fails or ctx is done, received is true
+// if some message was saved
+func (s *streamSubscription) subscribe(ctx context.Context) (received bool, err error) {
+	const generalScopeErr = "error subscribing to a stream"
+	var rt http.RoundTripper
+	if rt, err = s.transport.roundTripper(); err != nil {
+		errCause := fmt.Sprintln("can not get the transport: ", err.Error())
+		return false, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	// NOTE(denisacostaq@gmail.com): the connection is long-lived, so no timeout nor the service limits are used
+	httpClient := &http.Client{Transport: rt}
+	if s.auth != nil {
+		s.auth.prepareClient(httpClient)
+	}
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodGet, s.def.url, nil); err != nil {
+		errCause := fmt.Sprintln("can not create the request: ", err.Error())
+		return false, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	req = req.WithContext(ctx)
+	if s.auth != nil {
+		metricsCollector := make(chan prometheus.Metric)
+		go func() {
+			for range metricsCollector {
+			}
+		}()
+		err = s.auth.authenticate(ctx, req, metricsCollector)
+		close(metricsCollector)
+		if err != nil {
+			errCause := fmt.Sprintln("can not authenticate the request: ", err.Error())
+			return false, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	if s.def.kind == config.StreamWebSocket {
+		return s.subscribeWebsocket(ctx, httpClient, req)
+	}
+	return s.subscribeSSE(httpClient, req)
+}
+
+// subscribeWebsocket save the messages received from a WebSocket
+func (s *streamSubscription) subscribeWebsocket(ctx context.Context, httpClient *http.Client, req *http.Request) (received bool, err error) {
+	var conn *websocketConn
+	if conn, err = dialWebsocket(httpClient, req); err != nil {
+		return false, err
+	}
+	defer func() {
+		if errClose := conn.Close(); errClose != nil {
+			log.WithError(errClose).Warnln("can not close the websocket")
+		}
+	}()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// NOTE(denisacostaq@gmail.com): the close error is reported by the deferred Close
+			conn.Close()
+		case <-stop:
+		}
+	}()
+	if len(s.def.subscribe) != 0 {
+		if err = conn.WriteMessage([]byte(s.def.subscribe)); err != nil {
+			return false, err
+		}
+	}
+	s.setConnected(true)
+	for {
+		var message []byte
+		if message, err = conn.ReadMessage(); err != nil {
+			return received, err
+		}
+		s.push(message)
+		received = true
+	}
+}
+
+// subscribeSSE save the data of the events received from a Server-Sent Events endpoint
+func (s *streamSubscription) subscribeSSE(httpClient *http.Client, req *http.Request) (received bool, err error) {
+	const generalScopeErr = "error subscribing to a server-sent events stream"
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		errCause := fmt.Sprintln("can not do the request: ", err.Error())
+		return false, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errCause := fmt.Sprintf("no success response, status %s", resp.Status)
+		return false, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	s.setConnected(true)
+	reader := bufio.NewReader(resp.Body)
+	var data []string
+	for {
+		var line string
+		if line, err = reader.ReadString('\n'); err != nil {
+			if err == io.EOF {
+				err = util.ErrorFromThisScope("stream closed by the server", generalScopeErr)
+			}
+			return received, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case len(line) == 0:
+			// NOTE(denisacostaq@gmail.com): a blank line dispatch the event
+			if data != nil {
+				s.push([]byte(strings.Join(data, "\n")))
+				received = true
+				data = nil
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case line == "data":
+			data = append(data, "")
+		}
+	}
+}
+
+// StreamCreator have info to create a stream client
+type StreamCreator struct {
+	subscription *streamSubscription
+	withCount    bool
+}
+
+// CreateStreamCreator create a StreamCreator, the subscription to the resource path is started if it was not
+func CreateStreamCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf CacheableFactory, err error) {
+	resOpts := resConf.GetOptions()
+	resURI := strings.TrimPrefix(resConf.GetResourcePATH(srvConf.GetBasePath()), srvConf.GetBasePath())
+	srvOpts := srvConf.GetOptions()
+	jobName, err := srvOpts.GetString(config.OptKeyRextServiceDefJobName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find jobName")
+		return cf, err
+	}
+	instanceName, err := srvOpts.GetString(config.OptKeyRextServiceDefInstanceName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find instanceName")
+		return cf, err
+	}
+	var def streamDef
+	if def, err = streamDefFromOptions(resConf.GetResourcePATH(srvConf.GetBasePath()), resOpts); err != nil {
+		log.WithError(err).Errorln("Can not read the stream settings")
+		return cf, err
+	}
+	withCount := false
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefStreamWithCount); err == nil {
+		var okVal bool
+		if withCount, okVal = iVal.(bool); !okVal {
+			log.WithField("val", iVal).Errorln("stream with count should be a bool")
+			return cf, config.ErrKeyInvalidType
+		}
+	}
+	var ts *transportSource
+	if ts, err = transportSourceFor(resOpts, srvOpts, jobName, instanceName, cDefMetrics); err != nil {
+		log.WithError(err).Errorln("Can not read the http settings")
+		return cf, err
+	}
+	var auth authStrategy
+	if auth, err = createAuthStrategy(resConf.GetAuth(srvConf.GetAuthForBaseURL()), srvConf, jobName, instanceName, dataSourceResponseDurationDesc, ts); err != nil {
+		log.WithError(err).Errorln("Can not create the auth")
+		return cf, err
+	}
+	factory := baseFactory{
+		jobName:                        jobName,
+		instanceName:                   instanceName,
+		dataSource:                     resURI,
+		dataSourceResponseDurationDesc: dataSourceResponseDurationDesc,
+	}
+	return StreamCreator{subscription: streamFor(def, factory, ts, auth, cDefMetrics), withCount: withCount}, nil
+}
+
+// CreateClient create a stream client
+func (sc StreamCreator) CreateClient() (cl CacheableClient, err error) {
+	dataPath := "stream://" + sc.subscription.jobName + "/" + sc.subscription.def.key()
+	if sc.withCount {
+		dataPath += "#count"
+	}
+	cl = Stream{
+		baseCacheableClient: baseCacheableClient(dataPath),
+		subscription:        sc.subscription,
+		withCount:           sc.withCount,
+	}
+	return cl, nil
+}
+
+// Stream read the latest messages received in a subscription
+type Stream struct {
+	baseCacheableClient
+	subscription *streamSubscription
+	withCount    bool
+}
+
+// streamWithCount is the data of a stream client with count
+type streamWithCount struct {
+	Count  uint64          `json:"count"`
+	Latest json.RawMessage `json:"latest"`
+}
+
+// GetData return the latest message, or a json array with the latest ones if the window is greater than one, an
+// error is returned if no message was received yet. With count the data is a json object with the number of messages
+// received in the count node and the latest ones in the latest node
+func (cl Stream) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	const generalScopeErr = "error reading the latest messages from a stream"
+	messages, count := cl.subscription.latest()
+	if len(messages) == 0 {
+		return nil, util.ErrorFromThisScope("no message received yet", generalScopeErr)
+	}
+	if cl.subscription.def.window == 1 {
+		data = messages[0]
+	} else {
+		window := make([]json.RawMessage, len(messages))
+		for idx, message := range messages {
+			window[idx] = json.RawMessage(message)
+		}
+		if data, err = json.Marshal(window); err != nil {
+			errCause := fmt.Sprintln("can not encode the messages as a json array: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	if !cl.withCount {
+		return data, nil
+	}
+	if data, err = json.Marshal(streamWithCount{Count: count, Latest: json.RawMessage(data)}); err != nil {
+		errCause := fmt.Sprintln("can not encode the messages count: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return data, nil
+}
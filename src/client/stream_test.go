@@ -0,0 +1,269 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type streamSuit struct {
+	suite.Suite
+	testServer    *httptest.Server
+	connections   int32
+	subscribed    chan string
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+// writeServerFrame send an unmasked frame like a websocket server
+func writeServerFrame(w *bufio.ReadWriter, fin bool, opcode byte, payload string) {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	w.Write([]byte{first, byte(len(payload))})
+	w.WriteString(payload)
+	w.Flush()
+}
+
+// readClientFrame read a masked frame like a websocket server, the frames are expected to be small
+func readClientFrame(r *bufio.Reader) (opcode byte, payload []byte, err error) {
+	header := make([]byte, 6)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, header[1]&0x7F)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for idx := range payload {
+		payload[idx] ^= header[2+idx%4]
+	}
+	return header[0] & 0x0F, payload, nil
+}
+
+func (suite *streamSuit) SetupTest() {
+	suite.connections = 0
+	suite.subscribed = make(chan string, 1)
+	suite.clientMetrics = metrics.NewDefaultClientMetrics()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&suite.connections, 1)
+		suite.Equal("text/event-stream", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": comment\nevent: block\ndata: {\"seq\": 57}\n\n")
+		fmt.Fprint(w, "id: 2\ndata: {\"seq\":\ndata: 58}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&suite.connections, 1)
+		fmt.Fprint(w, "data: {\"seq\": 1}\n\n")
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&suite.connections, 1)
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		suite.Require().Nil(err)
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", websocketAccept(r.Header.Get("Sec-WebSocket-Key")))
+		rw.Flush()
+		_, subscribe, err := readClientFrame(rw.Reader)
+		suite.Nil(err)
+		suite.subscribed <- string(subscribe)
+		writeServerFrame(rw, true, websocketOpText, `{"peers": 3}`)
+		writeServerFrame(rw, true, websocketOpPing, "")
+		writeServerFrame(rw, false, websocketOpText, `{"peers"`)
+		writeServerFrame(rw, true, websocketOpContinuation, `: 4}`)
+		opcode, _, err := readClientFrame(rw.Reader)
+		suite.Nil(err)
+		suite.Equal(byte(websocketOpPong), opcode)
+		<-r.Context().Done()
+	})
+	suite.testServer = httptest.NewServer(mux)
+}
+
+func (suite *streamSuit) TearDownTest() {
+	stopStreams()
+	suite.testServer.CloseClientConnections()
+	suite.testServer.Close()
+}
+
+func TestStreamSuit(t *testing.T) {
+	suite.Run(t, new(streamSuit))
+}
+
+func (suite *streamSuit) client(resPath string, resOpts map[string]interface{}) CacheableClient {
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		jobName:      "node",
+		instanceName: "localhost:6420",
+		resType:      config.ResourceTypeStream,
+		resPath:      resPath,
+		resOpts:      resOpts,
+	}.build(suite.T())
+	cf, err := CreateStreamCreator(res, srv, desc, suite.clientMetrics)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl
+}
+
+// waitForData return the data from cl once it is equal to expected, or the last one read after a second
+func (suite *streamSuit) waitForData(cl Client, expected string) (data string) {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if body, err := cl.GetData(context.Background(), make(chan prometheus.Metric, 10)); err == nil {
+			if data = string(body); data == expected {
+				return data
+			}
+		}
+	}
+	return data
+}
+
+func (suite *streamSuit) counter(counterVec *prometheus.CounterVec, dataSource string) float64 {
+	var metric io_prometheus_client.Metric
+	counter := counterVec.WithLabelValues("node", "localhost:6420", dataSource)
+	suite.Require().Nil(counter.Write(&metric))
+	return metric.GetCounter().GetValue()
+}
+
+func (suite *streamSuit) TestSSELatestMessage() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cl := suite.client("/events", map[string]interface{}{config.OptKeyRextResourceDefStreamKind: config.StreamSSE})
+
+	// NOTE(denisacostaq@gmail.com): When
+	data := suite.waitForData(cl, "{\"seq\":\n58}")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal("{\"seq\":\n58}", data)
+	suite.Equal(float64(2), suite.counter(suite.clientMetrics.DataSourceStreamMessages, "/events"))
+}
+
+func (suite *streamSuit) TestSSEWindow() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefStreamKind:   config.StreamSSE,
+		config.OptKeyRextResourceDefStreamWindow: 5,
+	}
+	cl := suite.client("/events", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data := suite.waitForData(cl, `[{"seq":57},{"seq":58}]`)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal(`[{"seq":57},{"seq":58}]`, data)
+}
+
+func (suite *streamSuit) TestSSEWithCount() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefStreamKind:      config.StreamSSE,
+		config.OptKeyRextResourceDefStreamWithCount: true,
+	}
+	cl := suite.client("/events", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data := suite.waitForData(cl, `{"count":2,"latest":{"seq":58}}`)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal(`{"count":2,"latest":{"seq":58}}`, data)
+}
+
+func (suite *streamSuit) TestSubscriptionIsShared() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefStreamKind: config.StreamSSE}
+	cl1 := suite.client("/events", resOpts)
+	cl2 := suite.client("/events", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	suite.waitForData(cl1, "{\"seq\":\n58}")
+	suite.waitForData(cl2, "{\"seq\":\n58}")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal(int32(1), atomic.LoadInt32(&suite.connections))
+}
+
+func (suite *streamSuit) TestResetSharedStateStopSubscriptions() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefStreamKind: config.StreamSSE}
+	suite.waitForData(suite.client("/events", resOpts), "{\"seq\":\n58}")
+
+	// NOTE(denisacostaq@gmail.com): When
+	ResetSharedState()
+	connected := 1.0
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline) && connected != 0; {
+		time.Sleep(10 * time.Millisecond)
+		var metric io_prometheus_client.Metric
+		gauge := suite.clientMetrics.DataSourceStreamConnected.WithLabelValues("node", "localhost:6420", "/events")
+		suite.Require().Nil(gauge.Write(&metric))
+		connected = metric.GetGauge().GetValue()
+	}
+	cl := suite.client("/events", resOpts)
+	suite.waitForData(cl, "{\"seq\":\n58}")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal(float64(0), connected)
+	suite.Equal(int32(2), atomic.LoadInt32(&suite.connections))
+}
+
+func (suite *streamSuit) TestReconnect() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefStreamKind:                    config.StreamSSE,
+		config.OptKeyRextResourceDefStreamReconnectInitialBackoff: 10 * time.Millisecond,
+	}
+	suite.client("/flaky", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline) && atomic.LoadInt32(&suite.connections) < 3; {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.True(atomic.LoadInt32(&suite.connections) >= 3)
+	suite.True(suite.counter(suite.clientMetrics.DataSourceStreamReconnects, "/flaky") >= 2)
+}
+
+func (suite *streamSuit) TestWebsocket() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefStreamKind:      config.StreamWebSocket,
+		config.OptKeyRextResourceDefStreamSubscribe: `{"subscribe": "peers"}`,
+	}
+	cl := suite.client("/ws", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data := suite.waitForData(cl, `{"peers": 4}`)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Equal(`{"subscribe": "peers"}`, <-suite.subscribed)
+	suite.Equal(`{"peers": 4}`, data)
+}
+
+func (suite *streamSuit) TestNoMessageYet() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefStreamKind:                    config.StreamSSE,
+		config.OptKeyRextResourceDefStreamReconnectInitialBackoff: time.Minute,
+	}
+	cl := suite.client("/missing", resOpts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(data)
+	suite.NotNil(err)
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// tcpDef describe a request to a text protocol over tcp
+type tcpDef struct {
+	address    string
+	command    string
+	terminator string
+	timeout    time.Duration
+	maxSize    int
+}
+
+// defaultTCPDef wait up to 5 seconds for a response of up to 1MiB
+var defaultTCPDef = tcpDef{
+	timeout: 5 * time.Second,
+	maxSize: 1024 * 1024,
+}
+
+// tcpDefFromOptions read the tcp settings from the resource options, defaults are used for the missing ones
+func tcpDefFromOptions(address string, resOpts config.RextKeyValueStore) (def tcpDef, err error) {
+	def = defaultTCPDef
+	def.address = address
+	def.command, _ = resOpts.GetString(config.OptKeyRextResourceDefTCPCommand)
+	def.terminator, _ = resOpts.GetString(config.OptKeyRextResourceDefTCPTerminator)
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefTCPTimeout); err == nil {
+		var okVal bool
+		if def.timeout, okVal = iVal.(time.Duration); !okVal {
+			log.WithField("val", iVal).Errorln("tcp timeout should be a time.Duration")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	if iVal, err := resOpts.GetObject(config.OptKeyRextResourceDefTCPMaxSize); err == nil {
+		var okVal bool
+		if def.maxSize, okVal = iVal.(int); !okVal {
+			log.WithField("val", iVal).Errorln("tcp max size should be an int")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	return def, nil
+}
+
+// TCPCreator have info to create a tcp client
+type TCPCreator struct {
+	baseFactory
+	def tcpDef
+}
+
+// CreateTCPCreator create a TCPCreator, the service location is the address and the resource path is used as the
+// data source label only
+func CreateTCPCreator(resConf config.RextResourceDef, srvConf config.RextServiceDef, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (cf CacheableFactory, err error) {
+	resURI := strings.TrimPrefix(resConf.GetResourcePATH(srvConf.GetBasePath()), srvConf.GetBasePath())
+	srvOpts := srvConf.GetOptions()
+	jobName, err := srvOpts.GetString(config.OptKeyRextServiceDefJobName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find jobName")
+		return cf, err
+	}
+	instanceName, err := srvOpts.GetString(config.OptKeyRextServiceDefInstanceName)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find instanceName")
+		return cf, err
+	}
+	var def tcpDef
+	if def, err = tcpDefFromOptions(srvConf.GetBasePath(), resConf.GetOptions()); err != nil {
+		log.WithError(err).Errorln("Can not read the tcp settings")
+		return cf, err
+	}
+	cf = TCPCreator{
+		baseFactory: baseFactory{
+			jobName:                        jobName,
+			instanceName:                   instanceName,
+			dataSource:                     resURI,
+			dataSourceResponseDurationDesc: dataSourceResponseDurationDesc,
+		},
+		def: def,
+	}
+	return cf, nil
+}
+
+// CreateClient create a tcp client
+func (tc TCPCreator) CreateClient() (cl CacheableClient, err error) {
+	cl = TCP{
+		baseClient: baseClient{
+			jobName:                        tc.jobName,
+			instanceName:                   tc.instanceName,
+			dataSource:                     tc.dataSource,
+			dataSourceResponseDurationDesc: tc.dataSourceResponseDurationDesc,
+		},
+		baseCacheableClient: baseCacheableClient("tcp://" + tc.def.address + "/" + tc.def.command),
+		def:                 tc.def,
+	}
+	return cl, nil
+}
+
+// TCP send a command to a text protocol over tcp and return the response as the data
+type TCP struct {
+	baseClient
+	baseCacheableClient
+	def tcpDef
+}
+
+// GetData connect, send the command and read the response until the terminator, without a terminator the response
+// end when the connection is closed or the timeout elapse
+func (cl TCP) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	const generalScopeErr = "error making a tcp request to get metric"
+	ctx, cancel := context.WithTimeout(ctx, cl.def.timeout)
+	defer cancel()
+	startTime := time.Now().UTC()
+	var dialer net.Dialer
+	var conn net.Conn
+	if conn, err = dialer.DialContext(ctx, "tcp", cl.def.address); err != nil {
+		errCause := fmt.Sprintln("can not connect: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	defer conn.Close()
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		conn.SetDeadline(deadline)
+	}
+	if len(cl.def.command) != 0 {
+		if _, err = conn.Write([]byte(cl.def.command + "\r\n")); err != nil {
+			errCause := fmt.Sprintln("can not send the command: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	var response bytes.Buffer
+	terminator := []byte(cl.def.terminator)
+	buf := make([]byte, 4096)
+	for {
+		n, readErr := conn.Read(buf)
+		response.Write(buf[:n])
+		if response.Len() > cl.def.maxSize {
+			errCause := fmt.Sprintf("response bigger than %d bytes", cl.def.maxSize)
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		if len(terminator) != 0 {
+			if idx := bytes.Index(response.Bytes(), terminator); idx != -1 {
+				data = response.Bytes()[:idx]
+				break
+			}
+		}
+		if readErr != nil {
+			netErr, isNetErr := readErr.(net.Error)
+			timedOut := isNetErr && netErr.Timeout()
+			if len(terminator) != 0 || (!timedOut && readErr != io.EOF) || response.Len() == 0 {
+				log.WithFields(log.Fields{"err": readErr, "address": cl.def.address, "command": cl.def.command}).Errorln("can not read the response")
+				errCause := fmt.Sprintln("can not read the response: ", readErr.Error())
+				return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+			}
+			data = response.Bytes()
+			break
+		}
+	}
+	labels := []string{cl.jobName, cl.instanceName, cl.dataSource}
+	duration := time.Since(startTime).Seconds()
+	if metric, err := prometheus.NewConstMetric(cl.dataSourceResponseDurationDesc, prometheus.GaugeValue, duration, labels...); err == nil {
+		metricsCollector <- metric
+	} else {
+		log.WithFields(log.Fields{"err": err, "labels": labels}).Errorln("can not send dataSource response duration reading a tcp response")
+	}
+	return data, nil
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type tcpSuit struct {
+	suite.Suite
+	listener net.Listener
+	commands chan string
+}
+
+func (suite *tcpSuit) SetupTest() {
+	var err error
+	suite.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().Nil(err)
+	suite.commands = make(chan string, 10)
+	go func() {
+		for {
+			conn, err := suite.listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				command, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				command = strings.TrimSpace(command)
+				suite.commands <- command
+				switch command {
+				case "stats":
+					conn.Write([]byte("STAT pid 1234\r\nSTAT curr_connections 10\r\nEND\r\n"))
+					// NOTE(denisacostaq@gmail.com): like memcached, the connection is kept open
+					time.Sleep(time.Second)
+				case "INFO":
+					conn.Write([]byte("# Clients\r\nconnected_clients:7\r\n"))
+				case "hang":
+					conn.Write([]byte("partial:1\r\n"))
+					time.Sleep(time.Second)
+				}
+			}(conn)
+		}
+	}()
+}
+
+func (suite *tcpSuit) TearDownTest() {
+	suite.listener.Close()
+}
+
+func TestTCPSuit(t *testing.T) {
+	suite.Run(t, new(tcpSuit))
+}
+
+func (suite *tcpSuit) getData(address string, resOpts map[string]interface{}) ([]byte, error) {
+	srv, res, desc := clientFixture{
+		location:     address,
+		protocol:     config.ProtocolTCP,
+		jobName:      "cache",
+		instanceName: address,
+		resType:      config.ResourceTypeTCP,
+		resPath:      "/stats",
+		resOpts:      resOpts,
+	}.build(suite.T())
+	cf, err := CreateTCPCreator(res, srv, desc, metrics.NewDefaultClientMetrics())
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+}
+
+func (suite *tcpSuit) TestReadUntilTerminator() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefTCPCommand:    "stats",
+		config.OptKeyRextResourceDefTCPTerminator: "END\r\n",
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	startTime := time.Now()
+	data, err := suite.getData(suite.listener.Addr().String(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal("STAT pid 1234\r\nSTAT curr_connections 10\r\n", string(data))
+	suite.Equal("stats", <-suite.commands)
+	suite.True(time.Since(startTime) < 500*time.Millisecond)
+}
+
+func (suite *tcpSuit) TestReadUntilClosed() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefTCPCommand: "INFO"}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(suite.listener.Addr().String(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal("# Clients\r\nconnected_clients:7\r\n", string(data))
+}
+
+func (suite *tcpSuit) TestReadUntilTimeout() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefTCPCommand: "hang",
+		config.OptKeyRextResourceDefTCPTimeout: 100 * time.Millisecond,
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(suite.listener.Addr().String(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal("partial:1\r\n", string(data))
+}
+
+func (suite *tcpSuit) TestTerminatorNotFound() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefTCPCommand:    "hang",
+		config.OptKeyRextResourceDefTCPTerminator: "END\r\n",
+		config.OptKeyRextResourceDefTCPTimeout:    100 * time.Millisecond,
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(suite.listener.Addr().String(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Nil(data)
+}
+
+func (suite *tcpSuit) TestMaxSize() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	resOpts := map[string]interface{}{
+		config.OptKeyRextResourceDefTCPCommand: "INFO",
+		config.OptKeyRextResourceDefTCPMaxSize: 8,
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(suite.listener.Addr().String(), resOpts)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+}
+
+func (suite *tcpSuit) TestConnectionRefused() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	address := suite.listener.Addr().String()
+	suite.listener.Close()
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(address, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// tlsDef describe the tls settings for a service or resource
+type tlsDef struct {
+	caFile             string
+	certFile           string
+	keyFile            string
+	serverName         string
+	insecureSkipVerify bool
+	minVersion         string
+}
+
+var tlsVersions = map[string]uint16{
+	config.TLSVersion10: tls.VersionTLS10,
+	config.TLSVersion11: tls.VersionTLS11,
+	config.TLSVersion12: tls.VersionTLS12,
+	config.TLSVersion13: tls.VersionTLS13,
+}
+
+// tlsDefFromOptions read the tls settings, a value in resOpts(can be nil) override the one in srvOpts
+func tlsDefFromOptions(resOpts, srvOpts config.RextKeyValueStore) (def tlsDef, err error) {
+	getString := func(key string) string {
+		if resOpts != nil {
+			if val, err := resOpts.GetString(key); err == nil {
+				return val
+			}
+		}
+		if val, err := srvOpts.GetString(key); err == nil {
+			return val
+		}
+		return ""
+	}
+	def.caFile = getString(config.OptKeyRextTLSCAFile)
+	def.certFile = getString(config.OptKeyRextTLSCertFile)
+	def.keyFile = getString(config.OptKeyRextTLSKeyFile)
+	def.serverName = getString(config.OptKeyRextTLSServerName)
+	def.minVersion = getString(config.OptKeyRextTLSMinVersion)
+	for _, opts := range []config.RextKeyValueStore{resOpts, srvOpts} {
+		if opts == nil {
+			continue
+		}
+		if iInsecure, err := opts.GetObject(config.OptKeyRextTLSInsecureSkipVerify); err == nil {
+			var okInsecure bool
+			if def.insecureSkipVerify, okInsecure = iInsecure.(bool); !okInsecure {
+				log.WithField("val", iInsecure).Errorln("tls insecure skip verify should be a bool")
+				return def, config.ErrKeyInvalidType
+			}
+			break
+		}
+	}
+	return def, nil
+}
+
+// files return the certificate files in use
+func (def tlsDef) files() []string {
+	var files []string
+	for _, file := range []string{def.caFile, def.certFile, def.keyFile} {
+		if len(file) != 0 {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// tlsConfig load the certificates and create the tls config
+func (def tlsDef) tlsConfig() (tlsConf *tls.Config, err error) {
+	const generalScopeErr = "error creating the tls config"
+	tlsConf = &tls.Config{
+		ServerName:         def.serverName,
+		InsecureSkipVerify: def.insecureSkipVerify, // nolint gosec
+	}
+	if len(def.minVersion) != 0 {
+		var okVersion bool
+		if tlsConf.MinVersion, okVersion = tlsVersions[def.minVersion]; !okVersion {
+			errCause := fmt.Sprintf("invalid tls min version %s", def.minVersion)
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	if len(def.caFile) != 0 {
+		var caCerts []byte
+		if caCerts, err = ioutil.ReadFile(def.caFile); err != nil {
+			errCause := fmt.Sprintln("can not read the ca file: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		tlsConf.RootCAs = x509.NewCertPool()
+		if !tlsConf.RootCAs.AppendCertsFromPEM(caCerts) {
+			errCause := fmt.Sprintf("no certificates found in ca file %s", def.caFile)
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	if len(def.certFile) != 0 {
+		var cert tls.Certificate
+		if cert, err = tls.LoadX509KeyPair(def.certFile, def.keyFile); err != nil {
+			errCause := fmt.Sprintln("can not load the client certificate: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConf, nil
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type tlsSuit struct {
+	suite.Suite
+	testServer *httptest.Server
+	dir        string
+	clientCA   *x509.Certificate
+	clientKey  *ecdsa.PrivateKey
+}
+
+// newCert create a certificate signed by parent(self signed if nil)
+func (suite *tlsSuit) newCert(cn string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().Nil(err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	suite.Require().Nil(err)
+	cert, err := x509.ParseCertificate(der)
+	suite.Require().Nil(err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	suite.Require().Nil(err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return cert, key, certPEM, keyPEM
+}
+
+func (suite *tlsSuit) writeFile(name string, content []byte, modTime time.Time) string {
+	path := filepath.Join(suite.dir, name)
+	suite.Require().Nil(ioutil.WriteFile(path, content, 0600))
+	suite.Require().Nil(os.Chtimes(path, modTime, modTime))
+	return path
+}
+
+func (suite *tlsSuit) SetupTest() {
+	var err error
+	suite.dir, err = ioutil.TempDir("", "rextporter-tls")
+	suite.Require().Nil(err)
+	suite.clientCA, suite.clientKey, _, _ = suite.newCert("client ca", true, nil, nil)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(suite.clientCA)
+	suite.testServer = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	suite.testServer.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: clientCAs}
+	suite.testServer.StartTLS()
+}
+
+func (suite *tlsSuit) TearDownTest() {
+	suite.testServer.Close()
+	os.RemoveAll(suite.dir)
+}
+
+func TestTLSSuit(t *testing.T) {
+	suite.Run(t, new(tlsSuit))
+}
+
+func (suite *tlsSuit) serverCAFile() string {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: suite.testServer.Certificate().Raw})
+	return suite.writeFile("server-ca.pem", certPEM, time.Now())
+}
+
+func (suite *tlsSuit) getData(srvTLS, resTLS map[string]interface{}) ([]byte, error) {
+	resOpts := map[string]interface{}{config.OptKeyRextResourceDefHTTPMethod: "GET"}
+	for k, v := range resTLS {
+		resOpts[k] = v
+	}
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "https",
+		jobName:      "secure",
+		instanceName: "localhost:8443",
+		srvOpts:      srvTLS,
+		resType:      config.ResourceTypeRestAPI,
+		resPath:      "/api/v1/health",
+		resOpts:      resOpts,
+	}.build(suite.T())
+	cf, err := CreateAPIRestCreator(res, srv, desc, nil)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl.GetData(context.Background(), make(chan prometheus.Metric, 10))
+}
+
+func (suite *tlsSuit) TestUnknownAuthorityFail() {
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(nil, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+}
+
+func (suite *tlsSuit) TestCAFileInService() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srvTLS := map[string]interface{}{config.OptKeyRextTLSCAFile: suite.serverCAFile()}
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(srvTLS, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{}`, string(data))
+}
+
+func (suite *tlsSuit) TestInsecureSkipVerify() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srvTLS := map[string]interface{}{config.OptKeyRextTLSInsecureSkipVerify: true}
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := suite.getData(srvTLS, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+}
+
+func (suite *tlsSuit) TestResourceOverrideService() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	caFile := suite.serverCAFile()
+	srvTLS := map[string]interface{}{
+		config.OptKeyRextTLSCAFile:     caFile,
+		config.OptKeyRextTLSServerName: "rextporter.invalid",
+	}
+	resTLS := map[string]interface{}{config.OptKeyRextTLSServerName: "example.com"}
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, errSrv := suite.getData(srvTLS, nil)
+	_, errRes := suite.getData(srvTLS, resTLS)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(errSrv)
+	suite.Nil(errRes)
+}
+
+func (suite *tlsSuit) TestClientCertificateIsReloaded() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	strict := suite.testServer.TLS.Clone()
+	strict.ClientAuth = tls.RequireAndVerifyClientCert
+	suite.testServer.Close()
+	suite.testServer = httptest.NewUnstartedServer(suite.testServer.Config.Handler)
+	suite.testServer.TLS = strict
+	suite.testServer.StartTLS()
+	_, _, certPEM, keyPEM := suite.newCert("rextporter", false, suite.clientCA, suite.clientKey)
+	modTime := time.Now().Add(-time.Minute)
+	srvTLS := map[string]interface{}{
+		config.OptKeyRextTLSCAFile:   suite.serverCAFile(),
+		config.OptKeyRextTLSCertFile: suite.writeFile("client.pem", certPEM, modTime),
+		config.OptKeyRextTLSKeyFile:  suite.writeFile("client-key.pem", keyPEM, modTime),
+	}
+	_, errValidCert := suite.getData(srvTLS, nil)
+	otherCA, otherCAKey, _, _ := suite.newCert("other ca", true, nil, nil)
+	_, _, certPEM, keyPEM = suite.newCert("rextporter", false, otherCA, otherCAKey)
+	suite.writeFile("client.pem", certPEM, time.Now())
+	suite.writeFile("client-key.pem", keyPEM, time.Now())
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, errUnknownCert := suite.getData(srvTLS, nil)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(errValidCert)
+	suite.NotNil(errUnknownCert)
+}
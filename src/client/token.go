@@ -1,29 +1,43 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/oliveagle/jsonpath"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
 	"github.com/simelo/rextporter/src/util"
 	log "github.com/sirupsen/logrus"
 )
 
 // TokenCreator create token clients
-type TokenCreator baseFactory
+type TokenCreator struct {
+	baseFactory
+	request              requestDef
+	tokenSource          string
+	tokenKeyFromEndpoint string
+	transport            *transportSource
+}
 
 // CreateClient create a token client
-func (tc TokenCreator) CreateClient() (cl Client, err error) {
+func (tc TokenCreator) CreateClient() (cl TokenClient, err error) {
 	const generalScopeErr = "error creating a client to get a toke from remote endpoint for making future requests"
-	// client = TokenClient{baseClient: baseClient{service: service}}
-	// FIXME(denisacostaq@gmail.com): make the "GET" configurable.
 	var req *http.Request
-	if req, err = http.NewRequest("GET", tc.dataSource, nil); err != nil {
+	if req, err = tc.request.newRequest(tc.jobName, tc.instanceName); err != nil {
 		errCause := fmt.Sprintln("can not create the request: ", err.Error())
 		return cl, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
+	tokenSource := tc.tokenSource
+	if len(tokenSource) == 0 {
+		tokenSource = config.AuthTokenSourceJSON
+	}
 	cl = TokenClient{
 		baseClient: baseClient{ // nolint megacheck
 			jobName:                        tc.jobName,
@@ -31,7 +45,10 @@ func (tc TokenCreator) CreateClient() (cl Client, err error) {
 			dataSource:                     tc.dataSource,
 			dataSourceResponseDurationDesc: tc.dataSourceResponseDurationDesc,
 		},
-		req: req,
+		req:                  req,
+		tokenSource:          tokenSource,
+		tokenKeyFromEndpoint: tc.tokenKeyFromEndpoint,
+		transport:            tc.transport,
 	}
 	return cl, nil
 }
@@ -42,14 +59,19 @@ func (tc TokenCreator) CreateClient() (cl Client, err error) {
 // sa newTokenClient method.
 type TokenClient struct {
 	baseClient
-	req *http.Request
+	req                  *http.Request
+	tokenSource          string
+	tokenKeyFromEndpoint string
+	transport            *transportSource
 }
 
-// GetData can get a token value from a remote server
-func (client TokenClient) GetData(metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+func (client TokenClient) do(ctx context.Context, metricsCollector chan<- prometheus.Metric) (resp *http.Response, data []byte, err error) {
 	const generalScopeErr = "error making a server request to get token from remote endpoint"
-	httpClient := &http.Client{}
-	var resp *http.Response
+	httpClient, err := newHTTPClient(client.transport)
+	if err != nil {
+		errCause := fmt.Sprintln("can not create the http client: ", err.Error())
+		return nil, nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
 	{
 		successResponse := false
 		defer func(startTime time.Time) {
@@ -65,20 +87,203 @@ func (client TokenClient) GetData(metricsCollector chan<- prometheus.Metric) (da
 				}
 			}
 		}(time.Now().UTC())
-		if resp, err = httpClient.Do(client.req); err != nil {
+		if resp, err = httpClient.Do(client.req.WithContext(ctx)); err != nil {
 			errCause := fmt.Sprintln("can not do the request: ", err.Error())
-			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+			return nil, nil, util.ErrorFromThisScope(errCause, generalScopeErr)
 		}
 		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
 			errCause := fmt.Sprintf("no success response, status %s", resp.Status)
-			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+			return nil, nil, util.ErrorFromThisScope(errCause, generalScopeErr)
 		}
 		successResponse = true
 	}
 	defer resp.Body.Close()
 	if data, err = ioutil.ReadAll(resp.Body); err != nil {
 		errCause := fmt.Sprintln("can not read the body: ", err.Error())
-		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		return nil, nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return resp, data, nil
+}
+
+// GetData can get a token response from a remote server
+func (client TokenClient) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) (data []byte, err error) {
+	_, data, err = client.do(ctx, metricsCollector)
+	return data, err
+}
+
+// GetToken can get a token value from a remote server, the token is located in the response json body, in a
+// response header or in a cookie depending on the token source
+func (client TokenClient) GetToken(ctx context.Context, metricsCollector chan<- prometheus.Metric) (token string, err error) {
+	const generalScopeErr = "error getting a token from remote endpoint"
+	var resp *http.Response
+	var data []byte
+	if resp, data, err = client.do(ctx, metricsCollector); err != nil {
+		errCause := fmt.Sprintln("can make the request to get a token: ", err.Error())
+		return "", util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	switch client.tokenSource {
+	case config.AuthTokenSourceHeader:
+		token = resp.Header.Get(client.tokenKeyFromEndpoint)
+	case config.AuthTokenSourceCookie:
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == client.tokenKeyFromEndpoint {
+				token = cookie.Value
+				break
+			}
+		}
+	default:
+		var jsonData interface{}
+		if err = json.Unmarshal(data, &jsonData); err != nil {
+			errCause := fmt.Sprintln("can not decode the body: ", string(data), " ", err.Error())
+			return "", util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		var val interface{}
+		jPath := "$" + strings.Replace(client.tokenKeyFromEndpoint, "/", ".", -1)
+		if val, err = jsonpath.JsonPathLookup(jsonData, jPath); err != nil {
+			errCause := fmt.Sprintln("can not locate the path: ", err.Error())
+			return "", util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		var okToken bool
+		if token, okToken = val.(string); !okToken {
+			errCause := fmt.Sprintln("unable the get the token as a string value")
+			return "", util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+	}
+	if len(token) == 0 {
+		errCause := fmt.Sprintf("unable the get a not null(empty) token from %s %s", client.tokenSource, client.tokenKeyFromEndpoint)
+		return "", util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return token, nil
+}
+
+// tokenHolder keep the last token obtained, it is shared between all the clients created by the same
+// factory so a token survive across scrapes
+type tokenHolder struct {
+	mutex *sync.RWMutex
+	token string
+}
+
+func newTokenHolder() *tokenHolder {
+	return &tokenHolder{mutex: &sync.RWMutex{}}
+}
+
+func (th *tokenHolder) get() string {
+	th.mutex.RLock()
+	defer th.mutex.RUnlock()
+	return th.token
+}
+
+func (th *tokenHolder) set(token string) {
+	th.mutex.Lock()
+	defer th.mutex.Unlock()
+	th.token = token
+}
+
+// tokenAuth implements the authStrategy for the CSRF auth type, a token is requested to a remote endpoint
+// and sent in each request
+type tokenAuth struct {
+	tokenCreator   TokenCreator
+	tokenHeaderKey string
+	tokenInjectAs  string
+	token          *tokenHolder
+}
+
+func createTokenAuth(auth config.RextAuthDef, srvConf config.RextServiceDef, jobName, instanceName string, dataSourceResponseDurationDesc *prometheus.Desc, ts *transportSource) (strategy authStrategy, err error) {
+	authOpts := auth.GetOptions()
+	tkHeaderKey, err := authOpts.GetString(config.OptKeyRextAuthDefTokenHeaderKey)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find tokenHeaderKey")
+		return strategy, err
+	}
+	tkKeyFromEndpoint, err := authOpts.GetString(config.OptKeyRextAuthDefTokenKeyFromEndpoint)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find tokenKeyFromEndpoint")
+		return strategy, err
+	}
+	tkKeyGenEndpoint, err := authOpts.GetString(config.OptKeyRextAuthDefTokenGenEndpoint)
+	if err != nil {
+		log.WithError(err).Errorln("Can not find tkKeyGenEndpoint")
+		return strategy, err
+	}
+	var request requestDef
+	if request, err = requestDefFromOptions(authOpts, srvConf.GetBasePath()+tkKeyGenEndpoint, config.OptKeyRextAuthDefTokenGenHTTPMethod, http.MethodGet, config.OptKeyRextAuthDefTokenGenBody, config.OptKeyRextAuthDefTokenGenHeaders); err != nil {
+		log.WithError(err).Errorln("Can not read the token gen request")
+		return strategy, err
+	}
+	// NOTE(denisacostaq@gmail.com): the token source and injection are optional, defaults are used if not present
+	tkSource, _ := authOpts.GetString(config.OptKeyRextAuthDefTokenSource)
+	tkInjectAs, _ := authOpts.GetString(config.OptKeyRextAuthDefTokenInjectAs)
+	strategy = tokenAuth{
+		tokenCreator: TokenCreator{
+			baseFactory: baseFactory{
+				jobName:                        jobName,
+				instanceName:                   instanceName,
+				dataSource:                     request.url,
+				dataSourceResponseDurationDesc: dataSourceResponseDurationDesc,
+			},
+			request:              request,
+			tokenSource:          tkSource,
+			tokenKeyFromEndpoint: tkKeyFromEndpoint,
+			transport:            ts,
+		},
+		tokenHeaderKey: tkHeaderKey,
+		tokenInjectAs:  tkInjectAs,
+		token:          newTokenHolder(),
+	}
+	return strategy, nil
+}
+
+func (ta tokenAuth) prepareClient(httpClient *http.Client) {
+}
+
+func (ta tokenAuth) authenticate(ctx context.Context, req *http.Request, metricsCollector chan<- prometheus.Metric) error {
+	if len(ta.tokenHeaderKey) != 0 {
+		injectToken(req, ta.tokenInjectAs, ta.tokenHeaderKey, ta.token.get())
+	}
+	return nil
+}
+
+// rejected return true if the server answer with an unauthorized status, a token reset make no sense for
+// other failures like a server error or a connection error
+func (ta tokenAuth) rejected(resp *http.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden)
+}
+
+func (ta tokenAuth) renew(ctx context.Context, metricsCollector chan<- prometheus.Metric) (err error) {
+	const generalScopeErr = "error making resetting the token"
+	ta.token.set("")
+	var tokenClient TokenClient
+	if tokenClient, err = ta.tokenCreator.CreateClient(); err != nil {
+		errCause := fmt.Sprintln("create token client: ", err.Error())
+		return util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	var tk string
+	if tk, err = tokenClient.GetToken(ctx, metricsCollector); err != nil {
+		errCause := fmt.Sprintln("can make the request to get a token: ", err.Error())
+		return util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	ta.token.set(tk)
+	return nil
+}
+
+// injectToken put the token in the request as a header, a query parameter or a cookie
+func injectToken(req *http.Request, injectAs, key, token string) {
+	switch injectAs {
+	case config.AuthTokenInjectAsQuery:
+		query := req.URL.Query()
+		query.Set(key, token)
+		req.URL.RawQuery = query.Encode()
+	case config.AuthTokenInjectAsCookie:
+		cookies := req.Cookies()
+		req.Header.Del("Cookie")
+		for _, cookie := range cookies {
+			if cookie.Name != key {
+				req.AddCookie(cookie)
+			}
+		}
+		req.AddCookie(&http.Cookie{Name: key, Value: token})
+	default:
+		req.Header.Set(key, token)
 	}
-	return data, nil
 }
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+type tokenSuit struct {
+	suite.Suite
+	testServer *httptest.Server
+	tokenReqs  []*http.Request
+	tokenBody  []string
+}
+
+func (suite *tokenSuit) SetupTest() {
+	suite.tokenReqs = nil
+	suite.tokenBody = nil
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json-token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"csrf_token": "tk1"}}`))
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		suite.tokenReqs = append(suite.tokenReqs, r)
+		suite.tokenBody = append(suite.tokenBody, string(body))
+		w.Header().Set("X-Auth-Token", "tk2")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "tk3"})
+	})
+	mux.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") == "tk2" {
+			w.Write([]byte(`{"by": "query"}`))
+			return
+		}
+		if cookie, err := r.Cookie("session"); err == nil && cookie.Value == "tk3" {
+			w.Write([]byte(`{"by": "cookie"}`))
+			return
+		}
+		if r.Header.Get("X-CSRF-Token") == "tk1" {
+			w.Write([]byte(`{"by": "header"}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	})
+	suite.testServer = httptest.NewServer(mux)
+}
+
+func (suite *tokenSuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestTokenSuit(t *testing.T) {
+	suite.Run(t, new(tokenSuit))
+}
+
+func (suite *tokenSuit) apiRestCreator(authOpts map[string]interface{}) CacheableFactory {
+	auth := memconfig.NewHTTPAuth(config.AuthTypeCSRF, "", memconfig.NewOptionsMap())
+	for k, v := range authOpts {
+		_, err := auth.GetOptions().SetObject(k, v)
+		suite.Nil(err)
+	}
+	srv, res, desc := clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		auth:         auth,
+		jobName:      "skycoin",
+		instanceName: "localhost:6420",
+		resType:      config.ResourceTypeRestAPI,
+		resPath:      "/api/v1/health",
+		resOpts:      map[string]interface{}{config.OptKeyRextResourceDefHTTPMethod: "GET"},
+	}.build(suite.T())
+	cf, err := CreateAPIRestCreator(res, srv, desc, nil)
+	suite.Require().Nil(err)
+	return cf
+}
+
+func (suite *tokenSuit) getData(cf CacheableFactory) ([]byte, error) {
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	metricsCollector := make(chan prometheus.Metric, 10)
+	return cl.GetData(context.Background(), metricsCollector)
+}
+
+func (suite *tokenSuit) TestTokenFromJSONInjectedAsHeader() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cf := suite.apiRestCreator(map[string]interface{}{
+		config.OptKeyRextAuthDefTokenHeaderKey:       "X-CSRF-Token",
+		config.OptKeyRextAuthDefTokenGenEndpoint:     "/json-token",
+		config.OptKeyRextAuthDefTokenKeyFromEndpoint: "/data/csrf_token",
+	})
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(cf)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{"by": "header"}`, string(data))
+}
+
+func (suite *tokenSuit) TestTokenFromHeaderWithTemplatedPostInjectedAsQuery() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	suite.Nil(os.Setenv("REXTPORTER_TEST_PASSWORD", "secret"))
+	defer os.Unsetenv("REXTPORTER_TEST_PASSWORD")
+	cf := suite.apiRestCreator(map[string]interface{}{
+		config.OptKeyRextAuthDefTokenHeaderKey:       "access_token",
+		config.OptKeyRextAuthDefTokenGenEndpoint:     "/login",
+		config.OptKeyRextAuthDefTokenKeyFromEndpoint: "X-Auth-Token",
+		config.OptKeyRextAuthDefTokenGenHTTPMethod:   http.MethodPost,
+		config.OptKeyRextAuthDefTokenGenBody:         `{"user": "{{.JobName}}", "password": "{{env "REXTPORTER_TEST_PASSWORD"}}"}`,
+		config.OptKeyRextAuthDefTokenGenHeaders:      map[string]string{"Content-Type": "application/json"},
+		config.OptKeyRextAuthDefTokenSource:          config.AuthTokenSourceHeader,
+		config.OptKeyRextAuthDefTokenInjectAs:        config.AuthTokenInjectAsQuery,
+	})
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(cf)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{"by": "query"}`, string(data))
+	suite.Require().Len(suite.tokenReqs, 1)
+	suite.Equal(http.MethodPost, suite.tokenReqs[0].Method)
+	suite.Equal("application/json", suite.tokenReqs[0].Header.Get("Content-Type"))
+	suite.Equal(`{"user": "skycoin", "password": "secret"}`, suite.tokenBody[0])
+}
+
+func (suite *tokenSuit) TestTokenFromCookieInjectedAsCookieIsReused() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	cf := suite.apiRestCreator(map[string]interface{}{
+		config.OptKeyRextAuthDefTokenHeaderKey:       "session",
+		config.OptKeyRextAuthDefTokenGenEndpoint:     "/login",
+		config.OptKeyRextAuthDefTokenKeyFromEndpoint: "session",
+		config.OptKeyRextAuthDefTokenSource:          config.AuthTokenSourceCookie,
+		config.OptKeyRextAuthDefTokenInjectAs:        config.AuthTokenInjectAsCookie,
+	})
+
+	// NOTE(denisacostaq@gmail.com): When
+	data1, err1 := suite.getData(cf)
+	data2, err2 := suite.getData(cf)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.Nil(err2)
+	suite.Equal(`{"by": "cookie"}`, string(data1))
+	suite.Equal(`{"by": "cookie"}`, string(data2))
+	suite.Len(suite.tokenReqs, 1)
+}
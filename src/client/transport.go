@@ -0,0 +1,293 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// transportDef describe the http settings for a service
+type transportDef struct {
+	timeout             time.Duration
+	connectTimeout      time.Duration
+	tlsHandshakeTimeout time.Duration
+	keepAlive           time.Duration
+	disableKeepAlives   bool
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     time.Duration
+	proxyURL            string
+	socketPath          string
+}
+
+// defaultTransportDef are the http settings used if no one is configured, the main difference with the
+// http.DefaultClient is the overall timeout, a request should never block a worker forever
+var defaultTransportDef = transportDef{
+	timeout:             10 * time.Second,
+	connectTimeout:      5 * time.Second,
+	tlsHandshakeTimeout: 5 * time.Second,
+	keepAlive:           30 * time.Second,
+	maxIdleConns:        100,
+	maxIdleConnsPerHost: 4,
+	idleConnTimeout:     90 * time.Second,
+}
+
+// transportDefFromOptions read the http settings from the service options, defaults are used for the missing ones
+func transportDefFromOptions(srvOpts config.RextKeyValueStore) (def transportDef, err error) {
+	def = defaultTransportDef
+	durations := []struct {
+		key string
+		val *time.Duration
+	}{
+		{key: config.OptKeyRextServiceDefHTTPTimeout, val: &def.timeout},
+		{key: config.OptKeyRextServiceDefHTTPConnectTimeout, val: &def.connectTimeout},
+		{key: config.OptKeyRextServiceDefHTTPTLSHandshakeTimeout, val: &def.tlsHandshakeTimeout},
+		{key: config.OptKeyRextServiceDefHTTPKeepAlive, val: &def.keepAlive},
+		{key: config.OptKeyRextServiceDefHTTPIdleConnTimeout, val: &def.idleConnTimeout},
+	}
+	for _, duration := range durations {
+		if iVal, err := srvOpts.GetObject(duration.key); err == nil {
+			var okVal bool
+			if *duration.val, okVal = iVal.(time.Duration); !okVal {
+				log.WithFields(log.Fields{"key": duration.key, "val": iVal}).Errorln("value should be a time.Duration")
+				return def, config.ErrKeyInvalidType
+			}
+		}
+	}
+	ints := []struct {
+		key string
+		val *int
+	}{
+		{key: config.OptKeyRextServiceDefHTTPMaxIdleConns, val: &def.maxIdleConns},
+		{key: config.OptKeyRextServiceDefHTTPMaxIdleConnsPerHost, val: &def.maxIdleConnsPerHost},
+		{key: config.OptKeyRextServiceDefHTTPMaxConnsPerHost, val: &def.maxConnsPerHost},
+	}
+	for _, intVal := range ints {
+		if iVal, err := srvOpts.GetObject(intVal.key); err == nil {
+			var okVal bool
+			if *intVal.val, okVal = iVal.(int); !okVal {
+				log.WithFields(log.Fields{"key": intVal.key, "val": iVal}).Errorln("value should be an int")
+				return def, config.ErrKeyInvalidType
+			}
+		}
+	}
+	if iVal, err := srvOpts.GetObject(config.OptKeyRextServiceDefHTTPDisableKeepAlives); err == nil {
+		var okVal bool
+		if def.disableKeepAlives, okVal = iVal.(bool); !okVal {
+			log.WithField("val", iVal).Errorln("disable keep alives should be a bool")
+			return def, config.ErrKeyInvalidType
+		}
+	}
+	def.proxyURL, _ = srvOpts.GetString(config.OptKeyRextServiceDefHTTPProxyURL)
+	def.socketPath, _ = srvOpts.GetString(config.OptKeyRextServiceDefSocketPath)
+	return def, nil
+}
+
+// transportKey identify a transport, all the resources in a service with the same settings share it
+type transportKey struct {
+	jobName      string
+	instanceName string
+	tls          tlsDef
+	http         transportDef
+}
+
+// transportSource keep the transport(and so the connections pool) for a service, the transport is created
+// again if some of the certificate files change
+type transportSource struct {
+	transportKey
+	clientMetrics *metrics.DefaultClientMetrics
+	mutex         *sync.Mutex
+	modTimes      map[string]time.Time
+	transport     *http.Transport
+	limiter       *limiter
+}
+
+var (
+	transportSourcesMutex = &sync.Mutex{}
+	transportSources      = make(map[transportKey]*transportSource)
+)
+
+// transportSourceFor return the transport source for the settings in the resource(can be nil) and service
+// options, clientMetrics can be nil
+func transportSourceFor(resOpts, srvOpts config.RextKeyValueStore, jobName, instanceName string, clientMetrics *metrics.DefaultClientMetrics) (ts *transportSource, err error) {
+	key := transportKey{jobName: jobName, instanceName: instanceName}
+	if key.tls, err = tlsDefFromOptions(resOpts, srvOpts); err != nil {
+		return nil, err
+	}
+	if key.http, err = transportDefFromOptions(srvOpts); err != nil {
+		return nil, err
+	}
+	var l *limiter
+	if l, err = limiterFor(srvOpts, jobName, instanceName, clientMetrics); err != nil {
+		return nil, err
+	}
+	transportSourcesMutex.Lock()
+	defer transportSourcesMutex.Unlock()
+	if ts, found := transportSources[key]; found {
+		return ts, nil
+	}
+	ts = &transportSource{transportKey: key, clientMetrics: clientMetrics, mutex: &sync.Mutex{}, limiter: l}
+	transportSources[key] = ts
+	return ts, nil
+}
+
+// resetTransportSources close the idle connections of all the transports and drop them
+func resetTransportSources() {
+	transportSourcesMutex.Lock()
+	defer transportSourcesMutex.Unlock()
+	for _, ts := range transportSources {
+		ts.mutex.Lock()
+		if ts.transport != nil {
+			ts.transport.CloseIdleConnections()
+		}
+		ts.mutex.Unlock()
+	}
+	transportSources = make(map[transportKey]*transportSource)
+}
+
+func (ts *transportSource) currentModTimes() (modTimes map[string]time.Time, err error) {
+	modTimes = make(map[string]time.Time)
+	for _, file := range ts.tls.files() {
+		var info os.FileInfo
+		if info, err = os.Stat(file); err != nil {
+			return nil, err
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (ts *transportSource) changed(modTimes map[string]time.Time) bool {
+	if ts.transport == nil {
+		return true
+	}
+	for file, modTime := range modTimes {
+		if !ts.modTimes[file].Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTransport create a transport with the tls and http settings
+func (ts *transportSource) newTransport() (transport *http.Transport, err error) {
+	const generalScopeErr = "error creating the transport"
+	transport = &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   ts.http.connectTimeout,
+			KeepAlive: ts.http.keepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   ts.http.tlsHandshakeTimeout,
+		DisableKeepAlives:     ts.http.disableKeepAlives,
+		MaxIdleConns:          ts.http.maxIdleConns,
+		MaxIdleConnsPerHost:   ts.http.maxIdleConnsPerHost,
+		MaxConnsPerHost:       ts.http.maxConnsPerHost,
+		IdleConnTimeout:       ts.http.idleConnTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+	if len(ts.http.socketPath) != 0 {
+		// NOTE(denisacostaq@gmail.com): the address in the url is ignored, all the connections go to the socket
+		dialer := &net.Dialer{Timeout: ts.http.connectTimeout}
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", ts.http.socketPath)
+		}
+		transport.Proxy = nil
+		transport.ForceAttemptHTTP2 = false
+	} else if len(ts.http.proxyURL) != 0 {
+		var proxyURL *url.URL
+		if proxyURL, err = url.Parse(ts.http.proxyURL); err != nil {
+			errCause := fmt.Sprintln("can not parse the proxy url: ", err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if transport.TLSClientConfig, err = ts.tls.tlsConfig(); err != nil {
+		errCause := fmt.Sprintln("can not load the tls config: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return transport, nil
+}
+
+// roundTripper return the transport for the service, it is created again if some certificate file changed
+func (ts *transportSource) roundTripper() (rt http.RoundTripper, err error) {
+	const generalScopeErr = "error getting the transport"
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	var modTimes map[string]time.Time
+	if modTimes, err = ts.currentModTimes(); err != nil {
+		errCause := fmt.Sprintln("can not check the certificate files: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if !ts.changed(modTimes) {
+		return ts.transport, nil
+	}
+	var transport *http.Transport
+	if transport, err = ts.newTransport(); err != nil {
+		errCause := fmt.Sprintln("can not create the transport: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if ts.transport != nil {
+		log.WithField("files", ts.tls.files()).Infoln("certificate files changed, reloading tls config")
+		ts.transport.CloseIdleConnections()
+	}
+	ts.transport = transport
+	ts.modTimes = modTimes
+	return ts.transport, nil
+}
+
+// tracedRoundTripper count the new and reused connections
+type tracedRoundTripper struct {
+	base          http.RoundTripper
+	clientMetrics *metrics.DefaultClientMetrics
+	jobName       string
+	instanceName  string
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (rt tracedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			labels := []string{rt.jobName, rt.instanceName, strconv.FormatBool(info.Reused)}
+			rt.clientMetrics.DataSourceConnections.WithLabelValues(labels...).Inc()
+		},
+	}
+	return rt.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
+
+// newHTTPClient create an http client using the transport in ts(can be nil)
+func newHTTPClient(ts *transportSource) (httpClient *http.Client, err error) {
+	if ts == nil {
+		return &http.Client{Timeout: defaultTransportDef.timeout}, nil
+	}
+	var rt http.RoundTripper
+	if rt, err = ts.roundTripper(); err != nil {
+		return nil, err
+	}
+	if ts.clientMetrics != nil {
+		rt = tracedRoundTripper{
+			base:          rt,
+			clientMetrics: ts.clientMetrics,
+			jobName:       ts.jobName,
+			instanceName:  ts.instanceName,
+		}
+	}
+	if ts.limiter != nil && ts.limiter.enabled() {
+		rt = limitedRoundTripper{base: rt, limiter: ts.limiter}
+	}
+	return &http.Client{Transport: rt, Timeout: ts.http.timeout}, nil
+}
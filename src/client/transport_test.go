@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/simelo/rextporter/src/util/metrics"
+	"github.com/stretchr/testify/suite"
+)
+
+type transportSuit struct {
+	suite.Suite
+	testServer    *httptest.Server
+	clientMetrics *metrics.DefaultClientMetrics
+}
+
+func (suite *transportSuit) SetupTest() {
+	// NOTE(denisacostaq@gmail.com): each test start with a new connections pool
+	transportSourcesMutex.Lock()
+	transportSources = make(map[transportKey]*transportSource)
+	transportSourcesMutex.Unlock()
+	suite.clientMetrics = metrics.NewDefaultClientMetrics()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v1/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	})
+	suite.testServer = httptest.NewServer(mux)
+}
+
+func (suite *transportSuit) TearDownTest() {
+	suite.testServer.Close()
+}
+
+func TestTransportSuit(t *testing.T) {
+	suite.Run(t, new(transportSuit))
+}
+
+func (suite *transportSuit) service(jobName string, httpOpts map[string]interface{}) clientFixture {
+	return clientFixture{
+		location:     suite.testServer.URL,
+		protocol:     "http",
+		jobName:      jobName,
+		instanceName: "localhost:6420",
+		srvOpts:      httpOpts,
+	}
+}
+
+func (suite *transportSuit) getData(fixture clientFixture, resPath string) ([]byte, error) {
+	return suite.getDataWithContext(context.Background(), fixture, resPath)
+}
+
+func (suite *transportSuit) getDataWithContext(ctx context.Context, fixture clientFixture, resPath string) ([]byte, error) {
+	fixture.resType = config.ResourceTypeRestAPI
+	fixture.resPath = resPath
+	fixture.resOpts = map[string]interface{}{config.OptKeyRextResourceDefHTTPMethod: "GET"}
+	srv, res, desc := fixture.build(suite.T())
+	cf, err := CreateAPIRestCreator(res, srv, desc, suite.clientMetrics)
+	suite.Require().Nil(err)
+	cl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+	return cl.GetData(ctx, make(chan prometheus.Metric, 10))
+}
+
+func (suite *transportSuit) connections(jobName, reused string) float64 {
+	var metric io_prometheus_client.Metric
+	counter := suite.clientMetrics.DataSourceConnections.WithLabelValues(jobName, "localhost:6420", reused)
+	suite.Require().Nil(counter.Write(&metric))
+	return metric.GetCounter().GetValue()
+}
+
+func (suite *transportSuit) TestTimeout() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srv := suite.service("timeout", map[string]interface{}{
+		config.OptKeyRextServiceDefHTTPTimeout: 50 * time.Millisecond,
+	})
+
+	// NOTE(denisacostaq@gmail.com): When
+	startTime := time.Now()
+	_, err := suite.getData(srv, "/api/v1/slow")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.True(time.Since(startTime) < 200*time.Millisecond)
+}
+
+func (suite *transportSuit) TestScrapeDeadline() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srv := suite.service("deadline", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// NOTE(denisacostaq@gmail.com): When
+	startTime := time.Now()
+	_, err := suite.getDataWithContext(ctx, srv, "/api/v1/slow")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Equal(context.DeadlineExceeded, ctx.Err())
+	suite.True(time.Since(startTime) < 200*time.Millisecond)
+}
+
+func (suite *transportSuit) TestConnectionsAreReusedAcrossResources() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srv := suite.service("reuse", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err1 := suite.getData(srv, "/api/v1/health")
+	_, err2 := suite.getData(srv, "/api/v1/slow")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.Nil(err2)
+	suite.Equal(float64(1), suite.connections("reuse", "false"))
+	suite.Equal(float64(1), suite.connections("reuse", "true"))
+}
+
+func (suite *transportSuit) TestResetSharedStateCloseConnections() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srv := suite.service("reset", nil)
+	_, err1 := suite.getData(srv, "/api/v1/health")
+
+	// NOTE(denisacostaq@gmail.com): When
+	ResetSharedState()
+	_, err2 := suite.getData(srv, "/api/v1/health")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.Nil(err2)
+	suite.Equal(float64(2), suite.connections("reset", "false"))
+	suite.Equal(float64(0), suite.connections("reset", "true"))
+}
+
+func (suite *transportSuit) TestDisableKeepAlives() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srv := suite.service("no_reuse", map[string]interface{}{
+		config.OptKeyRextServiceDefHTTPDisableKeepAlives: true,
+	})
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err1 := suite.getData(srv, "/api/v1/health")
+	_, err2 := suite.getData(srv, "/api/v1/health")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err1)
+	suite.Nil(err2)
+	suite.Equal(float64(2), suite.connections("no_reuse", "false"))
+	suite.Equal(float64(0), suite.connections("no_reuse", "true"))
+}
+
+func (suite *transportSuit) TestProxyURL() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+		w.Write([]byte(`{"proxied": true}`))
+	}))
+	defer proxy.Close()
+	srv := suite.service("proxy", map[string]interface{}{
+		config.OptKeyRextServiceDefHTTPProxyURL: proxy.URL,
+	})
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(srv, "/api/v1/health")
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{"proxied": true}`, string(data))
+	target, err := url.Parse(suite.testServer.URL + "/api/v1/health")
+	suite.Nil(err)
+	suite.Equal(target.String(), proxiedURL)
+}
+
+func (suite *transportSuit) TestUnixSocket() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	dir, err := ioutil.TempDir("", "rextporter")
+	suite.Require().Nil(err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "node.sock")
+	listener, err := net.Listen("unix", socketPath)
+	suite.Require().Nil(err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/csrf", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"csrf_token": "tk1"}`))
+	})
+	mux.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-CSRF-Token") != "tk1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"socket": true}`))
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("up 1\n"))
+	})
+	unixServer := httptest.NewUnstartedServer(mux)
+	unixServer.Listener = listener
+	unixServer.Start()
+	defer unixServer.Close()
+	auth := memconfig.NewHTTPAuth(config.AuthTypeCSRF, "", memconfig.NewOptionsMap())
+	authOpts := map[string]string{
+		config.OptKeyRextAuthDefTokenHeaderKey:       "X-CSRF-Token",
+		config.OptKeyRextAuthDefTokenKeyFromEndpoint: "/csrf_token",
+		config.OptKeyRextAuthDefTokenGenEndpoint:     "/csrf",
+	}
+	for k, v := range authOpts {
+		_, err = auth.GetOptions().SetString(k, v)
+		suite.Nil(err)
+	}
+	fixture := clientFixture{
+		location:     "http://localhost",
+		protocol:     config.ProtocolHTTPUnix,
+		auth:         auth,
+		jobName:      "unix",
+		instanceName: socketPath,
+		srvOpts:      map[string]interface{}{config.OptKeyRextServiceDefSocketPath: socketPath},
+		resType:      "metrics_fordwader",
+		resPath:      "/metrics",
+	}
+	srv, fRes, _ := fixture.build(suite.T())
+	cf, err := CreateProxyMetricClientCreator(fRes, srv, metrics.NewDefaultFordwaderMetrics(), suite.clientMetrics)
+	suite.Require().Nil(err)
+	fCl, err := cf.CreateClient()
+	suite.Require().Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	data, err := suite.getData(fixture, "/api/v1/health")
+	fData, fErr := fCl.GetData(context.Background())
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(`{"socket": true}`, string(data))
+	suite.Nil(fErr)
+	suite.Equal("up 1\n", string(fData))
+}
@@ -0,0 +1,263 @@
+package client
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/simelo/rextporter/src/util"
+)
+
+// websocketGUID is used to compute the Sec-WebSocket-Accept header, see RFC 6455 section 1.3
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// websocketMaxMessageSize is the maximum size for a message, the connection is closed for bigger ones
+const websocketMaxMessageSize = 10 * 1024 * 1024
+
+const (
+	websocketOpContinuation = 0x0
+	websocketOpText         = 0x1
+	websocketOpBinary       = 0x2
+	websocketOpClose        = 0x8
+	websocketOpPing         = 0x9
+	websocketOpPong         = 0xA
+)
+
+// websocketMaxControlPayload is the maximum payload size for the control frames
+const websocketMaxControlPayload = 125
+
+const (
+	websocketStatusNormalClosure = 1000
+	websocketStatusProtocolError = 1002
+)
+
+// errWebsocketClosed is returned when the server close the connection
+var errWebsocketClosed = errors.New("websocket closed by the server")
+
+// websocketProtocolError is returned for a frame not following RFC 6455, the connection is closed after it
+type websocketProtocolError string
+
+func (err websocketProtocolError) Error() string {
+	return "websocket protocol error: " + string(err)
+}
+
+// websocketConn is a client WebSocket connection, only one goroutine should read from it
+type websocketConn struct {
+	rwc        io.ReadWriteCloser
+	reader     *bufio.Reader
+	writeMutex *sync.Mutex
+	closeOnce  *sync.Once
+	closeErr   error
+}
+
+// websocketAccept return the expected Sec-WebSocket-Accept for key
+func websocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// dialWebsocket make the opening handshake with req(a GET to an http or https url) using httpClient
+func dialWebsocket(httpClient *http.Client, req *http.Request) (conn *websocketConn, err error) {
+	const generalScopeErr = "error opening a websocket"
+	nonce := make([]byte, 16)
+	if _, err = rand.Read(nonce); err != nil {
+		errCause := fmt.Sprintln("can not create the key: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	var resp *http.Response
+	if resp, err = httpClient.Do(req); err != nil {
+		errCause := fmt.Sprintln("can not do the request: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		errCause := fmt.Sprintf("no switching protocols response, status %s", resp.Status)
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	rwc, isRWC := resp.Body.(io.ReadWriteCloser)
+	if !isRWC {
+		resp.Body.Close()
+		return nil, util.ErrorFromThisScope("the connection can not be written", generalScopeErr)
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") || resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		rwc.Close()
+		return nil, util.ErrorFromThisScope("invalid handshake response", generalScopeErr)
+	}
+	return newWebsocketConn(rwc, bufio.NewReader(rwc)), nil
+}
+
+// newWebsocketConn create a client connection reading the frames from reader and writing them to rwc
+func newWebsocketConn(rwc io.ReadWriteCloser, reader *bufio.Reader) *websocketConn {
+	return &websocketConn{rwc: rwc, reader: reader, writeMutex: &sync.Mutex{}, closeOnce: &sync.Once{}}
+}
+
+// writeFrame send a single masked frame
+func (conn *websocketConn) writeFrame(opcode byte, payload []byte) (err error) {
+	frame := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+	mask := make([]byte, 4)
+	if _, err = rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for idx, b := range payload {
+		frame = append(frame, b^mask[idx%4])
+	}
+	conn.writeMutex.Lock()
+	defer conn.writeMutex.Unlock()
+	_, err = conn.rwc.Write(frame)
+	return err
+}
+
+// WriteMessage send a text message
+func (conn *websocketConn) WriteMessage(message []byte) error {
+	return conn.writeFrame(websocketOpText, message)
+}
+
+// readFrame read a frame from the server and return its opcode, fin bit and payload, a websocketProtocolError is
+// returned for the masked frames or the ones using an extension because the server should not send them
+func (conn *websocketConn) readFrame() (opcode byte, fin bool, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(conn.reader, header); err != nil {
+		return 0, false, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	if header[0]&0x70 != 0 {
+		return 0, false, nil, websocketProtocolError("reserved bits set without a negotiated extension")
+	}
+	if header[1]&0x80 != 0 {
+		return 0, false, nil, websocketProtocolError("masked frame from the server")
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err = io.ReadFull(conn.reader, ext); err != nil {
+			return 0, false, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err = io.ReadFull(conn.reader, ext); err != nil {
+			return 0, false, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if opcode >= websocketOpClose && (!fin || length > websocketMaxControlPayload) {
+		return 0, false, nil, websocketProtocolError("fragmented or too long control frame")
+	}
+	if length > websocketMaxMessageSize {
+		return 0, false, nil, fmt.Errorf("frame of %d bytes is bigger than %d", length, websocketMaxMessageSize)
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(conn.reader, payload); err != nil {
+		return 0, false, nil, err
+	}
+	return opcode, fin, payload, nil
+}
+
+// ReadMessage return the next text or binary message, the control frames are answered while waiting for it. The
+// connection is closed after a websocketProtocolError.
+func (conn *websocketConn) ReadMessage() (message []byte, err error) {
+	fragmented := false
+	for {
+		var opcode byte
+		var fin bool
+		var payload []byte
+		if opcode, fin, payload, err = conn.readFrame(); err != nil {
+			if protocolErr, isProtocolErr := err.(websocketProtocolError); isProtocolErr {
+				return nil, conn.fail(protocolErr)
+			}
+			return nil, err
+		}
+		switch opcode {
+		case websocketOpPing:
+			if err = conn.writeFrame(websocketOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case websocketOpPong:
+			continue
+		case websocketOpClose:
+			if err = conn.close(payload); err != nil {
+				return nil, fmt.Errorf("%s, can not answer it: %s", errWebsocketClosed.Error(), err.Error())
+			}
+			return nil, errWebsocketClosed
+		case websocketOpText, websocketOpBinary:
+			if fragmented {
+				return nil, conn.fail(websocketProtocolError("new message before the end of a fragmented one"))
+			}
+			message = payload
+		case websocketOpContinuation:
+			if !fragmented {
+				return nil, conn.fail(websocketProtocolError("continuation frame without a fragmented message"))
+			}
+			message = append(message, payload...)
+		default:
+			return nil, conn.fail(websocketProtocolError(fmt.Sprintf("unknown opcode %d", opcode)))
+		}
+		if len(message) > websocketMaxMessageSize {
+			return nil, fmt.Errorf("message bigger than %d bytes", websocketMaxMessageSize)
+		}
+		if fin {
+			return message, nil
+		}
+		fragmented = true
+	}
+}
+
+// fail close the connection with a protocol error status and return protocolErr, or an error with both causes if
+// the connection can not be closed
+func (conn *websocketConn) fail(protocolErr websocketProtocolError) error {
+	if err := conn.close(websocketClosePayload(websocketStatusProtocolError)); err != nil {
+		return fmt.Errorf("%s, can not close the connection: %s", protocolErr.Error(), err.Error())
+	}
+	return protocolErr
+}
+
+// websocketClosePayload return the payload of a close frame with status
+func websocketClosePayload(status uint16) []byte {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, status)
+	return payload
+}
+
+// close send a close frame with payload and close the connection, only the first call does it and the next ones
+// return the same error
+func (conn *websocketConn) close(payload []byte) error {
+	conn.closeOnce.Do(func() {
+		errWrite := conn.writeFrame(websocketOpClose, payload)
+		if conn.closeErr = conn.rwc.Close(); conn.closeErr == nil {
+			conn.closeErr = errWrite
+		}
+	})
+	return conn.closeErr
+}
+
+// Close send a normal closure frame and close the connection
+func (conn *websocketConn) Close() error {
+	return conn.close(websocketClosePayload(websocketStatusNormalClosure))
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"bufio"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// clientFrame is a frame received by the fake server
+type clientFrame struct {
+	opcode  byte
+	payload []byte
+}
+
+// websocketPipe return a client connection to a fake server writing frames, the frames received by the fake server
+// are sent to received, it is closed when the client close the connection
+func websocketPipe(frames ...[]byte) (conn *websocketConn, received chan clientFrame) {
+	clientEnd, serverEnd := net.Pipe()
+	received = make(chan clientFrame, 10)
+	go func() {
+		for _, frame := range frames {
+			if _, err := serverEnd.Write(frame); err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer close(received)
+		reader := bufio.NewReader(serverEnd)
+		for {
+			opcode, payload, err := readClientFrame(reader)
+			if err != nil {
+				return
+			}
+			received <- clientFrame{opcode: opcode, payload: payload}
+		}
+	}()
+	return newWebsocketConn(clientEnd, bufio.NewReader(clientEnd)), received
+}
+
+func TestWebsocketFragmentedMessage(t *testing.T) {
+	// NOTE(denisacostaq@gmail.com): Giving
+	conn, received := websocketPipe(
+		[]byte{websocketOpText, 1, 'a'},
+		[]byte{0x80 | websocketOpPing, 1, 'p'},
+		[]byte{0x80 | websocketOpContinuation, 1, 'b'},
+	)
+
+	// NOTE(denisacostaq@gmail.com): When
+	message, err := conn.ReadMessage()
+	errClose := conn.Close()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	require.Nil(t, err)
+	require.Equal(t, "ab", string(message))
+	require.Nil(t, errClose)
+	require.Equal(t, clientFrame{opcode: websocketOpPong, payload: []byte("p")}, <-received)
+	require.Equal(t, clientFrame{opcode: websocketOpClose, payload: websocketClosePayload(websocketStatusNormalClosure)}, <-received)
+	_, open := <-received
+	require.False(t, open)
+}
+
+func TestWebsocketClosedByTheServer(t *testing.T) {
+	// NOTE(denisacostaq@gmail.com): Giving
+	conn, received := websocketPipe([]byte{0x80 | websocketOpClose, 2, 0x03, 0xE9})
+
+	// NOTE(denisacostaq@gmail.com): When
+	message, err := conn.ReadMessage()
+	errClose := conn.Close()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	require.Nil(t, message)
+	require.Equal(t, errWebsocketClosed, err)
+	require.Nil(t, errClose)
+	require.Equal(t, clientFrame{opcode: websocketOpClose, payload: []byte{0x03, 0xE9}}, <-received)
+	_, open := <-received
+	require.False(t, open)
+}
+
+func TestWebsocketProtocolErrorCloseTheConnection(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames [][]byte
+	}{
+		{
+			name:   "interleaved fragments",
+			frames: [][]byte{{websocketOpText, 1, 'a'}, {0x80 | websocketOpText, 1, 'b'}},
+		},
+		{
+			name:   "masked frame",
+			frames: [][]byte{{0x80 | websocketOpText, 0x80 | 1, 1, 2, 3, 4, 'a' ^ 1}},
+		},
+		{
+			name:   "continuation without a fragmented message",
+			frames: [][]byte{{0x80 | websocketOpContinuation, 1, 'a'}},
+		},
+		{
+			name:   "fragmented control frame",
+			frames: [][]byte{{websocketOpPing, 0}},
+		},
+		{
+			name:   "reserved bits",
+			frames: [][]byte{{0x80 | 0x40 | websocketOpText, 1, 'a'}},
+		},
+		{
+			name:   "unknown opcode",
+			frames: [][]byte{{0x80 | 0x3, 1, 'a'}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// NOTE(denisacostaq@gmail.com): Giving
+			conn, received := websocketPipe(tc.frames...)
+
+			// NOTE(denisacostaq@gmail.com): When
+			message, err := conn.ReadMessage()
+
+			// NOTE(denisacostaq@gmail.com): Assert
+			require.Nil(t, message)
+			_, isProtocolErr := err.(websocketProtocolError)
+			require.True(t, isProtocolErr, err)
+			require.Equal(t, clientFrame{opcode: websocketOpClose, payload: websocketClosePayload(websocketStatusProtocolError)}, <-received)
+			_, open := <-received
+			require.False(t, open)
+		})
+	}
+}
@@ -43,12 +43,267 @@ const (
 	OptKeyRextAuthDefTokenKeyFromEndpoint = "1cb99a48-c642-4234-af5e-7de88cb20271" // nolint gosec
 	// OptKeyRextAuthDefTokenGenEndpoint key to define a token endpoint to get authenticated inside a RextAuthDef
 	OptKeyRextAuthDefTokenGenEndpoint = "3a5e1d2f-53c0-4c47-b0cb-13a3190ce97f" // nolint gosec
+	// OptKeyRextAuthDefTokenGenHTTPMethod key to define the http method used to request the token endpoint
+	// inside a RextAuthDef, GET if not present
+	OptKeyRextAuthDefTokenGenHTTPMethod = "25ad80ac-11b3-416a-872f-9fab4795147b" // nolint gosec
+	// OptKeyRextAuthDefTokenGenBody key to define a body template to be sent to the token endpoint inside a RextAuthDef
+	OptKeyRextAuthDefTokenGenBody = "3bc5d23a-dc86-4abe-9c23-4d25311b5e0d" // nolint gosec
+	// OptKeyRextAuthDefTokenGenHeaders key to define extra headers(a map[string]string) to be sent to the token
+	// endpoint inside a RextAuthDef
+	OptKeyRextAuthDefTokenGenHeaders = "fc5b7026-7af2-4991-ba02-f23f18aa31e8" // nolint gosec
+	// OptKeyRextAuthDefTokenSource key to define where the token should be read from in the token endpoint
+	// response inside a RextAuthDef, one of AuthTokenSourceJSON(default), AuthTokenSourceHeader or AuthTokenSourceCookie
+	OptKeyRextAuthDefTokenSource = "f7c1bb01-9580-4b81-9095-7f668be2f693" // nolint gosec
+	// OptKeyRextAuthDefTokenInjectAs key to define how the token should be sent in the requests inside a RextAuthDef,
+	// one of AuthTokenInjectAsHeader(default), AuthTokenInjectAsQuery or AuthTokenInjectAsCookie
+	OptKeyRextAuthDefTokenInjectAs = "b718e13f-8c33-4a0c-aff7-3e250d61e03e" // nolint gosec
+	// OptKeyRextAuthDefLoginEndpoint key to define the login endpoint inside a RextAuthDef of SessionLogin kind
+	OptKeyRextAuthDefLoginEndpoint = "635a1ec7-c07c-480b-938c-6091ab6e3b79"
+	// OptKeyRextAuthDefLoginHTTPMethod key to define the http method used to request the login endpoint inside
+	// a RextAuthDef, POST if not present
+	OptKeyRextAuthDefLoginHTTPMethod = "f800c85e-b549-422b-ae0f-59af5da2b46a"
+	// OptKeyRextAuthDefLoginBody key to define a body template to be sent to the login endpoint inside a RextAuthDef
+	OptKeyRextAuthDefLoginBody = "9945dbab-4245-49d3-af59-4cc08f78faab"
+	// OptKeyRextAuthDefLoginHeaders key to define extra headers(a map[string]string) to be sent to the login
+	// endpoint inside a RextAuthDef
+	OptKeyRextAuthDefLoginHeaders = "e7da9fe1-11cb-4573-9c6e-bf2b60b3b724"
+	// OptKeyRextAuthDefLoginPage key to define the path of the page the service redirect to when the session
+	// expires inside a RextAuthDef, the login endpoint path if not present
+	OptKeyRextAuthDefLoginPage = "798b8555-27c0-4c56-953c-093c8d7e37ba"
+	// OptKeyRextAuthDefHMACSecret key to define the secret used to sign the requests inside a RextAuthDef of HMAC kind
+	OptKeyRextAuthDefHMACSecret = "117d4189-11c6-4e4a-980f-98993333400f" // nolint gosec
+	// OptKeyRextAuthDefHMACSigningString key to define a template for the string to be signed inside a RextAuthDef,
+	// the fields .Method, .Path, .Timestamp and .BodyHash are available
+	OptKeyRextAuthDefHMACSigningString = "865e6487-8297-4d28-8239-74f8d25f01b2"
+	// OptKeyRextAuthDefHMACSignatureHeader key to define the header name for the signature inside a RextAuthDef
+	OptKeyRextAuthDefHMACSignatureHeader = "c8f23514-1360-4d89-97b3-2645640c927f"
+	// OptKeyRextAuthDefHMACTimestampHeader key to define the header name for the timestamp inside a RextAuthDef
+	OptKeyRextAuthDefHMACTimestampHeader = "f2640f38-7bf2-4cc4-9776-5cc0deffe289"
+	// OptKeyRextAuthDefHMACBodyHashHeader key to define the header name for the body hash inside a RextAuthDef
+	OptKeyRextAuthDefHMACBodyHashHeader = "0c5dbc40-6f43-4b4f-aef8-241548996164"
 	// OptKeyRextServiceDefJobName key to define the job name, it is mandatory for all services
 	OptKeyRextServiceDefJobName = "555efe9a-fd0a-4f03-9724-fed758491e65"
 	// OptKeyRextServiceDefInstanceName key to define a instance name for a service, it is mandatory for all services
 	// a service can run in multiple nodes(physical or virtual), all these instances are mandatory, can be
 	// for example 127.0.0.0:8080
 	OptKeyRextServiceDefInstanceName = "0a12a60a-6ed4-400b-af78-2664d6588233"
+	// OptKeyRextTLSCAFile key to define a PEM file with the CA certificates to trust inside a RextServiceDef or
+	// a RextResourceDef, a value in the resource override the one in the service
+	OptKeyRextTLSCAFile = "3f43ec99-54f2-40ee-8a0f-38cf82c7f6eb"
+	// OptKeyRextTLSCertFile key to define a PEM file with the client certificate inside a RextServiceDef or a RextResourceDef
+	OptKeyRextTLSCertFile = "f00c3ec3-0229-4e3a-b4a8-2a4d2505cc05"
+	// OptKeyRextTLSKeyFile key to define a PEM file with the client key inside a RextServiceDef or a RextResourceDef
+	OptKeyRextTLSKeyFile = "19ff49e7-d3bc-4819-bc51-8a2e3a5b646e"
+	// OptKeyRextTLSServerName key to define the server name to verify(and sent as SNI) inside a RextServiceDef or
+	// a RextResourceDef
+	OptKeyRextTLSServerName = "f3486413-96c7-4f2e-bee6-98dcf21d1609"
+	// OptKeyRextTLSInsecureSkipVerify key to define(a bool) if the server certificate should not be verified
+	// inside a RextServiceDef or a RextResourceDef
+	OptKeyRextTLSInsecureSkipVerify = "c09fee5c-c276-4485-be31-857d1391f2dd"
+	// OptKeyRextTLSMinVersion key to define the minimum TLS version inside a RextServiceDef or a RextResourceDef, one
+	// of TLSVersion10, TLSVersion11, TLSVersion12 or TLSVersion13
+	OptKeyRextTLSMinVersion = "1f5de848-607d-4850-9fe6-77f6aabbb18f"
+	// OptKeyRextServiceDefHTTPTimeout key to define(a time.Duration) the overall time limit for a request, including
+	// the connection and the response body read inside a RextServiceDef
+	OptKeyRextServiceDefHTTPTimeout = "bcda3970-a3e5-40bc-8aed-2db148d0cdca"
+	// OptKeyRextServiceDefHTTPConnectTimeout key to define(a time.Duration) the time limit to establish a connection
+	// inside a RextServiceDef
+	OptKeyRextServiceDefHTTPConnectTimeout = "b6ae736a-f569-411f-9702-fc57e124a56b"
+	// OptKeyRextServiceDefHTTPTLSHandshakeTimeout key to define(a time.Duration) the time limit for the TLS
+	// handshake inside a RextServiceDef
+	OptKeyRextServiceDefHTTPTLSHandshakeTimeout = "be8b8fa1-1d25-493e-903d-c0a93178c7de"
+	// OptKeyRextServiceDefHTTPKeepAlive key to define(a time.Duration) the keep-alive period for the connections
+	// inside a RextServiceDef
+	OptKeyRextServiceDefHTTPKeepAlive = "6c83c8be-1e9d-4853-8e93-8f5f513bb899"
+	// OptKeyRextServiceDefHTTPDisableKeepAlives key to define(a bool) if the connections should not be reused inside
+	// a RextServiceDef
+	OptKeyRextServiceDefHTTPDisableKeepAlives = "81f193bb-676c-4df0-87fc-c8b3fa0dcb73"
+	// OptKeyRextServiceDefHTTPMaxIdleConns key to define(an int) the maximum number of idle connections inside
+	// a RextServiceDef
+	OptKeyRextServiceDefHTTPMaxIdleConns = "b959d76e-4100-48bc-8742-9bde993f5ef1"
+	// OptKeyRextServiceDefHTTPMaxIdleConnsPerHost key to define(an int) the maximum number of idle connections per
+	// host inside a RextServiceDef
+	OptKeyRextServiceDefHTTPMaxIdleConnsPerHost = "5fcd74ab-4d65-4ade-b118-0d3b08a4b3e4"
+	// OptKeyRextServiceDefHTTPMaxConnsPerHost key to define(an int) the maximum number of connections per host
+	// inside a RextServiceDef, zero means no limit
+	OptKeyRextServiceDefHTTPMaxConnsPerHost = "344e9c30-52d2-4556-877f-1ff304836bb4"
+	// OptKeyRextServiceDefHTTPIdleConnTimeout key to define(a time.Duration) how long an idle connection is kept
+	// inside a RextServiceDef
+	OptKeyRextServiceDefHTTPIdleConnTimeout = "7bf5b727-48b1-4abd-ab64-b3572dfcf778"
+	// OptKeyRextServiceDefHTTPProxyURL key to define the proxy url inside a RextServiceDef, the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables are used if not present
+	OptKeyRextServiceDefHTTPProxyURL = "12309700-9d42-4b86-a6e9-1a593d080a2f"
+	// OptKeyRextResourceDefFileMaxSize key to define(an int) the maximum size in bytes for a file data source
+	// inside a RextServiceDef or a RextResourceDef
+	OptKeyRextResourceDefFileMaxSize = "8c5712e2-dfb5-41d3-9ff7-54afd401be78"
+	// OptKeyRextResourceDefExecArgs key to define(an []string) the arguments for the command inside a RextResourceDef
+	// of exec type
+	OptKeyRextResourceDefExecArgs = "4a7cc4cf-cce6-4809-a776-d6a3a949245d"
+	// OptKeyRextResourceDefExecEnv key to define(an []string) extra environment variables like KEY=VALUE for the
+	// command inside a RextResourceDef of exec type
+	OptKeyRextResourceDefExecEnv = "6e64abdf-f6ef-4d0a-8c4e-5c4a60c238ac"
+	// OptKeyRextResourceDefExecDir key to define the working directory for the command inside a RextResourceDef of
+	// exec type
+	OptKeyRextResourceDefExecDir = "a8b52b86-9524-45d9-ac3c-a4444f09e43a"
+	// OptKeyRextResourceDefExecTimeout key to define(a time.Duration) the time limit for the command inside a
+	// RextResourceDef of exec type
+	OptKeyRextResourceDefExecTimeout = "9a0f033e-2e41-4a19-ad9f-35ecd08357af"
+	// OptKeyRextResourceDefExecMaxConcurrent key to define(an int) the maximum number of concurrent runs of the
+	// command inside a RextResourceDef of exec type
+	OptKeyRextResourceDefExecMaxConcurrent = "3c2fb3b0-5b98-4825-b5cd-f4fac942ba82"
+	// OptKeyRextResourceDefJSONRPCMethod key to define the method to call inside a RextResourceDef of json_rpc type
+	OptKeyRextResourceDefJSONRPCMethod = "33949b1d-f780-423d-936b-3742a5614490"
+	// OptKeyRextResourceDefJSONRPCParams key to define the params(a json array or object) for the method inside a
+	// RextResourceDef of json_rpc type
+	OptKeyRextResourceDefJSONRPCParams = "72fa90de-5e20-43d6-ac02-da878836e66e"
+	// OptKeyRextResourceDefJSONRPCBatch key to define(a bool) if the call can be sent in a batch with the other
+	// calls to the same endpoint inside a RextResourceDef of json_rpc type
+	OptKeyRextResourceDefJSONRPCBatch = "8e6d351d-dc55-4cea-97ce-ace072913d31"
+	// OptKeyRextResourceDefGraphQLQuery key to define the query document inside a RextResourceDef of graphql type
+	OptKeyRextResourceDefGraphQLQuery = "6d4cc987-66e2-4093-94e3-c8092f702e5d"
+	// OptKeyRextResourceDefGraphQLVariables key to define the variables(a json object) for the query inside a
+	// RextResourceDef of graphql type
+	OptKeyRextResourceDefGraphQLVariables = "db2c63e3-9e61-48be-bdd7-ad9f90383b83"
+	// OptKeyRextResourceDefGraphQLOperationName key to define the operation to run when the query document have
+	// more than one inside a RextResourceDef of graphql type
+	OptKeyRextResourceDefGraphQLOperationName = "7e26dd4d-17c2-4d7b-9658-0e2564ec767b"
+	// OptKeyRextResourceDefPaginationStrategy key to define how to request the next pages inside a RextResourceDef,
+	// one of PaginationPage, PaginationCursor or PaginationLink
+	OptKeyRextResourceDefPaginationStrategy = "8f0dc3ff-fe49-494f-b0f4-61d537f6da41"
+	// OptKeyRextResourceDefPaginationItemsPath key to define the path to the array of items in each page inside a
+	// RextResourceDef, the page itself is the array if empty
+	OptKeyRextResourceDefPaginationItemsPath = "a5a91348-a9a5-4261-9da4-b5b8e872099a"
+	// OptKeyRextResourceDefPaginationPageParam key to define the query param for the page number inside a
+	// RextResourceDef with the PaginationPage strategy
+	OptKeyRextResourceDefPaginationPageParam = "044b0978-f5d6-4c53-9c0a-5519ddd64e61"
+	// OptKeyRextResourceDefPaginationLimitParam key to define the query param for the page size inside a
+	// RextResourceDef with the PaginationPage strategy
+	OptKeyRextResourceDefPaginationLimitParam = "7d6e43b3-026e-491f-bc09-5371fa4aee08"
+	// OptKeyRextResourceDefPaginationPageSize key to define(an int) the page size inside a RextResourceDef with the
+	// PaginationPage strategy, zero means the server default
+	OptKeyRextResourceDefPaginationPageSize = "e40aa99d-b5bc-440e-8d2c-c4c97fe9fc55"
+	// OptKeyRextResourceDefPaginationFirstPage key to define(an int) the number of the first page inside a
+	// RextResourceDef with the PaginationPage strategy
+	OptKeyRextResourceDefPaginationFirstPage = "4c68c097-afd8-4708-8351-0b52105d126c"
+	// OptKeyRextResourceDefPaginationCursorPath key to define the path to the next cursor in each page inside a
+	// RextResourceDef with the PaginationCursor strategy
+	OptKeyRextResourceDefPaginationCursorPath = "a1f1018b-439b-4f4d-8dd7-5644082f5006"
+	// OptKeyRextResourceDefPaginationCursorParam key to define the query param for the cursor inside a
+	// RextResourceDef with the PaginationCursor strategy
+	OptKeyRextResourceDefPaginationCursorParam = "5266a68f-8ad0-4f5a-a23c-765627f689d6"
+	// OptKeyRextResourceDefPaginationMaxPages key to define(an int) the maximum number of pages to request inside a
+	// RextResourceDef
+	OptKeyRextResourceDefPaginationMaxPages = "041d6331-4851-46bf-980c-afb9f094722d"
+	// OptKeyRextResourceDefStreamKind key to define the kind of subscription inside a RextResourceDef of stream type,
+	// one of StreamWebSocket or StreamSSE
+	OptKeyRextResourceDefStreamKind = "19f4fb3c-6d9c-41c2-a47b-b80109363d73"
+	// OptKeyRextResourceDefStreamSubscribe key to define the message to send after connect inside a RextResourceDef of
+	// stream type with the StreamWebSocket kind
+	OptKeyRextResourceDefStreamSubscribe = "f2f9f835-a17d-43c7-9c9e-4f2b4930f856"
+	// OptKeyRextResourceDefStreamWindow key to define(an int) how many of the latest messages are kept inside a
+	// RextResourceDef of stream type
+	OptKeyRextResourceDefStreamWindow = "68be8925-5276-4fb1-a962-0c396ad3f8d0"
+	// OptKeyRextResourceDefStreamReconnectInitialBackoff key to define(a time.Duration) the wait before the first
+	// reconnection inside a RextResourceDef of stream type, it is doubled for each next one
+	OptKeyRextResourceDefStreamReconnectInitialBackoff = "60c3a081-0592-4449-b687-2efcf5879251"
+	// OptKeyRextResourceDefStreamReconnectMaxBackoff key to define(a time.Duration) the maximum wait between
+	// reconnections inside a RextResourceDef of stream type
+	OptKeyRextResourceDefStreamReconnectMaxBackoff = "9c2d24e0-1db3-43b7-acdc-a3b67540bcb7"
+	// OptKeyRextResourceDefStreamWithCount key to define(a bool) if the data of a RextResourceDef of stream type
+	// include the number of messages received in a count node next to the latest ones
+	OptKeyRextResourceDefStreamWithCount = "c6ec4a26-3658-4181-900c-124910d11489"
+	// OptKeyRextResourceDefTCPCommand key to define the command to send after connect inside a RextResourceDef of tcp
+	// type
+	OptKeyRextResourceDefTCPCommand = "88db9560-105d-490c-bb85-ff664beb52ae"
+	// OptKeyRextResourceDefTCPTerminator key to define the text that end the response inside a RextResourceDef of tcp
+	// type, the response end when the connection is closed or the timeout elapse if not present
+	OptKeyRextResourceDefTCPTerminator = "a1b231e1-455d-4eb6-9fc0-b7f0e0e7a914"
+	// OptKeyRextResourceDefTCPTimeout key to define(a time.Duration) the time limit to connect and read the response
+	// inside a RextResourceDef of tcp type
+	OptKeyRextResourceDefTCPTimeout = "4475e20b-e0ab-471a-842b-6643d8f6e0f6"
+	// OptKeyRextResourceDefTCPMaxSize key to define(an int) the maximum size in bytes for the response inside a
+	// RextResourceDef of tcp type
+	OptKeyRextResourceDefTCPMaxSize = "10b66832-ef0c-4001-8fa6-2e444583faad"
+	// OptKeyRextDecoderTextSeparator key to define the separator between the key and the value in each line inside a
+	// RextDecoderDef of DecoderText type, the first ':' or white space if not present
+	OptKeyRextDecoderTextSeparator = "cc092a54-2a07-4cec-8950-ebef40d5f0ca"
+	// OptKeyRextDecoderTextLinePrefix key to define a prefix to remove from each line(like "STAT ") inside a
+	// RextDecoderDef of DecoderText type
+	OptKeyRextDecoderTextLinePrefix = "5c84e399-7e94-4b17-972f-ddd47bd89dd6"
+	// OptKeyRextDecoderCSVDelimiter key to define the field delimiter(a single character) inside a RextDecoderDef
+	// of DecoderCSV or DecoderTSV type, ',' and '\t' by default
+	OptKeyRextDecoderCSVDelimiter = "73a3aee0-138f-4359-a861-73d68600952a"
+	// OptKeyRextDecoderCSVCommentPrefix key to define a prefix for the lines to ignore(like "#") inside a
+	// RextDecoderDef of DecoderCSV or DecoderTSV type
+	OptKeyRextDecoderCSVCommentPrefix = "68105b4c-b212-4099-875c-e102f7282755"
+	// OptKeyRextDecoderCSVHeader key to define if the first row is the header(see CSVHeaderAuto) inside a
+	// RextDecoderDef of DecoderCSV or DecoderTSV type
+	OptKeyRextDecoderCSVHeader = "ab7aa498-5e7b-40d7-89a8-758ac60ee62e"
+	// OptKeyRextNodeSolverRegexGroup key to define the capture group with the node value inside a RextNodeSolver
+	// of regex type, the group named value, the first one or the whole match are used if not present
+	OptKeyRextNodeSolverRegexGroup = "40bef201-9833-45ba-ba6c-4598010071a0"
+	// OptKeyRextResourceDefSQLQuery key to define the query to run inside a RextResourceDef of sql type
+	OptKeyRextResourceDefSQLQuery = "f963c119-4619-4171-9df2-9341b2addc38"
+	// OptKeyRextResourceDefSQLTimeout key to define(a time.Duration) the time limit for the query inside a
+	// RextResourceDef of sql type
+	OptKeyRextResourceDefSQLTimeout = "00bc3791-b107-41b9-87e3-393b66c70905"
+	// OptKeyRextResourceDefSQLValueColumns key to define(an []string) the columns decoded as numbers inside a
+	// RextResourceDef of sql type, the other ones are decoded as strings to be used as labels
+	OptKeyRextResourceDefSQLValueColumns = "29709b0f-1d70-4a7d-b3fe-aa1b964c3515"
+	// OptKeyRextServiceDefSQLDriver key to define the database/sql driver name inside a RextServiceDef with the
+	// ProtocolSQL protocol
+	OptKeyRextServiceDefSQLDriver = "aa914537-89c2-45b5-83e6-cb0605193c68"
+	// OptKeyRextServiceDefSQLDSNFile key to define a file with the data source name inside a RextServiceDef with the
+	// ProtocolSQL protocol
+	OptKeyRextServiceDefSQLDSNFile = "bce0c7f0-3a64-437d-b9f5-c28fdb0076fe"
+	// OptKeyRextServiceDefSQLDSNEnv key to define an environment variable with the data source name inside a
+	// RextServiceDef with the ProtocolSQL protocol
+	OptKeyRextServiceDefSQLDSNEnv = "7eebf030-49ea-474f-bedf-2ccc3a2522d4"
+	// OptKeyRextServiceDefSQLMaxOpenConns key to define(an int) the maximum number of open connections inside a
+	// RextServiceDef with the ProtocolSQL protocol
+	OptKeyRextServiceDefSQLMaxOpenConns = "93eca539-86c7-4a30-8bad-1a365e9a4ce9"
+	// OptKeyRextServiceDefSQLMaxIdleConns key to define(an int) the maximum number of idle connections inside a
+	// RextServiceDef with the ProtocolSQL protocol
+	OptKeyRextServiceDefSQLMaxIdleConns = "7ce9ad6c-c54e-46aa-b2ee-8ded9d0977f7"
+	// OptKeyRextServiceDefSQLConnMaxLifetime key to define(a time.Duration) the maximum time a connection is reused
+	// inside a RextServiceDef with the ProtocolSQL protocol
+	OptKeyRextServiceDefSQLConnMaxLifetime = "9c5b80f0-05ca-44e4-84a4-b672a2cc0749"
+	// OptKeyRextServiceDefSocketPath key to define the Unix domain socket to dial inside a RextServiceDef with the
+	// ProtocolHTTPUnix protocol
+	OptKeyRextServiceDefSocketPath = "6107b7f8-28a8-42bc-a2d0-994c24be3d29"
+	// OptKeyRextServiceDefLimitRequestsPerSecond key to define(a float64) the maximum rate of requests inside a
+	// RextServiceDef, zero means no limit
+	OptKeyRextServiceDefLimitRequestsPerSecond = "1e68e1b0-84f5-4550-ab9a-839fc53cf29d"
+	// OptKeyRextServiceDefLimitBurst key to define(an int) how many requests can be made at once over the rate
+	// inside a RextServiceDef
+	OptKeyRextServiceDefLimitBurst = "e47c888e-5aa0-4074-90a4-bd2d7be94b95"
+	// OptKeyRextServiceDefLimitMaxConcurrentRequests key to define(an int) the maximum number of requests in flight
+	// inside a RextServiceDef, zero means no limit
+	OptKeyRextServiceDefLimitMaxConcurrentRequests = "6ce51f41-2567-4434-b921-e293464abbba"
+	// OptKeyRextRetryMaxAttempts key to define(an int) the maximum number of attempts for a request inside a
+	// RextServiceDef or a RextResourceDef, 1 means no retries
+	OptKeyRextRetryMaxAttempts = "f9bf03de-387e-4307-87b2-9c0f737e2253"
+	// OptKeyRextRetryInitialBackoff key to define(a time.Duration) the wait before the first retry inside a
+	// RextServiceDef or a RextResourceDef, it is doubled for each next retry
+	OptKeyRextRetryInitialBackoff = "0c5e8451-e58a-4c28-a422-466716337ac6"
+	// OptKeyRextRetryMaxBackoff key to define(a time.Duration) the maximum wait between retries inside a
+	// RextServiceDef or a RextResourceDef
+	OptKeyRextRetryMaxBackoff = "e22d3a1c-3744-4cd7-b0fe-a43a10580285"
+	// OptKeyRextRetryTimeBudget key to define(a time.Duration) the total time for all the attempts inside a
+	// RextServiceDef or a RextResourceDef, the scrape deadline is used if not present
+	OptKeyRextRetryTimeBudget = "e3464001-8e6c-4f91-8074-ba92cb559893"
+	// OptKeyRextRetryStatusCodes key to define(an []int) the response status codes to retry inside a RextServiceDef
+	// or a RextResourceDef
+	OptKeyRextRetryStatusCodes = "2d5e38a7-9fcc-4443-97a4-8ebe2b460e71"
+	// OptKeyRextRetryErrors key to define(an []string) the request error classes to retry inside a RextServiceDef
+	// or a RextResourceDef, each one of RetryErrorConnection, RetryErrorTimeout or RetryErrorDNS
+	OptKeyRextRetryErrors = "e70454f2-4290-4b5d-95ac-33f98d4fc567"
+	// OptKeyRextCircuitBreakerFailureThreshold key to define(an int) the consecutive failures to open the circuit
+	// for a data source inside a RextServiceDef or a RextResourceDef, 0 disable the circuit breaker
+	OptKeyRextCircuitBreakerFailureThreshold = "4ce99385-69f6-4dc5-bfcf-a9ef26b917e4"
+	// OptKeyRextCircuitBreakerCoolDown key to define(a time.Duration) how long the circuit stay open before a probe
+	// request is allowed inside a RextServiceDef or a RextResourceDef
+	OptKeyRextCircuitBreakerCoolDown = "09929675-e719-4c49-b3a8-71802bf55998"
 	// OptKeyRextMetricDefHMetricBuckets key to hold the configured buckets inside a RextMetricDef if you are using
 	// a histogram kind
 	OptKeyRextMetricDefHMetricBuckets = "9983807d-13fe-4b1d-9363-4b844ea2f301"
@@ -131,7 +386,7 @@ type RextResourceDef interface {
 // RextDecoderDef allow you to decode a resource from different formats
 type RextDecoderDef interface {
 	// GetType return some kind of "encoding" like: json, xml, ini, plain_text, prometheus_exposed_metrics,
-	// .rar(even encrypted)
+	// .rar(even encrypted), the decoders are registered by type with RegisterDecoder
 	GetType() string
 
 	// GetOptions return additional options for example if the retrieved content is encripted, get info
@@ -144,6 +399,20 @@ type RextDecoderDef interface {
 const (
 	// RextNodeSolverTypeJSONPath var name to use node solver of json kind
 	RextNodeSolverTypeJSONPath = "jsonPath"
+	// RextNodeSolverTypeXPath var name to use node solver of xpath kind, it requires a DecoderXML
+	RextNodeSolverTypeXPath = "xpath"
+	// RextNodeSolverTypeRegex var name to use node solver of regular expression kind, every match is a node, it
+	// requires a DecoderPlainText
+	RextNodeSolverTypeRegex = "regex"
+	// RextNodeSolverTypeLine var name to use node solver of line kind, the path is a line number or a key prefix, it
+	// requires a DecoderPlainText
+	RextNodeSolverTypeLine = "line"
+	// RextNodeSolverTypePrometheus var name to use node solver of prometheus kind, the path is a series selector like
+	// process_open_fds{job="x"}, it requires a DecoderPrometheus
+	RextNodeSolverTypePrometheus = "prometheus"
+	// RextNodeSolverTypeJQ var name to use node solver of jq kind, the path is a jq expression like
+	// .connections[] | select(.outgoing == true) | .height, it works with the json like decoders
+	RextNodeSolverTypeJQ = "jq"
 )
 
 // RextNodeSolver help you to get raw data(sample/s) to create a metric from a specific path inside a
@@ -151,16 +420,22 @@ const (
 type RextNodeSolver interface {
 	// GetType return the strategy to find the data, it can be: jpath, xpath, .ini, plain_text, .tar.gz
 	// it is different to RextDecoderDef.type in the sense of a decoder can work over a binary encoded
-	// content and after, the node solver over a .rar
+	// content and after, the node solver over a .rar. The node solvers are registered by type with
+	// RegisterNodeSolver, if empty the default one for the decoder is used
 	GetType() string
 
 	// GetNodePath return the path where you can find the value, it depends on the type, see some examples below:
 	// "json" -> "/blockchain/head/seq" | "/blockchain/head/fee"
 	// "xml" -> "/blockchain/head/seq" | "/blockchain/head/fee"
+	// "xpath" -> "//connection[@outgoing='true']/height" | "count(//connection)", the location paths, the steps and
+	// predicates listed in the scrapper.XMLParser doc only, any other expression is rejected by Validate
 	// "ini" -> "key_name"
 	// "plain_text" -> line number
 	// "directory" -> file_path
 	// ".rar" -> file_path | file_path + jpath for the specific file | file_path + key(.ini) for the specific file
+	// "jq" -> ".connections[] | select(.outgoing) | .height", the paths, slices, `|`, `,`, `//`, `if`, the
+	// arithmetic, comparison and logical operators, the array construction and the functions listed in the
+	// scrapper.JQSolver doc only, any other expression is rejected by Validate
 	GetNodePath() string
 	SetNodePath(string)
 
@@ -208,6 +483,141 @@ type RextLabelDef interface {
 // AuthTypeCSRF define a const name for auth of type CSRF
 const AuthTypeCSRF = "CSRF"
 
+// AuthTypeSessionLogin define a const name for auth of type session login, a login request set a session
+// cookie to be sent in the next requests
+const AuthTypeSessionLogin = "SessionLogin"
+
+// AuthTypeHMAC define a const name for auth of type HMAC, each request is signed with a shared secret
+const AuthTypeHMAC = "HMAC"
+
+// AuthHMACDefaultSigningString is the signing string template used if no one is configured for the HMAC auth type
+const AuthHMACDefaultSigningString = "{{.Method}}\n{{.Path}}\n{{.Timestamp}}\n{{.BodyHash}}"
+
+const (
+	// AuthTokenSourceJSON the token is located in the response body through a json path
+	AuthTokenSourceJSON = "json"
+	// AuthTokenSourceHeader the token is the value of a response header
+	AuthTokenSourceHeader = "header"
+	// AuthTokenSourceCookie the token is the value of a cookie set by the response
+	AuthTokenSourceCookie = "cookie"
+)
+
+const (
+	// AuthTokenInjectAsHeader the token is sent as a request header
+	AuthTokenInjectAsHeader = "header"
+	// AuthTokenInjectAsQuery the token is sent as a query parameter
+	AuthTokenInjectAsQuery = "query"
+	// AuthTokenInjectAsCookie the token is sent as a request cookie
+	AuthTokenInjectAsCookie = "cookie"
+)
+
+const (
+	// TLSVersion10 is the TLS 1.0 version
+	TLSVersion10 = "1.0"
+	// TLSVersion11 is the TLS 1.1 version
+	TLSVersion11 = "1.1"
+	// TLSVersion12 is the TLS 1.2 version
+	TLSVersion12 = "1.2"
+	// TLSVersion13 is the TLS 1.3 version
+	TLSVersion13 = "1.3"
+)
+
+const (
+	// ProtocolFile is the protocol for the data sources in the local filesystem, the service base path is a
+	// directory and the resource paths can be glob patterns
+	ProtocolFile = "file"
+	// ProtocolExec is the protocol for the services with exec resources only, the resource paths are the commands
+	ProtocolExec = "exec"
+	// ProtocolHTTPUnix is the protocol for the services listening in a Unix domain socket, the requests are made
+	// over http with the resource paths as usual
+	ProtocolHTTPUnix = "http+unix"
+	// ProtocolTCP is the protocol for the services with a text protocol over tcp, the location is the address like
+	// host:port
+	ProtocolTCP = "tcp"
+	// ProtocolSQL is the protocol for the databases reached through database/sql, the location is used as the
+	// instance label only
+	ProtocolSQL = "sql"
+)
+
+const (
+	// ResourceTypeRestAPI is the type for the resources requested over http, the resource path is the endpoint
+	ResourceTypeRestAPI = "rest_api"
+	// ResourceTypeFile is the type for the resources read from the filesystem, the resource path is the file path
+	ResourceTypeFile = "file"
+	// ResourceTypeExec is the type for the resources got running a command, the resource path is the command
+	ResourceTypeExec = "exec"
+	// ResourceTypeJSONRPC is the type for the resources got calling a json-rpc method
+	ResourceTypeJSONRPC = "json_rpc"
+	// ResourceTypeGraphQL is the type for the resources got running a graphql query
+	ResourceTypeGraphQL = "graphql"
+	// ResourceTypeStream is the type for the resources got from the last message of a websocket or server-sent
+	// events subscription
+	ResourceTypeStream = "stream"
+	// ResourceTypeTCP is the type for the resources got sending a command over tcp
+	ResourceTypeTCP = "tcp"
+	// ResourceTypeSQL is the type for the resources got running a sql query
+	ResourceTypeSQL = "sql"
+	// ResourceTypeMetricsFordwader is the type for the resources exposing metrics in the prometheus format, they
+	// are forwarded with the job and instance labels
+	ResourceTypeMetricsFordwader = "metrics_fordwader"
+)
+
+const (
+	// DecoderJSON decode a json document, the nodes are found with paths like "/blockchain/head/seq" by default
+	DecoderJSON = "json"
+	// DecoderText decode the lines like "key value" or "key:value" in an object with a field per key
+	DecoderText = "text"
+	// DecoderXML decode a xml document, the nodes are found with xpath expressions by default
+	DecoderXML = "xml"
+	// DecoderYAML decode a yaml document, the nodes are found with paths like "/blockchain/head/seq" by default
+	DecoderYAML = "yaml"
+	// DecoderCSV decode comma separated values in an object like {"rows": [{"column": value}], "row_count": 1}, the
+	// nodes are found with paths like "/rows/column" by default
+	DecoderCSV = "csv"
+	// DecoderTSV decode tab separated values like DecoderCSV
+	DecoderTSV = "tsv"
+	// DecoderPlainText keep the body as text, the nodes are found by line by default or with a regular expression
+	DecoderPlainText = "plain_text"
+	// DecoderINI decode the lines like "key=value" in an object with a field per key and an object per section
+	DecoderINI = "ini"
+	// DecoderPrometheus decode a body in the Prometheus text exposition format in a list of series
+	DecoderPrometheus = "prometheus"
+)
+
+const (
+	// CSVHeaderAuto use the first row as the header if none of its fields is a number
+	CSVHeaderAuto = "auto"
+	// CSVHeaderFirstRow use the first row as the header always
+	CSVHeaderFirstRow = "first_row"
+	// CSVHeaderNone do not use a header, the columns are named column1, column2 ...
+	CSVHeaderNone = "none"
+)
+
+const (
+	// StreamWebSocket subscribe to a WebSocket endpoint
+	StreamWebSocket = "websocket"
+	// StreamSSE subscribe to a Server-Sent Events endpoint
+	StreamSSE = "sse"
+)
+
+const (
+	// PaginationPage request the pages with a page number and a page size in the query params
+	PaginationPage = "page"
+	// PaginationCursor request the next page with the cursor read from the previous one
+	PaginationCursor = "cursor"
+	// PaginationLink request the page in the Link header with rel="next"
+	PaginationLink = "link"
+)
+
+const (
+	// RetryErrorConnection retry the requests failing to connect or with the connection closed by the server
+	RetryErrorConnection = "connection"
+	// RetryErrorTimeout retry the requests failing by a timeout in the http client
+	RetryErrorTimeout = "timeout"
+	// RetryErrorDNS retry the requests failing to resolve the host name
+	RetryErrorDNS = "dns"
+)
+
 // RextAuthDef can store information about authentication requirements, how and where you can autenticate,
 // using what values, all this info is stored inside a RextAuthDef
 type RextAuthDef interface {
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BodyDecoder decode the body of a resource in a document where the nodes can be found by a NodeLookup
+type BodyDecoder interface {
+	DecodeBody(body []byte) (doc interface{}, err error)
+}
+
+// NodeLookup find the node in path inside a document decoded by a BodyDecoder, a path selecting many nodes should
+// return them as []interface{}. The document is shared by the metrics reading the same resource, so it should not be
+// modified.
+type NodeLookup interface {
+	PathLookup(path string, doc interface{}) (node interface{}, err error)
+}
+
+// PathValidator can be implemented by a NodeLookup to check the paths when the config is loaded, so an invalid
+// path is reported by the validation instead of failing on every scrape
+type PathValidator interface {
+	ValidatePath(path string) error
+}
+
+// DecoderFactory create a BodyDecoder with the options in a RextDecoderDef
+type DecoderFactory func(decoderOpts RextKeyValueStore) (BodyDecoder, error)
+
+// NodeSolverFactory create a NodeLookup with the options in a RextNodeSolver
+type NodeSolverFactory func(nodeSolverOpts RextKeyValueStore) (NodeLookup, error)
+
+// registeredDecoder is a decoder factory and the node solver type used when a metric does not define one
+type registeredDecoder struct {
+	factory               DecoderFactory
+	defaultNodeSolverType string
+}
+
+var (
+	registryMutex = &sync.RWMutex{}
+	decoders      = make(map[string]registeredDecoder)
+	nodeSolvers   = make(map[string]NodeSolverFactory)
+)
+
+// RegisterDecoder make a decoder available for the RextDecoderDef with decoderType, the nodes are found with the
+// defaultNodeSolverType for the metrics without a node solver type. It panics if decoderType is registered twice or
+// factory is nil, like database/sql.Register.
+func RegisterDecoder(decoderType, defaultNodeSolverType string, factory DecoderFactory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if factory == nil {
+		panic("config: RegisterDecoder factory is nil")
+	}
+	if _, dup := decoders[decoderType]; dup {
+		panic("config: RegisterDecoder called twice for decoder " + decoderType)
+	}
+	decoders[decoderType] = registeredDecoder{factory: factory, defaultNodeSolverType: defaultNodeSolverType}
+}
+
+// RegisterNodeSolver make a node solver available for the RextNodeSolver with nodeSolverType. It panics if
+// nodeSolverType is registered twice or factory is nil.
+func RegisterNodeSolver(nodeSolverType string, factory NodeSolverFactory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if factory == nil {
+		panic("config: RegisterNodeSolver factory is nil")
+	}
+	if _, dup := nodeSolvers[nodeSolverType]; dup {
+		panic("config: RegisterNodeSolver called twice for node solver " + nodeSolverType)
+	}
+	nodeSolvers[nodeSolverType] = factory
+}
+
+// DecoderTypes return the registered decoder types sorted
+func DecoderTypes() (types []string) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	for decoderType := range decoders {
+		types = append(types, decoderType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NodeSolverTypes return the registered node solver types sorted
+func NodeSolverTypes() (types []string) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	for nodeSolverType := range nodeSolvers {
+		types = append(types, nodeSolverType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// isDecoderRegistered return true if there is a decoder for decoderType
+func isDecoderRegistered(decoderType string) bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	_, found := decoders[decoderType]
+	return found
+}
+
+// isNodeSolverRegistered return true if there is a node solver for nodeSolverType
+func isNodeSolverRegistered(nodeSolverType string) bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	_, found := nodeSolvers[nodeSolverType]
+	return found
+}
+
+// NewBodyDecoder create the registered decoder for the decoder definition
+func NewBodyDecoder(decoderDef RextDecoderDef) (decoder BodyDecoder, err error) {
+	registryMutex.RLock()
+	registered, found := decoders[decoderDef.GetType()]
+	registryMutex.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown decoder %q, valid decoders are %v", decoderDef.GetType(), DecoderTypes())
+	}
+	return registered.factory(decoderDef.GetOptions())
+}
+
+// NewNodeLookup create the registered node solver for the node solver definition, the default node solver for
+// decoderType is used if the node solver definition have not a type
+func NewNodeLookup(decoderType string, nodeSolverDef RextNodeSolver) (lookup NodeLookup, err error) {
+	registryMutex.RLock()
+	nodeSolverType := nodeSolverDef.GetType()
+	if len(nodeSolverType) == 0 {
+		nodeSolverType = decoders[decoderType].defaultNodeSolverType
+	}
+	factory, found := nodeSolvers[nodeSolverType]
+	registryMutex.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown node solver %q, valid node solvers are %v", nodeSolverType, NodeSolverTypes())
+	}
+	return factory(nodeSolverDef.GetOptions())
+}
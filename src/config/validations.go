@@ -1,6 +1,16 @@
 package config
 
 import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/simelo/rextporter/src/util"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -26,6 +36,64 @@ func ValidateAuth(auth RextAuthDef) (hasError bool) {
 			hasError = true
 			log.Errorln("token from endpoint is required for CSRF auth type")
 		}
+		if validateRequestDef(opts, OptKeyRextAuthDefTokenGenHTTPMethod, OptKeyRextAuthDefTokenGenBody) {
+			hasError = true
+		}
+		if tks, err := opts.GetString(OptKeyRextAuthDefTokenSource); err == nil && len(tks) != 0 {
+			validSources := []string{AuthTokenSourceJSON, AuthTokenSourceHeader, AuthTokenSourceCookie}
+			if !util.StrSliceContains(validSources, tks) {
+				hasError = true
+				log.WithFields(log.Fields{"current": tks, "expected": validSources}).Errorln("invalid token source")
+			}
+		}
+		if tkia, err := opts.GetString(OptKeyRextAuthDefTokenInjectAs); err == nil && len(tkia) != 0 {
+			validInjections := []string{AuthTokenInjectAsHeader, AuthTokenInjectAsQuery, AuthTokenInjectAsCookie}
+			if !util.StrSliceContains(validInjections, tkia) {
+				hasError = true
+				log.WithFields(log.Fields{"current": tkia, "expected": validInjections}).Errorln("invalid token injection")
+			}
+		}
+	}
+	if auth.GetAuthType() == AuthTypeSessionLogin {
+		opts := auth.GetOptions()
+		if le, err := opts.GetString(OptKeyRextAuthDefLoginEndpoint); err != nil || len(le) == 0 {
+			hasError = true
+			log.Errorln("login endpoint is required for SessionLogin auth type")
+		}
+		if validateRequestDef(opts, OptKeyRextAuthDefLoginHTTPMethod, OptKeyRextAuthDefLoginBody) {
+			hasError = true
+		}
+	}
+	if auth.GetAuthType() == AuthTypeHMAC {
+		opts := auth.GetOptions()
+		if secret, err := opts.GetString(OptKeyRextAuthDefHMACSecret); err != nil || len(secret) == 0 {
+			hasError = true
+			log.Errorln("secret is required for HMAC auth type")
+		}
+		if ss, err := opts.GetString(OptKeyRextAuthDefHMACSigningString); err == nil && len(ss) != 0 {
+			if _, err := template.New("signingString").Parse(ss); err != nil {
+				hasError = true
+				log.WithError(err).Errorln("invalid signing string template")
+			}
+		}
+	}
+	return hasError
+}
+
+// validateRequestDef check the optional http method and body template of a request defined in options
+func validateRequestDef(opts RextKeyValueStore, methodKey, bodyKey string) (hasError bool) {
+	if method, err := opts.GetString(methodKey); err == nil && len(method) != 0 {
+		validMethods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch}
+		if !util.StrSliceContains(validMethods, method) {
+			hasError = true
+			log.WithFields(log.Fields{"current": method, "expected": validMethods}).Errorln("invalid http method")
+		}
+	}
+	if body, err := opts.GetString(bodyKey); err == nil && len(body) != 0 {
+		if _, err := template.New("body").Funcs(template.FuncMap{"env": os.Getenv}).Parse(body); err != nil {
+			hasError = true
+			log.WithError(err).Errorln("invalid body template")
+		}
 	}
 	return hasError
 }
@@ -42,9 +110,12 @@ func ValidateResource(r RextResourceDef) (hasError bool) {
 		hasError = true
 		log.Errorln("resource path is required in metric config")
 	}
+	// NOTE(denisacostaq@gmail.com): the metrics fordwader resources are forwarded without decoding them
 	if r.GetDecoder() == nil {
-		hasError = true
-		log.Errorln("decoder is required in metric config")
+		if r.GetType() != ResourceTypeMetricsFordwader {
+			hasError = true
+			log.Errorln("decoder is required in metric config")
+		}
 	} else if r.GetDecoder().Validate() {
 		hasError = true
 	}
@@ -53,6 +124,39 @@ func ValidateResource(r RextResourceDef) (hasError bool) {
 			hasError = true
 		}
 	}
+	if validateTLS(r.GetOptions()) {
+		hasError = true
+	}
+	if validateFile(r.GetOptions()) {
+		hasError = true
+	}
+	if validateExec(r.GetOptions()) {
+		hasError = true
+	}
+	if validateJSONRPC(r.GetOptions()) {
+		hasError = true
+	}
+	if validateGraphQL(r.GetOptions()) {
+		hasError = true
+	}
+	if validatePagination(r.GetOptions()) {
+		hasError = true
+	}
+	if validateStream(r.GetOptions()) {
+		hasError = true
+	}
+	if validateTCP(r.GetOptions()) {
+		hasError = true
+	}
+	if validateSQL(r.GetOptions()) {
+		hasError = true
+	}
+	if validateRetry(r.GetOptions()) {
+		hasError = true
+	}
+	if validateCircuitBreaker(r.GetOptions()) {
+		hasError = true
+	}
 	for _, mtrDef := range r.GetMetricDefs() {
 		if mtrDef.Validate() {
 			hasError = true
@@ -89,11 +193,41 @@ func ValidateService(srv RextServiceDef) (hasError bool) {
 		hasError = true
 		log.Errorln("protocol should not be null in service config")
 	}
+	if srv.GetProtocol() == ProtocolTCP {
+		if _, _, err := net.SplitHostPort(srv.GetBasePath()); err != nil {
+			hasError = true
+			log.WithError(err).WithField("location", srv.GetBasePath()).Errorln("location should be like host:port for the tcp protocol")
+		}
+	}
+	if srv.GetProtocol() == ProtocolSQL && validateSQLService(srvOpts) {
+		hasError = true
+	}
+	if srv.GetProtocol() == ProtocolHTTPUnix {
+		if socketPath, err := srvOpts.GetString(OptKeyRextServiceDefSocketPath); err != nil || len(socketPath) == 0 {
+			hasError = true
+			log.Errorln("socket is required in service config for the http+unix protocol")
+		}
+	}
 	if srv.GetAuthForBaseURL() != nil {
 		if srv.GetAuthForBaseURL().Validate() {
 			hasError = true
 		}
 	}
+	if validateTLS(srvOpts) {
+		hasError = true
+	}
+	if validateHTTP(srvOpts) {
+		hasError = true
+	}
+	if validateLimits(srvOpts) {
+		hasError = true
+	}
+	if validateRetry(srvOpts) {
+		hasError = true
+	}
+	if validateCircuitBreaker(srvOpts) {
+		hasError = true
+	}
 	for _, resource := range srv.GetResources() {
 		if resource.Validate() {
 			hasError = true
@@ -102,6 +236,391 @@ func ValidateService(srv RextServiceDef) (hasError bool) {
 	return hasError
 }
 
+// validateTLS check the optional tls settings in options
+func validateTLS(opts RextKeyValueStore) (hasError bool) {
+	certFile, _ := opts.GetString(OptKeyRextTLSCertFile)
+	keyFile, _ := opts.GetString(OptKeyRextTLSKeyFile)
+	if (len(certFile) == 0) != (len(keyFile) == 0) {
+		hasError = true
+		log.WithFields(log.Fields{"cert_file": certFile, "key_file": keyFile}).Errorln("tls cert file and key file should be defined together")
+	}
+	if minVersion, err := opts.GetString(OptKeyRextTLSMinVersion); err == nil && len(minVersion) != 0 {
+		validVersions := []string{TLSVersion10, TLSVersion11, TLSVersion12, TLSVersion13}
+		if !util.StrSliceContains(validVersions, minVersion) {
+			hasError = true
+			log.WithFields(log.Fields{"current": minVersion, "expected": validVersions}).Errorln("invalid tls min version")
+		}
+	}
+	if iInsecure, err := opts.GetObject(OptKeyRextTLSInsecureSkipVerify); err == nil {
+		if _, okInsecure := iInsecure.(bool); !okInsecure {
+			hasError = true
+			log.WithField("val", iInsecure).Errorln("tls insecure skip verify should be a bool")
+		}
+	}
+	return hasError
+}
+
+// validateHTTP check the optional http client settings in options
+func validateHTTP(opts RextKeyValueStore) (hasError bool) {
+	durationKeys := []string{
+		OptKeyRextServiceDefHTTPTimeout,
+		OptKeyRextServiceDefHTTPConnectTimeout,
+		OptKeyRextServiceDefHTTPTLSHandshakeTimeout,
+		OptKeyRextServiceDefHTTPKeepAlive,
+		OptKeyRextServiceDefHTTPIdleConnTimeout,
+	}
+	for _, key := range durationKeys {
+		if iVal, err := opts.GetObject(key); err == nil {
+			if val, okVal := iVal.(time.Duration); !okVal || val < 0 {
+				hasError = true
+				log.WithFields(log.Fields{"key": key, "val": iVal}).Errorln("http setting should be a positive duration")
+			}
+		}
+	}
+	intKeys := []string{
+		OptKeyRextServiceDefHTTPMaxIdleConns,
+		OptKeyRextServiceDefHTTPMaxIdleConnsPerHost,
+		OptKeyRextServiceDefHTTPMaxConnsPerHost,
+	}
+	for _, key := range intKeys {
+		if iVal, err := opts.GetObject(key); err == nil {
+			if val, okVal := iVal.(int); !okVal || val < 0 {
+				hasError = true
+				log.WithFields(log.Fields{"key": key, "val": iVal}).Errorln("http setting should be a positive int")
+			}
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextServiceDefHTTPDisableKeepAlives); err == nil {
+		if _, okVal := iVal.(bool); !okVal {
+			hasError = true
+			log.WithField("val", iVal).Errorln("http disable keep alives should be a bool")
+		}
+	}
+	if proxyURL, err := opts.GetString(OptKeyRextServiceDefHTTPProxyURL); err == nil && len(proxyURL) != 0 {
+		if _, err := url.Parse(proxyURL); err != nil {
+			hasError = true
+			log.WithError(err).Errorln("invalid http proxy url")
+		}
+	}
+	return hasError
+}
+
+// validateFile check the optional file data source settings in options
+func validateFile(opts RextKeyValueStore) (hasError bool) {
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefFileMaxSize); err == nil {
+		if val, okVal := iVal.(int); !okVal || val <= 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("file max size should be an int greater than zero")
+		}
+	}
+	return hasError
+}
+
+// validateExec check the optional command settings in options
+func validateExec(opts RextKeyValueStore) (hasError bool) {
+	strSliceKeys := []string{
+		OptKeyRextResourceDefExecArgs,
+		OptKeyRextResourceDefExecEnv,
+	}
+	for _, key := range strSliceKeys {
+		if iVal, err := opts.GetObject(key); err == nil {
+			if _, okVal := iVal.([]string); !okVal {
+				hasError = true
+				log.WithFields(log.Fields{"key": key, "val": iVal}).Errorln("exec setting should be an []string")
+			}
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefExecEnv); err == nil {
+		if env, okEnv := iVal.([]string); okEnv {
+			for _, variable := range env {
+				if !strings.Contains(variable, "=") {
+					hasError = true
+					log.WithField("val", variable).Errorln("exec environment variables should be like KEY=VALUE")
+				}
+			}
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefExecTimeout); err == nil {
+		if val, okVal := iVal.(time.Duration); !okVal || val <= 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("exec timeout should be a duration greater than zero")
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefExecMaxConcurrent); err == nil {
+		if val, okVal := iVal.(int); !okVal || val < 1 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("exec max concurrent should be an int greater than zero")
+		}
+	}
+	return hasError
+}
+
+// validateJSONRPC check the optional JSON-RPC settings in options
+func validateJSONRPC(opts RextKeyValueStore) (hasError bool) {
+	if params, err := opts.GetString(OptKeyRextResourceDefJSONRPCParams); err == nil && len(params) != 0 {
+		var val interface{}
+		if err := json.Unmarshal([]byte(params), &val); err != nil {
+			hasError = true
+			log.WithError(err).WithField("val", params).Errorln("json-rpc params should be a valid json")
+		} else {
+			switch val.(type) {
+			case []interface{}, map[string]interface{}:
+			default:
+				hasError = true
+				log.WithField("val", params).Errorln("json-rpc params should be a json array or object")
+			}
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefJSONRPCBatch); err == nil {
+		if _, okVal := iVal.(bool); !okVal {
+			hasError = true
+			log.WithField("val", iVal).Errorln("json-rpc batch should be a bool")
+		}
+	}
+	return hasError
+}
+
+// validateGraphQL check the optional GraphQL settings in options
+func validateGraphQL(opts RextKeyValueStore) (hasError bool) {
+	if variables, err := opts.GetString(OptKeyRextResourceDefGraphQLVariables); err == nil && len(variables) != 0 {
+		var val map[string]interface{}
+		if err := json.Unmarshal([]byte(variables), &val); err != nil {
+			hasError = true
+			log.WithError(err).WithField("val", variables).Errorln("graphql variables should be a json object")
+		}
+	}
+	return hasError
+}
+
+// validatePagination check the optional pagination settings in options
+func validatePagination(opts RextKeyValueStore) (hasError bool) {
+	strategy, err := opts.GetString(OptKeyRextResourceDefPaginationStrategy)
+	if err != nil {
+		return false
+	}
+	switch strategy {
+	case PaginationPage, PaginationLink:
+	case PaginationCursor:
+		if cursorPath, err := opts.GetString(OptKeyRextResourceDefPaginationCursorPath); err != nil || len(cursorPath) == 0 {
+			hasError = true
+			log.Errorln("cursor pagination require a cursor path")
+		}
+	default:
+		hasError = true
+		log.WithField("val", strategy).Errorln("pagination strategy should be one of page, cursor or link")
+	}
+	ints := []struct {
+		key string
+		min int
+	}{
+		{key: OptKeyRextResourceDefPaginationPageSize, min: 0},
+		{key: OptKeyRextResourceDefPaginationFirstPage, min: 0},
+		{key: OptKeyRextResourceDefPaginationMaxPages, min: 1},
+	}
+	for _, i := range ints {
+		if iVal, err := opts.GetObject(i.key); err == nil {
+			if val, okVal := iVal.(int); !okVal || val < i.min {
+				hasError = true
+				log.WithFields(log.Fields{"key": i.key, "val": iVal, "min": i.min}).Errorln("pagination value should be an int not lower than min")
+			}
+		}
+	}
+	return hasError
+}
+
+// validateStream check the optional stream settings in options
+func validateStream(opts RextKeyValueStore) (hasError bool) {
+	if kind, err := opts.GetString(OptKeyRextResourceDefStreamKind); err == nil {
+		if kind != StreamWebSocket && kind != StreamSSE {
+			hasError = true
+			log.WithField("val", kind).Errorln("stream kind should be one of websocket or sse")
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefStreamWindow); err == nil {
+		if val, okVal := iVal.(int); !okVal || val < 1 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("stream window should be an int greater than zero")
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefStreamWithCount); err == nil {
+		if _, okVal := iVal.(bool); !okVal {
+			hasError = true
+			log.WithField("val", iVal).Errorln("stream with count should be a bool")
+		}
+	}
+	durations := []string{
+		OptKeyRextResourceDefStreamReconnectInitialBackoff,
+		OptKeyRextResourceDefStreamReconnectMaxBackoff,
+	}
+	for _, key := range durations {
+		if iVal, err := opts.GetObject(key); err == nil {
+			if val, okVal := iVal.(time.Duration); !okVal || val <= 0 {
+				hasError = true
+				log.WithFields(log.Fields{"key": key, "val": iVal}).Errorln("stream reconnect backoff should be a positive time.Duration")
+			}
+		}
+	}
+	return hasError
+}
+
+// validateTCP check the optional tcp settings in options
+func validateTCP(opts RextKeyValueStore) (hasError bool) {
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefTCPTimeout); err == nil {
+		if val, okVal := iVal.(time.Duration); !okVal || val <= 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("tcp timeout should be a positive time.Duration")
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefTCPMaxSize); err == nil {
+		if val, okVal := iVal.(int); !okVal || val <= 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("tcp max size should be an int greater than zero")
+		}
+	}
+	return hasError
+}
+
+// validateSQLService check the database settings in options, the driver and a data source name are required
+func validateSQLService(opts RextKeyValueStore) (hasError bool) {
+	if driver, err := opts.GetString(OptKeyRextServiceDefSQLDriver); err != nil || len(driver) == 0 {
+		hasError = true
+		log.Errorln("driver is required in service config for the sql protocol")
+	}
+	dsnFile, _ := opts.GetString(OptKeyRextServiceDefSQLDSNFile)
+	dsnEnv, _ := opts.GetString(OptKeyRextServiceDefSQLDSNEnv)
+	if (len(dsnFile) == 0) == (len(dsnEnv) == 0) {
+		hasError = true
+		log.Errorln("one of dsn_file or dsn_env is required in service config for the sql protocol")
+	}
+	ints := []string{
+		OptKeyRextServiceDefSQLMaxOpenConns,
+		OptKeyRextServiceDefSQLMaxIdleConns,
+	}
+	for _, key := range ints {
+		if iVal, err := opts.GetObject(key); err == nil {
+			if val, okVal := iVal.(int); !okVal || val < 0 {
+				hasError = true
+				log.WithFields(log.Fields{"key": key, "val": iVal}).Errorln("sql connections limit should be a positive int")
+			}
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextServiceDefSQLConnMaxLifetime); err == nil {
+		if val, okVal := iVal.(time.Duration); !okVal || val < 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("sql connection max lifetime should be a positive time.Duration")
+		}
+	}
+	return hasError
+}
+
+// validateSQL check the optional query settings in options
+func validateSQL(opts RextKeyValueStore) (hasError bool) {
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefSQLTimeout); err == nil {
+		if val, okVal := iVal.(time.Duration); !okVal || val <= 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("sql timeout should be a positive time.Duration")
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextResourceDefSQLValueColumns); err == nil {
+		if _, okVal := iVal.([]string); !okVal {
+			hasError = true
+			log.WithField("val", iVal).Errorln("sql value columns should be an []string")
+		}
+	}
+	return hasError
+}
+
+// validateLimits check the optional requests limits in options
+func validateLimits(opts RextKeyValueStore) (hasError bool) {
+	if iVal, err := opts.GetObject(OptKeyRextServiceDefLimitRequestsPerSecond); err == nil {
+		if val, okVal := iVal.(float64); !okVal || val < 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("requests per second should be a positive float64")
+		}
+	}
+	intKeys := []string{
+		OptKeyRextServiceDefLimitBurst,
+		OptKeyRextServiceDefLimitMaxConcurrentRequests,
+	}
+	for _, key := range intKeys {
+		if iVal, err := opts.GetObject(key); err == nil {
+			if val, okVal := iVal.(int); !okVal || val < 0 {
+				hasError = true
+				log.WithFields(log.Fields{"key": key, "val": iVal}).Errorln("limit setting should be a positive int")
+			}
+		}
+	}
+	return hasError
+}
+
+// validateRetry check the optional retry policy in options
+func validateRetry(opts RextKeyValueStore) (hasError bool) {
+	if iVal, err := opts.GetObject(OptKeyRextRetryMaxAttempts); err == nil {
+		if val, okVal := iVal.(int); !okVal || val < 1 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("retry max attempts should be an int greater than zero")
+		}
+	}
+	durationKeys := []string{
+		OptKeyRextRetryInitialBackoff,
+		OptKeyRextRetryMaxBackoff,
+		OptKeyRextRetryTimeBudget,
+	}
+	for _, key := range durationKeys {
+		if iVal, err := opts.GetObject(key); err == nil {
+			if val, okVal := iVal.(time.Duration); !okVal || val < 0 {
+				hasError = true
+				log.WithFields(log.Fields{"key": key, "val": iVal}).Errorln("retry setting should be a positive duration")
+			}
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextRetryStatusCodes); err == nil {
+		codes, okCodes := iVal.([]int)
+		if !okCodes {
+			hasError = true
+			log.WithField("val", iVal).Errorln("retry status codes should be an []int")
+		}
+		for _, code := range codes {
+			if code < 100 || code > 599 {
+				hasError = true
+				log.WithField("val", code).Errorln("invalid retry status code")
+			}
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextRetryErrors); err == nil {
+		errorClasses, okErrorClasses := iVal.([]string)
+		if !okErrorClasses {
+			hasError = true
+			log.WithField("val", iVal).Errorln("retry errors should be an []string")
+		}
+		validErrorClasses := []string{RetryErrorConnection, RetryErrorTimeout, RetryErrorDNS}
+		for _, errorClass := range errorClasses {
+			if !util.StrSliceContains(validErrorClasses, errorClass) {
+				hasError = true
+				log.WithFields(log.Fields{"current": errorClass, "expected": validErrorClasses}).Errorln("invalid retry error class")
+			}
+		}
+	}
+	return hasError
+}
+
+// validateCircuitBreaker check the optional circuit breaker settings in options
+func validateCircuitBreaker(opts RextKeyValueStore) (hasError bool) {
+	if iVal, err := opts.GetObject(OptKeyRextCircuitBreakerFailureThreshold); err == nil {
+		if val, okVal := iVal.(int); !okVal || val < 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("circuit breaker failure threshold should be a positive int")
+		}
+	}
+	if iVal, err := opts.GetObject(OptKeyRextCircuitBreakerCoolDown); err == nil {
+		if val, okVal := iVal.(time.Duration); !okVal || val < 0 {
+			hasError = true
+			log.WithField("val", iVal).Errorln("circuit breaker cool down should be a positive duration")
+		}
+	}
+	return hasError
+}
+
 // ValidateNodeSolver check if the node solver instance in parameter fill the required constraints
 // to be considered as a valid RextNodeSolver.
 // Return true if any error is found
@@ -110,6 +629,21 @@ func ValidateNodeSolver(ns RextNodeSolver) (hasError bool) {
 		hasError = true
 		log.Errorln("node path is required in node solver config")
 	}
+	if len(ns.GetType()) != 0 && !isNodeSolverRegistered(ns.GetType()) {
+		hasError = true
+		log.WithFields(log.Fields{"type": ns.GetType(), "valid_types": NodeSolverTypes()}).Errorln("unknown node solver type")
+	}
+	if len(ns.GetType()) != 0 && len(ns.GetNodePath()) != 0 && isNodeSolverRegistered(ns.GetType()) {
+		if lookup, err := NewNodeLookup("", ns); err != nil {
+			hasError = true
+			log.WithError(err).Errorln("can not create the node solver")
+		} else if validator, isValidator := lookup.(PathValidator); isValidator {
+			if err = validator.ValidatePath(ns.GetNodePath()); err != nil {
+				hasError = true
+				log.WithFields(log.Fields{"err": err, "path": ns.GetNodePath()}).Errorln("invalid path in node solver config")
+			}
+		}
+	}
 	return hasError
 }
 
@@ -137,6 +671,33 @@ func ValidateDecoder(d RextDecoderDef) (hasError bool) {
 	if len(d.GetType()) == 0 {
 		hasError = true
 		log.Errorln("type is required in decoder config")
+	} else if !isDecoderRegistered(d.GetType()) {
+		hasError = true
+		log.WithFields(log.Fields{"type": d.GetType(), "valid_types": DecoderTypes()}).Errorln("unknown decoder type")
+	}
+	if d.GetType() == DecoderCSV || d.GetType() == DecoderTSV {
+		if validateCSVDecoder(d.GetOptions()) {
+			hasError = true
+		}
+	}
+	return hasError
+}
+
+// validateCSVDecoder check the delimiter is a single character and the header a valid one
+func validateCSVDecoder(decoderOpts RextKeyValueStore) (hasError bool) {
+	if delimiter, err := decoderOpts.GetString(OptKeyRextDecoderCSVDelimiter); err == nil {
+		if runes := []rune(delimiter); len(runes) != 1 || strings.ContainsRune("\r\n\"", runes[0]) {
+			hasError = true
+			log.WithField("delimiter", delimiter).Errorln("csv delimiter should be a single character different to a quote or a line break")
+		}
+	}
+	if header, err := decoderOpts.GetString(OptKeyRextDecoderCSVHeader); err == nil {
+		switch header {
+		case CSVHeaderAuto, CSVHeaderFirstRow, CSVHeaderNone:
+		default:
+			hasError = true
+			log.WithField("header", header).Errorln("csv header should be auto, first_row or none")
+		}
 	}
 	return hasError
 }
@@ -1,34 +1,47 @@
 package exporter
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
 	"github.com/simelo/rextporter/src/cache"
+	"github.com/simelo/rextporter/src/client"
 	"github.com/simelo/rextporter/src/config"
 	"github.com/simelo/rextporter/src/scrapper"
 	"github.com/simelo/rextporter/src/util"
+	"github.com/simelo/rextporter/src/util/metrics"
 	log "github.com/sirupsen/logrus"
 )
 
 // MetricsCollector has the metrics to be exposed
 type MetricsCollector struct {
-	metrics    endpointData2MetricsConsumer
-	cache      cache.Cache
-	defMetrics *defaultMetrics
+	metrics       endpointData2MetricsConsumer
+	cache         cache.Cache
+	defMetrics    *defaultMetrics
+	clientMetrics *metrics.DefaultClientMetrics
+	scrapeMutex   *sync.Mutex
 }
 
-func newMetricsCollector(c cache.Cache, conf config.RextRoot) (collector *MetricsCollector, err error) {
+func newMetricsCollector(c cache.Cache, conf config.RextRoot, cDefMetrics *metrics.DefaultClientMetrics) (collector *MetricsCollector, err error) {
 	const generalScopeErr = "error creating collector"
 	defMetrics := newDefaultMetrics()
 	var metrics endpointData2MetricsConsumer
-	if metrics, err = createMetrics(c, conf, defMetrics.dataSourceResponseDurationDesc); err != nil {
+	if metrics, err = createMetrics(c, conf, defMetrics.dataSourceResponseDurationDesc, cDefMetrics); err != nil {
 		errCause := fmt.Sprintln("error creating metrics: ", err.Error())
 		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
-	collector = &MetricsCollector{metrics: metrics, cache: c, defMetrics: defMetrics}
+	collector = &MetricsCollector{
+		metrics:       metrics,
+		cache:         c,
+		defMetrics:    defMetrics,
+		clientMetrics: cDefMetrics,
+		scrapeMutex:   &sync.Mutex{},
+	}
 	return collector, err
 }
 
@@ -42,7 +55,105 @@ func (collector *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
 	collector.defMetrics.describe(ch)
 }
 
-func collectCounters(metricsColl []constMetric, defMetrics *defaultMetrics, ch chan<- prometheus.Metric) {
+// scrapInPool run the scrappers in the workers pool and call onSuccess for each result received before ctx is
+// done, the metrics not received in time are counted in timeouts(can be nil)
+func scrapInPool(ctx context.Context, metricsColl []constMetric, defMetrics *defaultMetrics, timeouts *prometheus.CounterVec, ch chan<- prometheus.Metric, onSuccess func(res scrapper.ScrapResult)) {
+	// NOTE(denisacostaq@gmail.com): the channels are buffered and never closed because a scrapper can still be
+	// running after the deadline, the late results are discarded by drainLateResults
+	resC := make(chan scrapper.ScrapResult, len(metricsColl))
+	errC := make(chan scrapper.ScrapErrResult, len(metricsColl))
+	scrapperMetrics := make(chan prometheus.Metric)
+	stopForwarding := make(chan struct{})
+	forwardingDone := make(chan struct{})
+	go func() {
+		defer close(forwardingDone)
+		for {
+			select {
+			case metric := <-scrapperMetrics:
+				ch <- metric
+			case <-stopForwarding:
+				return
+			}
+		}
+	}()
+	startScrappingInPool := time.Now().UTC()
+	for idxM, mColl := range metricsColl {
+		scrapper.WorkPool.Apply(
+			scrapper.ScrapRequest{
+				Ctx:              ctx,
+				Scrap:            mColl.scrapper,
+				Res:              resC,
+				ConstMetricIdxIn: idxM,
+				JobName:          mColl.scrapper.GetJobName(),
+				InstanceName:     mColl.scrapper.GetInstanceName(),
+				DataSource:       mColl.scrapper.GetDataSource(),
+				Err:              errC,
+				MetricsCollector: scrapperMetrics,
+			},
+		)
+	}
+	received := make([]bool, len(metricsColl))
+	onTimeout := func(idxM int) {
+		mColl := metricsColl[idxM]
+		labels := []string{mColl.scrapper.GetJobName(), mColl.scrapper.GetInstanceName(), mColl.scrapper.GetDataSource()}
+		log.WithField("labels", labels).Warnln("scrape deadline reached before getting the data")
+		if timeouts != nil {
+			timeouts.WithLabelValues(labels...).Inc()
+		}
+	}
+	pending := len(metricsColl)
+	for ; pending > 0; pending-- {
+		select {
+		case res := <-resC:
+			received[res.ConstMetricIdxOut] = true
+			onSuccess(res)
+			elapsed := time.Since(startScrappingInPool).Seconds()
+			defMetrics.scrapeDurations.addSeconds(elapsed, res.JobName, res.InstanceName)
+			defMetrics.dataSourceScrapeDuration.add(elapsed, res.JobName, res.InstanceName, res.DataSource)
+		case err := <-errC:
+			received[err.ConstMetricIdxOut] = true
+			if ctx.Err() != nil {
+				onTimeout(err.ConstMetricIdxOut)
+			} else if client.IsCircuitOpen(err.Err) {
+				log.WithError(err.Err).Debugln("data source skipped")
+			} else {
+				log.WithError(err.Err).Errorln("can not get the data")
+			}
+			// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[err.ConstMetricIdxOut], err.JobName, err.InstanceName, ch)
+			elapsed := time.Since(startScrappingInPool).Seconds()
+			defMetrics.scrapeDurations.addSeconds(elapsed, err.JobName, err.InstanceName)
+			defMetrics.dataSourceScrapeDuration.add(elapsed, err.JobName, err.InstanceName, err.DataSource)
+		case <-ctx.Done():
+			for idxM := range metricsColl {
+				if !received[idxM] {
+					onTimeout(idxM)
+				}
+			}
+			close(stopForwarding)
+			<-forwardingDone
+			go drainLateResults(pending, resC, errC, scrapperMetrics)
+			return
+		}
+	}
+	close(stopForwarding)
+	<-forwardingDone
+}
+
+// drainLateResults discard the results and metrics of the scrappers still running after the scrape deadline,
+// this way the workers in the pool are not blocked
+func drainLateResults(pending int, resC chan scrapper.ScrapResult, errC chan scrapper.ScrapErrResult, scrapperMetrics chan prometheus.Metric) {
+	for pending > 0 {
+		select {
+		case <-resC:
+			pending--
+		case <-errC:
+			pending--
+		case <-scrapperMetrics:
+		}
+	}
+}
+
+func collectCounters(ctx context.Context, metricsColl []constMetric, defMetrics *defaultMetrics, timeouts *prometheus.CounterVec, ch chan<- prometheus.Metric) {
 	recoverNegativeCounter := func(counter constMetric, fch chan<- prometheus.Metric) {
 		if r := recover(); r != nil {
 			switch val := r.(type) {
@@ -76,68 +187,35 @@ func collectCounters(metricsColl []constMetric, defMetrics *defaultMetrics, ch c
 			}
 		}
 	}
-	resC := make(chan scrapper.ScrapResult)
-	defer close(resC)
-	errC := make(chan scrapper.ScrapErrResult)
-	defer close(errC)
-	var metricsNum = 0
-	startScrappingInPool := time.Now().UTC()
-	for idxM, mColl := range metricsColl {
-		scrapper.WorkPool.Apply(
-			scrapper.ScrapRequest{
-				Scrap:            mColl.scrapper,
-				Res:              resC,
-				ConstMetricIdxIn: idxM,
-				JobName:          mColl.scrapper.GetJobName(),
-				InstanceName:     mColl.scrapper.GetInstanceName(),
-				DataSource:       mColl.scrapper.GetDataSource(),
-				Err:              errC,
-				MetricsCollector: ch,
-			},
-		)
-		metricsNum++
-	}
-	for i := 0; i < metricsNum; i++ {
-		select {
-		case res := <-resC:
-			switch res.Val.(type) {
-			case float64:
-				counterVal, okCounterVal := res.Val.(float64)
-				defMetrics.scrapeSamples.addSeconds(1, res.JobName, res.InstanceName)
-				defMetrics.dataSourceScrapeSamples.add(1, res.JobName, res.InstanceName, res.DataSource)
-				if okCounterVal {
-					onCollectSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, counterVal)
-				} else {
-					log.WithField("val", res.Val).Errorln(fmt.Sprintf("unable to get value %+v as float64", res.Val))
-				}
-			case scrapper.NumericVecVals:
-				counterVecVal, okCounterVecVal := res.Val.(scrapper.NumericVecVals)
-				defMetrics.scrapeSamples.addSeconds(float64(len(counterVecVal)), res.JobName, res.InstanceName)
-				defMetrics.dataSourceScrapeSamples.add(float64(len(counterVecVal)), res.JobName, res.InstanceName, res.DataSource)
-				if okCounterVecVal {
-					onCollectVecSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, counterVecVal)
-				} else {
-					log.WithField("val", res.Val).Errorln(fmt.Sprintf("unable to get value %+v as float64", res.Val))
-				}
-			default:
-				log.WithFields(log.Fields{
-					"val":  res.Val,
-					"type": fmt.Sprintf("%T", res.Val)}).Errorln("unable to determine value type in counter")
+	scrapInPool(ctx, metricsColl, defMetrics, timeouts, ch, func(res scrapper.ScrapResult) {
+		switch res.Val.(type) {
+		case float64:
+			counterVal, okCounterVal := res.Val.(float64)
+			defMetrics.scrapeSamples.addSeconds(1, res.JobName, res.InstanceName)
+			defMetrics.dataSourceScrapeSamples.add(1, res.JobName, res.InstanceName, res.DataSource)
+			if okCounterVal {
+				onCollectSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, counterVal)
+			} else {
+				log.WithField("val", res.Val).Errorln(fmt.Sprintf("unable to get value %+v as float64", res.Val))
 			}
-			elapsed := time.Since(startScrappingInPool).Seconds()
-			defMetrics.scrapeDurations.addSeconds(elapsed, res.JobName, res.InstanceName)
-			defMetrics.dataSourceScrapeDuration.add(elapsed, res.JobName, res.InstanceName, res.DataSource)
-		case err := <-errC:
-			log.WithError(err.Err).Errorln("can not get the data")
-			// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[err.ConstMetricIdxOut], err.JobName, err.InstanceName, ch)
-			elapsed := time.Since(startScrappingInPool).Seconds()
-			defMetrics.scrapeDurations.addSeconds(elapsed, err.JobName, err.InstanceName)
-			defMetrics.dataSourceScrapeDuration.add(elapsed, err.JobName, err.InstanceName, err.DataSource)
+		case scrapper.NumericVecVals:
+			counterVecVal, okCounterVecVal := res.Val.(scrapper.NumericVecVals)
+			defMetrics.scrapeSamples.addSeconds(float64(len(counterVecVal)), res.JobName, res.InstanceName)
+			defMetrics.dataSourceScrapeSamples.add(float64(len(counterVecVal)), res.JobName, res.InstanceName, res.DataSource)
+			if okCounterVecVal {
+				onCollectVecSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, counterVecVal)
+			} else {
+				log.WithField("val", res.Val).Errorln(fmt.Sprintf("unable to get value %+v as float64", res.Val))
+			}
+		default:
+			log.WithFields(log.Fields{
+				"val":  res.Val,
+				"type": fmt.Sprintf("%T", res.Val)}).Errorln("unable to determine value type in counter")
 		}
-	}
+	})
 }
 
-func collectGauges(metricsColl []constMetric, defMetrics *defaultMetrics, ch chan<- prometheus.Metric) {
+func collectGauges(ctx context.Context, metricsColl []constMetric, defMetrics *defaultMetrics, timeouts *prometheus.CounterVec, ch chan<- prometheus.Metric) {
 	onCollectSuccess := func(gauge *constMetric, jobName, instanceName string, fch chan<- prometheus.Metric, val float64) {
 		if metric, err := prometheus.NewConstMetric(gauge.metricDesc, prometheus.GaugeValue, val, jobName, instanceName); err == nil {
 			fch <- metric
@@ -157,71 +235,38 @@ func collectGauges(metricsColl []constMetric, defMetrics *defaultMetrics, ch cha
 			}
 		}
 	}
-	resC := make(chan scrapper.ScrapResult)
-	defer close(resC)
-	errC := make(chan scrapper.ScrapErrResult)
-	defer close(errC)
-	var metricsNum = 0
-	startScrappingInPool := time.Now().UTC()
-	for idxM, mColl := range metricsColl {
-		scrapper.WorkPool.Apply(
-			scrapper.ScrapRequest{
-				Scrap:            mColl.scrapper,
-				Res:              resC,
-				ConstMetricIdxIn: idxM,
-				JobName:          mColl.scrapper.GetJobName(),
-				InstanceName:     mColl.scrapper.GetInstanceName(),
-				DataSource:       mColl.scrapper.GetDataSource(),
-				Err:              errC,
-				MetricsCollector: ch,
-			},
-		)
-		metricsNum++
-	}
-	for i := 0; i < metricsNum; i++ {
-		select {
-		case res := <-resC:
-			switch res.Val.(type) {
-			case float64:
-				defMetrics.scrapeSamples.addSeconds(1, res.JobName, res.InstanceName)
-				defMetrics.dataSourceScrapeSamples.add(1, res.JobName, res.InstanceName, res.DataSource)
-				gaugeVal, okGaugeVal := res.Val.(float64)
-				if okGaugeVal {
-					onCollectSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, gaugeVal)
-				} else {
-					log.WithField("val", res.Val).Errorln(fmt.Sprintf("unable to get value %+v as float64", res.Val))
-					// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[res.ConstMetricIdxOut], res.JobName, res.InstanceName, ch)
-				}
-			case scrapper.NumericVecVals:
-				gaugeVecVal, okGaugeVecVal := res.Val.(scrapper.NumericVecVals)
-				defMetrics.scrapeSamples.addSeconds(float64(len(gaugeVecVal)), res.JobName, res.InstanceName)
-				defMetrics.dataSourceScrapeSamples.add(float64(len(gaugeVecVal)), res.JobName, res.InstanceName, res.DataSource)
-				if okGaugeVecVal {
-					onCollectVecSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, gaugeVecVal)
-				} else {
-					log.WithField("val", res.Val).Errorln(fmt.Sprintf("unable to get value %+v as float64", res.Val))
-					// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[res.ConstMetricIdxOut], res.JobName, res.InstanceName, ch)
-				}
-			default:
-				log.WithFields(log.Fields{
-					"val":  res.Val,
-					"type": fmt.Sprintf("%T", res.Val)}).Errorln("unable to determine value type in gauge")
+	scrapInPool(ctx, metricsColl, defMetrics, timeouts, ch, func(res scrapper.ScrapResult) {
+		switch res.Val.(type) {
+		case float64:
+			defMetrics.scrapeSamples.addSeconds(1, res.JobName, res.InstanceName)
+			defMetrics.dataSourceScrapeSamples.add(1, res.JobName, res.InstanceName, res.DataSource)
+			gaugeVal, okGaugeVal := res.Val.(float64)
+			if okGaugeVal {
+				onCollectSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, gaugeVal)
+			} else {
+				log.WithField("val", res.Val).Errorln(fmt.Sprintf("unable to get value %+v as float64", res.Val))
 				// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[res.ConstMetricIdxOut], res.JobName, res.InstanceName, ch)
 			}
-			elapsed := time.Since(startScrappingInPool).Seconds()
-			defMetrics.scrapeDurations.addSeconds(elapsed, res.JobName, res.InstanceName)
-			defMetrics.dataSourceScrapeDuration.add(elapsed, res.JobName, res.InstanceName, res.DataSource)
-		case err := <-errC:
-			log.WithError(err.Err).Errorln("can not get the data")
-			// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[err.ConstMetricIdxOut], err.JobName, err.InstanceName, ch)
-			elapsed := time.Since(startScrappingInPool).Seconds()
-			defMetrics.scrapeDurations.addSeconds(elapsed, err.JobName, err.InstanceName)
-			defMetrics.dataSourceScrapeDuration.add(elapsed, err.JobName, err.InstanceName, err.DataSource)
+		case scrapper.NumericVecVals:
+			gaugeVecVal, okGaugeVecVal := res.Val.(scrapper.NumericVecVals)
+			defMetrics.scrapeSamples.addSeconds(float64(len(gaugeVecVal)), res.JobName, res.InstanceName)
+			defMetrics.dataSourceScrapeSamples.add(float64(len(gaugeVecVal)), res.JobName, res.InstanceName, res.DataSource)
+			if okGaugeVecVal {
+				onCollectVecSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, gaugeVecVal)
+			} else {
+				log.WithField("val", res.Val).Errorln(fmt.Sprintf("unable to get value %+v as float64", res.Val))
+				// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[res.ConstMetricIdxOut], res.JobName, res.InstanceName, ch)
+			}
+		default:
+			log.WithFields(log.Fields{
+				"val":  res.Val,
+				"type": fmt.Sprintf("%T", res.Val)}).Errorln("unable to determine value type in gauge")
+			// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[res.ConstMetricIdxOut], res.JobName, res.InstanceName, ch)
 		}
-	}
+	})
 }
 
-func collectHistograms(metricsColl []constMetric, defMetrics *defaultMetrics, ch chan<- prometheus.Metric) {
+func collectHistograms(ctx context.Context, metricsColl []constMetric, defMetrics *defaultMetrics, timeouts *prometheus.CounterVec, ch chan<- prometheus.Metric) {
 	onCollectSuccess := func(histogram *constMetric, jobName, instanceName string, fch chan<- prometheus.Metric, val scrapper.HistogramValue) {
 		if metric, err := prometheus.NewConstHistogram(
 			histogram.metricDesc,
@@ -236,53 +281,56 @@ func collectHistograms(metricsColl []constMetric, defMetrics *defaultMetrics, ch
 			log.WithError(err).Errorln("collectHistogram -> onCollectSuccess can not set the value")
 		}
 	}
-	resC := make(chan scrapper.ScrapResult)
-	defer close(resC)
-	errC := make(chan scrapper.ScrapErrResult)
-	defer close(errC)
-	var metricsNum = 0
-	startScrappingInPool := time.Now().UTC()
-	for idxM, mColl := range metricsColl {
-		scrapper.WorkPool.Apply(
-			scrapper.ScrapRequest{
-				Scrap:            mColl.scrapper,
-				Res:              resC,
-				ConstMetricIdxIn: idxM,
-				JobName:          mColl.scrapper.GetJobName(),
-				InstanceName:     mColl.scrapper.GetInstanceName(),
-				DataSource:       mColl.scrapper.GetDataSource(),
-				Err:              errC,
-				MetricsCollector: ch,
-			},
-		)
-		metricsNum++
-	}
-	for i := 0; i < metricsNum; i++ {
-		select {
-		case res := <-resC:
-			metricVal, okMetricVal := res.Val.(scrapper.HistogramValue)
-			defMetrics.scrapeSamples.addSeconds(float64(len(metricVal.Buckets)+2), res.JobName, res.InstanceName)
-			defMetrics.dataSourceScrapeSamples.add(float64(len(metricVal.Buckets)+2), res.JobName, res.InstanceName, res.DataSource)
-			if okMetricVal {
-				onCollectSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, metricVal)
-			} else {
-				log.WithField("val", res.Val).Errorln("can not assert the metric value to type histogram")
-			}
-			elapsed := time.Since(startScrappingInPool).Seconds()
-			defMetrics.scrapeDurations.addSeconds(elapsed, res.JobName, res.InstanceName)
-			defMetrics.dataSourceScrapeDuration.add(elapsed, res.JobName, res.InstanceName, res.DataSource)
-		case err := <-errC:
-			log.WithError(err.Err).Errorln("can not get the data")
-			// FIXME(denisacostaq@gmail.com): onCollectFail(metricsColl[err.ConstMetricIdxOut], err.JobName, err.InstanceName, ch)
-			elapsed := time.Since(startScrappingInPool).Seconds()
-			defMetrics.scrapeDurations.addSeconds(elapsed, err.JobName, err.InstanceName)
-			defMetrics.dataSourceScrapeDuration.add(elapsed, err.JobName, err.InstanceName, err.DataSource)
+	scrapInPool(ctx, metricsColl, defMetrics, timeouts, ch, func(res scrapper.ScrapResult) {
+		metricVal, okMetricVal := res.Val.(scrapper.HistogramValue)
+		defMetrics.scrapeSamples.addSeconds(float64(len(metricVal.Buckets)+2), res.JobName, res.InstanceName)
+		defMetrics.dataSourceScrapeSamples.add(float64(len(metricVal.Buckets)+2), res.JobName, res.InstanceName, res.DataSource)
+		if okMetricVal {
+			onCollectSuccess(&(metricsColl[res.ConstMetricIdxOut]), res.JobName, res.InstanceName, ch, metricVal)
+		} else {
+			log.WithField("val", res.Val).Errorln("can not assert the metric value to type histogram")
 		}
-	}
+	})
+}
+
+// scrapeCollector collect the metrics of a MetricsCollector with the context of a scrape
+type scrapeCollector struct {
+	ctx       context.Context
+	collector *MetricsCollector
+}
+
+// Describe writes all the descriptors of the collector to the prometheus desc channel.
+func (sc scrapeCollector) Describe(ch chan<- *prometheus.Desc) {
+	sc.collector.Describe(ch)
+}
+
+// Collect update all the descriptors is values, the data sources not answering before the scrape context is done
+// are skipped
+func (sc scrapeCollector) Collect(ch chan<- prometheus.Metric) {
+	sc.collector.collect(sc.ctx, ch)
 }
 
-//Collect update all the descriptors is values
+// gatherer return a gatherer for the metrics of the collector, giving up on the data sources not answering before
+// ctx is done. The scrapes are made one at a time because they share the cache.
+func (collector *MetricsCollector) gatherer(ctx context.Context) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*io_prometheus_client.MetricFamily, error) {
+		registry := prometheus.NewRegistry()
+		if err := registry.Register(scrapeCollector{ctx: ctx, collector: collector}); err != nil {
+			return nil, err
+		}
+		collector.scrapeMutex.Lock()
+		defer collector.scrapeMutex.Unlock()
+		return registry.Gather()
+	})
+}
+
+// Collect does nothing, the collector is registered to check its descriptors against the other metrics but the values
+// are collected with the context of each scrape(see gatherer)
 func (collector *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+}
+
+// collect update all the descriptors is values, the data sources not answering before ctx is done are skipped
+func (collector *MetricsCollector) collect(ctx context.Context, ch chan<- prometheus.Metric) {
 	filterMetricsByKind := func(kind string, orgMetrics []constMetric) (filteredMetrics []constMetric) {
 		for _, metric := range orgMetrics {
 			if metric.kind == kind {
@@ -291,14 +339,19 @@ func (collector *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 		return filteredMetrics
 	}
+	var timeouts *prometheus.CounterVec
+	if collector.clientMetrics != nil {
+		timeouts = collector.clientMetrics.DataSourceScrapeTimeouts
+	}
 	collector.defMetrics.reset()
+	ctx = client.NewScrapeContext(ctx)
 	for k := range collector.metrics {
 		counters := filterMetricsByKind(config.KeyMetricTypeCounter, collector.metrics[k])
 		gauges := filterMetricsByKind(config.KeyMetricTypeGauge, collector.metrics[k])
 		histograms := filterMetricsByKind(config.KeyMetricTypeHistogram, collector.metrics[k])
-		collectCounters(counters, collector.defMetrics, ch)
-		collectGauges(gauges, collector.defMetrics, ch)
-		collectHistograms(histograms, collector.defMetrics, ch)
+		collectCounters(ctx, counters, collector.defMetrics, timeouts, ch)
+		collectGauges(ctx, gauges, collector.defMetrics, timeouts, ch)
+		collectHistograms(ctx, histograms, collector.defMetrics, timeouts, ch)
 		collector.cache.Reset()
 	}
 	collector.defMetrics.collectDefaultMetrics(ch)
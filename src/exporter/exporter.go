@@ -1,14 +1,13 @@
 package exporter
 
 import (
-	"bufio"
 	"bytes"
-	"compress/gzip"
+	"context"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net/http"
-	"net/http/httptest"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/NYTimes/gziphandler"
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,187 +15,134 @@ import (
 	io_prometheus_client "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 	"github.com/simelo/rextporter/src/cache"
+	"github.com/simelo/rextporter/src/client"
 	"github.com/simelo/rextporter/src/config"
 	"github.com/simelo/rextporter/src/scrapper"
-	"github.com/simelo/rextporter/src/util"
 	"github.com/simelo/rextporter/src/util/metrics"
-	mutil "github.com/simelo/rextporter/src/util/metrics"
 	log "github.com/sirupsen/logrus"
 )
 
-func autolabelSelfMetrics(metrics []byte, listenAddr string) (labeledMetrics []byte, err error) {
-	job := config.KeyLabelJob
-	jobValue := config.SystemProgramName
-	instance := config.KeyLabelInstance
-	instanceValue := listenAddr
-	var mtrsWithOutLabels []string
+// autolabelSelfMetrics add the job and instance labels to the metrics in metricFamilies not having them
+func autolabelSelfMetrics(metricFamilies []*io_prometheus_client.MetricFamily, listenAddr string) {
+	labels := map[string]string{config.KeyLabelJob: config.SystemProgramName, config.KeyLabelInstance: listenAddr}
 	// NOTE(denisacostaq@gmail.com): all scrapped metrics should have at least the
 	// job and instance labels, so the metrics without labels are the autogenerated by
 	// golang client library, like: go_gc_duration_seconds, go_memstats_sys_bytes, ...
-	if mtrsWithOutLabels, err = mutil.FindMetricsNamesWithoutLabels(metrics, []string{job, instance}); err != nil {
-		log.WithError(err).Errorln("ca not find unlabeled metrics")
-		return nil, err
-	}
-	labeledMetrics, err = mutil.AppendLables(
-		mtrsWithOutLabels,
-		metrics,
-		[]*io_prometheus_client.LabelPair{
-			&io_prometheus_client.LabelPair{
-				Name:  &job,
-				Value: &jobValue,
-			},
-			&io_prometheus_client.LabelPair{
-				Name:  &instance,
-				Value: &instanceValue,
-			},
-		},
-	)
-	if err != nil {
-		log.WithError(err).Errorln("Can not append default labels for self metric inside rextporter")
-		return nil, config.ErrKeyDecodingFile
+	for _, mf := range metricFamilies {
+		for _, metric := range mf.GetMetric() {
+			found := make(map[string]bool, len(labels))
+			for _, label := range metric.GetLabel() {
+				found[label.GetName()] = true
+			}
+			for name, value := range labels {
+				if !found[name] {
+					labelName, labelValue := name, value
+					metric.Label = append(metric.Label, &io_prometheus_client.LabelPair{Name: &labelName, Value: &labelValue})
+				}
+			}
+			sort.Slice(metric.Label, func(i, j int) bool {
+				return metric.Label[i].GetName() < metric.Label[j].GetName()
+			})
+		}
 	}
-	return labeledMetrics, err
 }
 
-func mergeMetrics(scrappedMetrics, fordwadedMetrics []byte) (mergedMetrics []byte, err error) {
-	recordMetrics := func(metricsRecorder map[string]*io_prometheus_client.MetricFamily, metrics []byte) (err error) {
-		var parser expfmt.TextParser
-		in := bytes.NewReader(metrics)
-		var metricsFamilies map[string]*io_prometheus_client.MetricFamily
-		if metricsFamilies, err = parser.TextToMetricFamilies(in); err != nil {
-			log.WithError(err).Errorln("error, reading text format failed")
-			return config.ErrKeyDecodingFile
-		}
-		for key, mf := range metricsFamilies {
-			if mmf, ok := metricsRecorder[key]; ok {
-				mtrs := make([]*io_prometheus_client.Metric, len(mf.Metric)+len(mmf.Metric))
-				for idxMetric := range mf.Metric {
-					mtrs[idxMetric] = mf.Metric[idxMetric]
-				}
-				for idxMetric := range mmf.Metric {
-					mtrs[len(mf.Metric)+idxMetric] = mmf.Metric[idxMetric]
-				}
-				mmf.Metric = mtrs
-			} else {
-				metricsRecorder[key] = mf
+// defaultMetricsGatherer gather the metrics in the default registry, the ones about rextporter itself are labeled
+// with the rextporter job and the listenAddr instance
+func defaultMetricsGatherer(listenAddr string) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() (metricFamilies []*io_prometheus_client.MetricFamily, err error) {
+		metricFamilies, err = prometheus.DefaultGatherer.Gather()
+		autolabelSelfMetrics(metricFamilies, listenAddr)
+		return metricFamilies, err
+	})
+}
+
+// fordwadedMetricsGatherer gather the metrics from the metrics forwaders, giving up on the ones not answering before
+// ctx is done
+func fordwadedMetricsGatherer(ctx context.Context, scrappers []scrapper.FordwaderScrapper) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() (metricFamilies []*io_prometheus_client.MetricFamily, err error) {
+		for _, fs := range scrappers {
+			var iMetrics interface{}
+			if iMetrics, err = fs.GetMetric(ctx); err != nil {
+				log.WithError(err).Errorln("error scrapping fordwader metrics")
+				continue
+			}
+			fordwadedData, okFordwadedData := iMetrics.([]byte)
+			if !okFordwadedData {
+				log.WithField("val", iMetrics).Errorln("error asserting fordwader metrics data as []byte")
+				continue
+			}
+			var parser expfmt.TextParser
+			var families map[string]*io_prometheus_client.MetricFamily
+			if families, err = parser.TextToMetricFamilies(bytes.NewReader(fordwadedData)); err != nil {
+				log.WithError(err).Errorln("error, reading text format failed")
+				continue
+			}
+			for _, mf := range families {
+				metricFamilies = append(metricFamilies, mf)
 			}
 		}
-		return nil
-	}
-	var mergedMetricFamilies = make(map[string]*io_prometheus_client.MetricFamily)
-	if err = recordMetrics(mergedMetricFamilies, scrappedMetrics); err != nil {
-		log.WithError(err).Errorln("can not record scrapped metrics")
-		return mergedMetrics, config.ErrKeyDecodingFile
-	}
-	if err = recordMetrics(mergedMetricFamilies, fordwadedMetrics); err != nil {
-		log.WithError(err).Errorln("can not record fordwaded metrics")
-		return mergedMetrics, config.ErrKeyDecodingFile
+		// NOTE(denisacostaq@gmail.com): a failing forwader is logged, the metrics from the other ones are exposed
+		return metricFamilies, nil
+	})
+}
+
+// scrapeTimeoutOffset is subtracted from the scrape timeout sent by Prometheus to have time to encode and send
+// the response
+var scrapeTimeoutOffset = 500 * time.Millisecond
+
+// scrapeContext return a context with the deadline from the X-Prometheus-Scrape-Timeout-Seconds header, if the
+// header is not present the request context is used as is
+func scrapeContext(r *http.Request) (ctx context.Context, cancel context.CancelFunc) {
+	timeoutSeconds, err := strconv.ParseFloat(r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"), 64)
+	if err != nil || timeoutSeconds <= 0 {
+		return context.WithCancel(r.Context())
 	}
-	var buff bytes.Buffer
-	writer := bufio.NewWriter(&buff)
-	encoder := expfmt.NewEncoder(writer, expfmt.FmtText)
-	for _, mf := range mergedMetricFamilies {
-		if err := encoder.Encode(mf); err != nil {
-			log.WithFields(log.Fields{"err": err, "metric_family": mf}).Errorln("can not encode metric family")
-			return mergedMetrics, err
-		}
+	timeout := time.Duration(timeoutSeconds * float64(time.Second))
+	if timeout > 2*scrapeTimeoutOffset {
+		timeout -= scrapeTimeoutOffset
 	}
-	writer.Flush()
-	mergedMetrics = buff.Bytes()
-	return mergedMetrics, err
+	return context.WithTimeout(r.Context(), timeout)
 }
 
-func exposedMetricsMiddleware(listenAddr string, scrappers []scrapper.FordwaderScrapper, promHandler http.Handler) http.Handler {
+func exposedMetricsMiddleware(listenAddr string, collector *MetricsCollector, scrappers []scrapper.FordwaderScrapper) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if len(listenAddr) == 0 {
 			listenAddr = r.Host
 		}
-		getScrappedMetricFromAPI := func() (data []byte, err error) {
-			generalScopeErr := "error reding default data"
-			recorder := httptest.NewRecorder()
-			promHandler.ServeHTTP(recorder, r)
-			var reader io.ReadCloser
-			switch recorder.Header().Get("Content-Encoding") {
-			case "gzip":
-				reader, err = gzip.NewReader(recorder.Body)
-				if err != nil {
-					errCause := fmt.Sprintln("can not create gzip reader.", err.Error())
-					return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
-				}
-			default:
-				reader = ioutil.NopCloser(bytes.NewReader(recorder.Body.Bytes()))
-			}
-			defer reader.Close()
-			if data, err = ioutil.ReadAll(reader); err != nil {
-				log.WithError(err).Errorln("can not read recorded default data")
-				return nil, err
-			}
-			if recorder.Result().StatusCode != http.StatusOK {
-				log.WithFields(log.Fields{"code": recorder.Result().StatusCode, "status": recorder.Result().Status, "body": string(data)}).Errorln("invalid response from remote")
-				return nil, config.ErrKeyNotSuccessResponse
-			}
-			labeled, err := autolabelSelfMetrics(data, listenAddr)
-			if err != nil {
-				log.WithError(err).Errorln("Can not append default labels for self metric inside rextporter")
-				return nil, config.ErrKeyDecodingFile
-			}
-			return labeled, nil
-		}
-		var err error
-		var scrappedFromAPIData []byte
-		if scrappedFromAPIData, err = getScrappedMetricFromAPI(); err != nil {
-			log.WithError(err).Errorln("error getting data from API endpoints")
-		}
-		var allFordwadedData []byte
-		for _, fs := range scrappers {
-			var iMetrics interface{}
-			var err error
-			if iMetrics, err = fs.GetMetric(); err != nil {
-				log.WithError(err).Errorln("error scrapping fordwader metrics")
-			} else {
-				fordwadedData, okFordwadedData := iMetrics.([]byte)
-				if okFordwadedData {
-					allFordwadedData = append(allFordwadedData, fordwadedData...)
-				} else {
-					log.WithError(err).Errorln("error asserting fordwader metrics data as []byte")
-				}
-			}
-		}
-		var allData []byte
-		if allData, err = mergeMetrics(scrappedFromAPIData, allFordwadedData); err == nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusOK)
-			if count, err := w.Write(allData); err != nil || count != len(allData) {
-				if err != nil {
-					log.WithError(err).Errorln("error writing data")
-				}
-				if count != len(allData) {
-					log.WithFields(log.Fields{
-						"wrote":    count,
-						"required": len(allData),
-					}).Errorln("no enough content wrote")
-				}
-			}
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			log.WithError(err).Errorln("merge metrics")
+		ctx, cancel := scrapeContext(r)
+		defer cancel()
+		gatherers := prometheus.Gatherers{
+			collector.gatherer(ctx),
+			defaultMetricsGatherer(listenAddr),
+			fordwadedMetricsGatherer(ctx, scrappers),
 		}
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{
+			ErrorLog:      log.StandardLogger(),
+			ErrorHandling: promhttp.ContinueOnError,
+			// NOTE(denisacostaq@gmail.com): the response is compressed by the gzip handler
+			DisableCompression: true,
+		}).ServeHTTP(w, r)
 	})
 }
 
 // MustExportMetrics will read the config from mainConfigFile if any or use a default one.
 func MustExportMetrics(listenAddr, handlerEndpoint string, listenPort uint16, conf config.RextRoot) (srv *http.Server) {
+	// NOTE(denisacostaq@gmail.com): the clients of a previous config should not share state with the new ones
+	client.ResetSharedState()
 	c := cache.NewCache()
-	var collector prometheus.Collector
+	cDefMetrics := metrics.NewDefaultClientMetrics()
+	cDefMetrics.MustRegister()
+	var collector *MetricsCollector
 	var err error
-	if collector, err = newMetricsCollector(c, conf); err != nil {
+	if collector, err = newMetricsCollector(c, conf, cDefMetrics); err != nil {
 		log.WithError(err).Panicln("Can not create metrics")
 	}
 	prometheus.MustRegister(collector)
 	fDefMetrics := metrics.NewDefaultFordwaderMetrics()
 	fDefMetrics.MustRegister()
 	var metricsForwaders []scrapper.FordwaderScrapper
-	if metricsForwaders, err = createMetricsForwaders(conf, fDefMetrics); err != nil {
+	if metricsForwaders, err = createMetricsForwaders(conf, fDefMetrics, cDefMetrics); err != nil {
 		log.WithError(err).Panicln("Can not create forward_metrics metrics")
 	}
 	var listenAddrPort string
@@ -207,9 +153,10 @@ func MustExportMetrics(listenAddr, handlerEndpoint string, listenPort uint16, co
 		listenAddr = listenAddrPort
 	}
 	srv = &http.Server{Addr: listenAddrPort}
+	srv.RegisterOnShutdown(client.ResetSharedState)
 	http.Handle(
 		handlerEndpoint,
-		gziphandler.GzipHandler(exposedMetricsMiddleware(listenAddr, metricsForwaders, promhttp.Handler())))
+		gziphandler.GzipHandler(exposedMetricsMiddleware(listenAddr, collector, metricsForwaders)))
 	go func() {
 		log.Infoln(fmt.Sprintf("Starting server in %s, path %s ...", listenAddrPort, handlerEndpoint))
 		log.WithError(srv.ListenAndServe()).Errorln("unable to start the server")
@@ -13,15 +13,15 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func createMetricsForwaders(conf config.RextRoot, fDefMetrics *metrics.DefaultFordwaderMetrics) (fordwaderScrappers []scrapper.FordwaderScrapper, err error) {
+func createMetricsForwaders(conf config.RextRoot, fDefMetrics *metrics.DefaultFordwaderMetrics, cDefMetrics *metrics.DefaultClientMetrics) (fordwaderScrappers []scrapper.FordwaderScrapper, err error) {
 	generalScopeErr := "can not create metrics Middleware"
 	services := conf.GetServices()
 	for _, srvConf := range services {
 		var metricFordwaderCreator client.ProxyMetricClientCreator
 		resources := srvConf.GetResources()
 		for _, resConf := range resources {
-			if resConf.GetType() == "metrics_fordwader" {
-				if metricFordwaderCreator, err = client.CreateProxyMetricClientCreator(resConf, srvConf, fDefMetrics); err != nil {
+			if resConf.GetType() == config.ResourceTypeMetricsFordwader {
+				if metricFordwaderCreator, err = client.CreateProxyMetricClientCreator(resConf, srvConf, fDefMetrics, cDefMetrics); err != nil {
 					errCause := fmt.Sprintln("error creating metric client: ", err.Error())
 					return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
 				}
@@ -41,7 +41,7 @@ type constMetric struct {
 
 type endpointData2MetricsConsumer map[string][]constMetric
 
-func createMetrics(cache cache.Cache, conf config.RextRoot, dataSourceResponseDurationDesc *prometheus.Desc) (metrics endpointData2MetricsConsumer, err error) {
+func createMetrics(cache cache.Cache, conf config.RextRoot, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (metrics endpointData2MetricsConsumer, err error) {
 	generalScopeErr := "can not create metrics"
 	metrics = make(endpointData2MetricsConsumer)
 	for _, srvConf := range conf.GetServices() {
@@ -50,7 +50,7 @@ func createMetrics(cache cache.Cache, conf config.RextRoot, dataSourceResponseDu
 			var m constMetric
 			for _, mtrConf := range resConf.GetMetricDefs() {
 				nSolver := mtrConf.GetNodeSolver()
-				if m, err = createConstMetric(cache, resConf, srvConf, mtrConf, nSolver, dataSourceResponseDurationDesc); err != nil {
+				if m, err = createConstMetric(cache, resConf, srvConf, mtrConf, nSolver, dataSourceResponseDurationDesc, cDefMetrics); err != nil {
 					errCause := fmt.Sprintln(fmt.Sprintf("error creating metric client for %s metric of kind %s. ", mtrConf.GetMetricName(), mtrConf.GetMetricType()), err.Error())
 					return metrics, util.ErrorFromThisScope(errCause, generalScopeErr)
 				}
@@ -61,20 +61,46 @@ func createMetrics(cache cache.Cache, conf config.RextRoot, dataSourceResponseDu
 	return metrics, err
 }
 
-func createConstMetric(cache cache.Cache, resConf config.RextResourceDef, srvConf config.RextServiceDef, mtrConf config.RextMetricDef, nSolver config.RextNodeSolver, dataSourceResponseDurationDesc *prometheus.Desc) (metric constMetric, err error) {
+func createConstMetric(cache cache.Cache, resConf config.RextResourceDef, srvConf config.RextServiceDef, mtrConf config.RextMetricDef, nSolver config.RextNodeSolver, dataSourceResponseDurationDesc *prometheus.Desc, cDefMetrics *metrics.DefaultClientMetrics) (metric constMetric, err error) {
 	generalScopeErr := "can not create metric " + mtrConf.GetMetricName()
 	if len(mtrConf.GetMetricName()) == 0 {
 		log.Errorln("metric name is required")
 		return metric, config.ErrKeyEmptyValue
 	}
 	var ccf client.CacheableFactory
-	if ccf, err = client.CreateAPIRestCreator(resConf, srvConf, dataSourceResponseDurationDesc); err != nil {
+	createClientCreator := client.CreateAPIRestCreator
+	switch resConf.GetType() {
+	case config.ResourceTypeExec:
+		createClientCreator = client.CreateExecCreator
+	case config.ResourceTypeJSONRPC:
+		createClientCreator = client.CreateJSONRPCCreator
+	case config.ResourceTypeGraphQL:
+		createClientCreator = client.CreateGraphQLCreator
+	case config.ResourceTypeStream:
+		createClientCreator = client.CreateStreamCreator
+	case config.ResourceTypeTCP:
+		createClientCreator = client.CreateTCPCreator
+	case config.ResourceTypeSQL:
+		createClientCreator = client.CreateSQLCreator
+	case config.ResourceTypeFile:
+		createClientCreator = client.CreateFileCreator
+	default:
+		if srvConf.GetProtocol() == config.ProtocolFile {
+			createClientCreator = client.CreateFileCreator
+		}
+	}
+	if ccf, err = createClientCreator(resConf, srvConf, dataSourceResponseDurationDesc, cDefMetrics); err != nil {
 		errCause := fmt.Sprintln("error creating metric client: ", err.Error())
 		return metric, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
 	cc := client.CatcherCreator{Cache: cache, ClientFactory: ccf}
 	var numScrapper scrapper.Scrapper
-	if numScrapper, err = scrapper.NewScrapper(cc, scrapper.JSONParser{}, resConf, srvConf, mtrConf, nSolver); err != nil {
+	var parser scrapper.BodyParser
+	if parser, err = scrapper.NewBodyParser(resConf.GetDecoder(), nSolver); err != nil {
+		errCause := fmt.Sprintln("error creating metric parser: ", err.Error())
+		return metric, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if numScrapper, err = scrapper.NewScrapper(cc, parser, resConf, srvConf, mtrConf, nSolver); err != nil {
 		errCause := fmt.Sprintln("error creating metric client: ", err.Error())
 		return metric, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
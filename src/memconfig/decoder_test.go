@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/simelo/rextporter/src/config"
+	// NOTE(denisacostaq@gmail.com): register the built in decoders and node solvers used in the validations
+	_ "github.com/simelo/rextporter/src/scrapper"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -22,7 +24,7 @@ type decoderSuit struct {
 }
 
 func (suite *decoderSuit) SetupTest() {
-	suite.decoderType = "dfdf"
+	suite.decoderType = config.DecoderJSON
 	suite.options = NewOptionsMap()
 	_, err := suite.options.SetString("k1", "v1")
 	suite.Nil(err)
@@ -84,3 +86,37 @@ func (suite *decoderSuit) TestValidationTypeShouldNotBeEmpty() {
 	// NOTE(denisacostaq@gmail.com): Assert
 	suite.True(hasError)
 }
+
+func (suite *decoderSuit) TestValidationTypeShouldBeRegistered() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	decoderDef := NewDecoder("dfdf", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	hasError := decoderDef.Validate()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.True(hasError)
+}
+
+func (suite *decoderSuit) TestValidationCSVOptions() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	valid := NewDecoder(config.DecoderTSV, NewOptionsMap())
+	_, err := valid.GetOptions().SetString(config.OptKeyRextDecoderCSVHeader, config.CSVHeaderNone)
+	suite.Nil(err)
+	badDelimiter := NewDecoder(config.DecoderCSV, NewOptionsMap())
+	_, err = badDelimiter.GetOptions().SetString(config.OptKeyRextDecoderCSVDelimiter, ";;")
+	suite.Nil(err)
+	badHeader := NewDecoder(config.DecoderCSV, NewOptionsMap())
+	_, err = badHeader.GetOptions().SetString(config.OptKeyRextDecoderCSVHeader, "yes")
+	suite.Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	validHasError := valid.Validate()
+	badDelimiterHasError := badDelimiter.Validate()
+	badHeaderHasError := badHeader.Validate()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(validHasError)
+	suite.True(badDelimiterHasError)
+	suite.True(badHeaderHasError)
+}
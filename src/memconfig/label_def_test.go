@@ -24,7 +24,7 @@ type labelDefConfSuit struct {
 
 func (suite *labelDefConfSuit) SetupTest() {
 	suite.name = "MySupperLabel"
-	suite.nodeSolver = NewNodeSolver("tr", "pat", NewOptionsMap())
+	suite.nodeSolver = NewNodeSolver(config.RextNodeSolverTypeJSONPath, "pat", NewOptionsMap())
 	suite.labelDef = newLabelDef(suite)
 }
 
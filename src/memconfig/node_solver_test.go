@@ -93,3 +93,53 @@ func (suite *nodeSolverSuit) TestValidationTypeShouldNotBeEmpty() {
 	// NOTE(denisacostaq@gmail.com): Assert
 	suite.True(hasError)
 }
+
+func (suite *nodeSolverSuit) TestValidationTypeShouldBeRegistered() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	nodeSolver := NewNodeSolver("tr", "/blockchain/head/seq", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	hasError := nodeSolver.Validate()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.True(hasError)
+}
+
+func (suite *nodeSolverSuit) TestValidationEmptyTypeUseTheDecoderDefault() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	nodeSolver := NewNodeSolver("", "/blockchain/head/seq", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	hasError := nodeSolver.Validate()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(hasError)
+}
+
+func (suite *nodeSolverSuit) TestValidationPathShouldBeValidForTheNodeSolver() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	validNodeSolver := NewNodeSolver(config.RextNodeSolverTypePrometheus, `process_open_fds{job="skycoin"}`, nil)
+	invalidNodeSolver := NewNodeSolver(config.RextNodeSolverTypePrometheus, `process_open_fds{job=skycoin}`, nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	validHasError := validNodeSolver.Validate()
+	invalidHasError := invalidNodeSolver.Validate()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(validHasError)
+	suite.True(invalidHasError)
+}
+
+func (suite *nodeSolverSuit) TestValidationJQExpressionShouldCompile() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	validNodeSolver := NewNodeSolver(config.RextNodeSolverTypeJQ, ".connections[] | select(.outgoing) | .height", nil)
+	invalidNodeSolver := NewNodeSolver(config.RextNodeSolverTypeJQ, ".connections[] | select(.outgoing", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	validHasError := validNodeSolver.Validate()
+	invalidHasError := invalidNodeSolver.Validate()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(validHasError)
+	suite.True(invalidHasError)
+}
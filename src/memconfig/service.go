@@ -19,7 +19,7 @@ type Service struct {
 
 // Validate the service, return true if any error is found
 func (srv Service) Validate() (hasError bool) {
-	if srv.GetProtocol() == "http" {
+	if srv.GetProtocol() == "http" || srv.GetProtocol() == config.ProtocolHTTPUnix {
 		for _, res := range srv.GetResources() {
 			resPath := res.GetResourcePATH(srv.GetBasePath())
 			if !util.IsValidURL(resPath) {
@@ -0,0 +1,145 @@
+package scrapper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/cache"
+	"github.com/simelo/rextporter/src/client"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+)
+
+// skycoinConnections return a /api/v1/network/connections response like the fake skycoin node with n connections
+func skycoinConnections(n int) string {
+	connections := make([]string, n)
+	for idx := range connections {
+		connections[idx] = fmt.Sprintf(`{
+			"id": %d,
+			"address": "139.162.%d.%d:6000",
+			"last_sent": 1520675750,
+			"last_received": 1520675750,
+			"connected_at": 1520675500,
+			"outgoing": %t,
+			"state": "introduced",
+			"mirror": 1338939619,
+			"listen_port": 6000,
+			"height": %d,
+			"user_agent": "skycoin:0.25.0",
+			"is_trusted_peer": true,
+			"unconfirmed_verify_transaction": {"burn_factor": %d, "max_transaction_size": 32768, "max_decimals": 3}
+		}`, 99107+idx, idx/256, idx%256, idx%2 == 0, idx%200, idx%4)
+	}
+	return `{"connections": [` + strings.Join(connections, ",") + `]}`
+}
+
+// plainClient return the same body always, it can not share the decoded document
+type plainClient struct {
+	body []byte
+}
+
+func (cl plainClient) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) ([]byte, error) {
+	return cl.body, nil
+}
+
+func (cl plainClient) CreateClient() (client.Client, error) {
+	return cl, nil
+}
+
+// cacheablePlainClient is a plainClient to be used with a client.Catcher
+type cacheablePlainClient struct {
+	plainClient
+}
+
+func (cl cacheablePlainClient) DataPath() string {
+	return "http://localhost:6420/bench"
+}
+
+func (cl cacheablePlainClient) CreateClient() (client.CacheableClient, error) {
+	return cl, nil
+}
+
+// skycoinConnectionsScrappers create the vector and histogram metrics in the skycoin integration config
+func skycoinConnectionsScrappers(b *testing.B, cf client.Factory) (scrappers []Scrapper) {
+	parser, err := NewBodyParser(memconfig.NewDecoder(config.DecoderJSON, nil), memconfig.NewNodeSolver("", "/connections/height", nil))
+	if err != nil {
+		b.Fatal(err)
+	}
+	paths := []string{
+		"/connections/height",
+		"/connections/unconfirmed_verify_transaction/burn_factor",
+		"/connections/unconfirmed_verify_transaction/max_transaction_size",
+		"/connections/unconfirmed_verify_transaction/max_decimals",
+	}
+	for _, path := range paths {
+		mtrConf := &memconfig.MetricDef{}
+		mtrConf.SetMetricType(config.KeyMetricTypeGauge)
+		mtrConf.AddLabel(memconfig.NewLabelDef("address", memconfig.NewNodeSolver(config.RextNodeSolverTypeJSONPath, "/connections/address", nil)))
+		nSolver := memconfig.NewNodeSolver(config.RextNodeSolverTypeJSONPath, path, nil)
+		scrappers = append(scrappers, newNumericVec(cf, parser, []config.NodeLookup{JSONParser{}}, "skycoin", "localhost:6420", "/api/v1/network/connections", nSolver, mtrConf))
+	}
+	scrappers = append(scrappers, newHistogram(cf, parser, "/api/v1/network/connections", "skycoin", "localhost:6420", "/connections/unconfirmed_verify_transaction/burn_factor", histogramClientOptions{1, 2, 3}))
+	return scrappers
+}
+
+// skycoinHealthScrappers create the numeric metrics in the skycoin integration config for /api/v1/health
+func skycoinHealthScrappers(b *testing.B, cf client.Factory) (scrappers []Scrapper) {
+	parser, err := NewBodyParser(memconfig.NewDecoder(config.DecoderJSON, nil), memconfig.NewNodeSolver("", "/blockchain/head/seq", nil))
+	if err != nil {
+		b.Fatal(err)
+	}
+	paths := []string{"/blockchain/head/seq", "/blockchain/head/fee", "/blockchain/unspents", "/blockchain/unconfirmed", "/open_connections"}
+	for _, path := range paths {
+		scrappers = append(scrappers, newNumeric(cf, parser, path, "skycoin", "localhost:6420", "/api/v1/health"))
+	}
+	return scrappers
+}
+
+// benchmarkScrape run a scrape of all the scrappers in each iteration, the body is decoded for each metric with
+// the plainClient and once per scrape with a client.Catcher
+func benchmarkScrape(b *testing.B, body string, createScrappers func(b *testing.B, cf client.Factory) []Scrapper) {
+	benchmarks := []struct {
+		name  string
+		cache cache.Cache
+	}{
+		{name: "DecodedPerMetric"},
+		{name: "SharedDocument", cache: cache.NewCache()},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var cf client.Factory = plainClient{body: []byte(body)}
+			if bm.cache != nil {
+				cf = client.CatcherCreator{Cache: bm.cache, ClientFactory: cacheablePlainClient{plainClient{body: []byte(body)}}}
+			}
+			scrappers := createScrappers(b, cf)
+			metricsCollector := make(chan prometheus.Metric, 10)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, s := range scrappers {
+					if _, err := s.GetMetric(context.Background(), metricsCollector); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if bm.cache != nil {
+					bm.cache.Reset()
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkScrapeSkycoinHealth(b *testing.B) {
+	benchmarkScrape(b, skycoinHealth, skycoinHealthScrappers)
+}
+
+func BenchmarkScrapeSkycoinConnections(b *testing.B) {
+	for _, n := range []int{3, 100, 1000} {
+		b.Run(fmt.Sprintf("%dConnections", n), func(b *testing.B) {
+			benchmarkScrape(b, skycoinConnections(n), skycoinConnectionsScrappers)
+		})
+	}
+}
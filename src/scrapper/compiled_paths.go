@@ -0,0 +1,39 @@
+package scrapper
+
+import (
+	"sync"
+)
+
+// compiledPaths keep the paths compiled by a node solver, so a path is compiled once when the config is loaded
+// and shared by all the metrics and scrapes using it
+type compiledPaths struct {
+	mutex   *sync.RWMutex
+	paths   map[string]interface{}
+	compile func(path string) (compiled interface{}, err error)
+}
+
+func newCompiledPaths(compile func(path string) (compiled interface{}, err error)) *compiledPaths {
+	return &compiledPaths{
+		mutex:   &sync.RWMutex{},
+		paths:   make(map[string]interface{}),
+		compile: compile,
+	}
+}
+
+// get return the compiled path, it is compiled if it is not found. The errors are not kept, so an invalid path is
+// reported every time.
+func (c *compiledPaths) get(path string) (compiled interface{}, err error) {
+	c.mutex.RLock()
+	compiled, found := c.paths[path]
+	c.mutex.RUnlock()
+	if found {
+		return compiled, nil
+	}
+	if compiled, err = c.compile(path); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	c.paths[path] = compiled
+	c.mutex.Unlock()
+	return compiled, nil
+}
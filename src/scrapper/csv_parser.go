@@ -0,0 +1,102 @@
+package scrapper
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+)
+
+// CSVParser is a body parser for comma(or tab) separated values, the rows are decoded in an object like
+// {"rows": [{"column": value}], "row_count": 1}, so the values in a column can be found with paths like
+// "/rows/column"
+type CSVParser struct {
+	JSONParser
+	delimiter     rune
+	commentPrefix string
+	header        string
+}
+
+// NewCSVParser create a CSVParser with the settings in the decoder options, delimiter is used if not present
+func NewCSVParser(delimiter rune, decoderOpts config.RextKeyValueStore) CSVParser {
+	p := CSVParser{delimiter: delimiter, header: config.CSVHeaderAuto}
+	if decoderOpts != nil {
+		if val, err := decoderOpts.GetString(config.OptKeyRextDecoderCSVDelimiter); err == nil && len([]rune(val)) == 1 {
+			p.delimiter = []rune(val)[0]
+		}
+		p.commentPrefix, _ = decoderOpts.GetString(config.OptKeyRextDecoderCSVCommentPrefix)
+		if val, err := decoderOpts.GetString(config.OptKeyRextDecoderCSVHeader); err == nil {
+			p.header = val
+		}
+	}
+	return p
+}
+
+// csvValue decode a field as float64 if it is a number, as string otherwise
+func csvValue(field string) interface{} {
+	if num, err := strconv.ParseFloat(strings.TrimSpace(field), 64); err == nil {
+		return num
+	}
+	return field
+}
+
+// isCSVHeader return true if the record should be used as the header
+func (p CSVParser) isCSVHeader(record []string) bool {
+	switch p.header {
+	case config.CSVHeaderFirstRow:
+		return true
+	case config.CSVHeaderNone:
+		return false
+	}
+	for _, field := range record {
+		if _, isNumber := csvValue(field).(float64); isNumber {
+			return false
+		}
+	}
+	return true
+}
+
+// DecodeBody decode the records, the empty lines and the lines starting with the comment prefix are ignored. The
+// columns without a name in the header are named by position like column1, column2 ...
+func (p CSVParser) DecodeBody(body []byte) (val interface{}, err error) {
+	generalScopeErr := "error decoding body as csv"
+	if len(p.commentPrefix) != 0 {
+		var content bytes.Buffer
+		for _, line := range strings.SplitAfter(string(body), "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), p.commentPrefix) {
+				content.WriteString(line)
+			}
+		}
+		body = content.Bytes()
+	}
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.Comma = p.delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = p.delimiter == '\t'
+	var records [][]string
+	if records, err = reader.ReadAll(); err != nil {
+		errCause := fmt.Sprintf("can not decode the body: %s. Err: %s", string(body), err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	var header []string
+	if len(records) != 0 && p.isCSVHeader(records[0]) {
+		header, records = records[0], records[1:]
+	}
+	rows := make([]interface{}, len(records))
+	for idxRecord, record := range records {
+		row := make(map[string]interface{}, len(record))
+		for idx, field := range record {
+			column := fmt.Sprintf("column%d", idx+1)
+			if idx < len(header) && len(strings.TrimSpace(header[idx])) != 0 {
+				column = strings.TrimSpace(header[idx])
+			}
+			row[column] = csvValue(field)
+		}
+		rows[idxRecord] = row
+	}
+	return map[string]interface{}{"rows": rows, "row_count": float64(len(rows))}, nil
+}
@@ -0,0 +1,121 @@
+package scrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+const csvJobs = `# exported by the batch system
+queue,pending,duration
+default,12,0.5
+reports,3,120
+
+# end of report
+`
+
+type csvParserSuit struct {
+	suite.Suite
+}
+
+func TestCSVParserSuit(t *testing.T) {
+	suite.Run(t, new(csvParserSuit))
+}
+
+func (suite *csvParserSuit) parser(delimiter rune, opts map[string]string) CSVParser {
+	decoderOpts := memconfig.NewOptionsMap()
+	for key, val := range opts {
+		_, err := decoderOpts.SetString(key, val)
+		suite.Nil(err)
+	}
+	return NewCSVParser(delimiter, decoderOpts)
+}
+
+func (suite *csvParserSuit) TestHeaderDetection() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	p := suite.parser(',', map[string]string{config.OptKeyRextDecoderCSVCommentPrefix: "#"})
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(csvJobs))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"queue": "default", "pending": float64(12), "duration": 0.5},
+			map[string]interface{}{"queue": "reports", "pending": float64(3), "duration": float64(120)},
+		},
+		"row_count": float64(2),
+	}, val)
+}
+
+func (suite *csvParserSuit) TestWithoutHeader() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "default\t12\nreports\t3\n"
+	p := suite.parser('\t', nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(body))
+	pending, errLookup := p.PathLookup("/rows/column2", val)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Nil(errLookup)
+	suite.Equal([]interface{}{float64(12), float64(3)}, pending)
+}
+
+func (suite *csvParserSuit) TestHeaderSettingAndDelimiter() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "queue;1h;24h\ndefault;12;300\n"
+	p := suite.parser(',', map[string]string{
+		config.OptKeyRextDecoderCSVDelimiter: ";",
+		config.OptKeyRextDecoderCSVHeader:    config.CSVHeaderFirstRow,
+	})
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(body))
+	lastDay, errLookup := p.PathLookup("/rows/24h", val)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Nil(errLookup)
+	suite.Equal([]interface{}{float64(300)}, lastDay)
+}
+
+func (suite *csvParserSuit) TestInvalidBody() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "queue,pending\n\"default,12\n"
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := suite.parser(',', nil).DecodeBody([]byte(body))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Nil(val)
+}
+
+func (suite *csvParserSuit) TestNumericVecAndHistogram() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	p := suite.parser(',', map[string]string{config.OptKeyRextDecoderCSVCommentPrefix: "#"})
+	nSolver := memconfig.NewNodeSolver(config.RextNodeSolverTypeJSONPath, "/rows/pending", nil)
+	label := memconfig.NewLabelDef("queue", memconfig.NewNodeSolver(config.RextNodeSolverTypeJSONPath, "/rows/queue", nil))
+	mtrConf := &memconfig.MetricDef{}
+	mtrConf.SetMetricType(config.KeyMetricTypeGauge)
+	mtrConf.AddLabel(label)
+	vec := newNumericVec(xmlClient{body: csvJobs}, p, []config.NodeLookup{p}, "batch", "localhost", "/jobs.csv", nSolver, mtrConf)
+	histogram := newHistogram(xmlClient{body: csvJobs}, p, "/jobs.csv", "batch", "localhost", "/rows/duration", histogramClientOptions{1, 60})
+
+	// NOTE(denisacostaq@gmail.com): When
+	vecVal, errVec := vec.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+	histogramVal, errHistogram := histogram.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(errVec)
+	suite.Equal(NumericVecVals{{Val: 12, Labels: []string{"default"}}, {Val: 3, Labels: []string{"reports"}}}, vecVal)
+	suite.Nil(errHistogram)
+	suite.Equal(HistogramValue{Count: 2, Sum: 120.5, Buckets: map[float64]uint64{1: 1, 60: 1}}, histogramVal)
+}
@@ -0,0 +1,123 @@
+package scrapper
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+)
+
+func init() {
+	config.RegisterDecoder(config.DecoderJSON, config.RextNodeSolverTypeJSONPath, func(config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return JSONParser{}, nil
+	})
+	config.RegisterDecoder(config.DecoderText, config.RextNodeSolverTypeJSONPath, func(decoderOpts config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return NewTextParser(decoderOpts), nil
+	})
+	config.RegisterDecoder(config.DecoderXML, config.RextNodeSolverTypeXPath, func(config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return XMLParser{}, nil
+	})
+	config.RegisterDecoder(config.DecoderYAML, config.RextNodeSolverTypeJSONPath, func(config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return YAMLParser{}, nil
+	})
+	config.RegisterDecoder(config.DecoderCSV, config.RextNodeSolverTypeJSONPath, func(decoderOpts config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return NewCSVParser(',', decoderOpts), nil
+	})
+	config.RegisterDecoder(config.DecoderTSV, config.RextNodeSolverTypeJSONPath, func(decoderOpts config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return NewCSVParser('\t', decoderOpts), nil
+	})
+	config.RegisterDecoder(config.DecoderPlainText, config.RextNodeSolverTypeLine, func(config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return PlainTextParser{}, nil
+	})
+	config.RegisterDecoder(config.DecoderINI, config.RextNodeSolverTypeJSONPath, func(decoderOpts config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return NewINIParser(decoderOpts), nil
+	})
+	config.RegisterDecoder(config.DecoderPrometheus, config.RextNodeSolverTypePrometheus, func(config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return PrometheusParser{}, nil
+	})
+	config.RegisterNodeSolver(config.RextNodeSolverTypeJSONPath, func(config.RextKeyValueStore) (config.NodeLookup, error) {
+		return JSONParser{}, nil
+	})
+	config.RegisterNodeSolver(config.RextNodeSolverTypeXPath, func(config.RextKeyValueStore) (config.NodeLookup, error) {
+		return XMLParser{}, nil
+	})
+	config.RegisterNodeSolver(config.RextNodeSolverTypeRegex, func(nodeSolverOpts config.RextKeyValueStore) (config.NodeLookup, error) {
+		return NewRegexSolver(nodeSolverOpts), nil
+	})
+	config.RegisterNodeSolver(config.RextNodeSolverTypeLine, func(config.RextKeyValueStore) (config.NodeLookup, error) {
+		return LineSolver{}, nil
+	})
+	config.RegisterNodeSolver(config.RextNodeSolverTypePrometheus, func(config.RextKeyValueStore) (config.NodeLookup, error) {
+		return PrometheusParser{}, nil
+	})
+	config.RegisterNodeSolver(config.RextNodeSolverTypeJQ, func(config.RextKeyValueStore) (config.NodeLookup, error) {
+		return JQSolver{}, nil
+	})
+}
+
+// registeredParser decode the body with the resource decoder and find the nodes with the metric node solver
+type registeredParser struct {
+	config.BodyDecoder
+	config.NodeLookup
+	decoderKey string
+}
+
+// DecoderKey identify the decoder type and options, the metrics with the same decoder key share the decoded body
+func (p registeredParser) DecoderKey() string {
+	return p.decoderKey
+}
+
+// decoderKey return a key for the decoder type and options
+func decoderKey(decoderDef config.RextDecoderDef) string {
+	key := decoderDef.GetType()
+	if decoderDef.GetOptions() == nil {
+		return key
+	}
+	optKeys := decoderDef.GetOptions().GetKeys()
+	sort.Strings(optKeys)
+	for _, optKey := range optKeys {
+		val, _ := decoderDef.GetOptions().GetObject(optKey)
+		key += fmt.Sprintf("|%s=%v", optKey, val)
+	}
+	return key
+}
+
+// parserDecoderKey return the key for the decoder used by the parser, the metrics with the same decoder key share
+// the decoded body
+func parserDecoderKey(p BodyParser) string {
+	if keyer, isKeyer := p.(interface{ DecoderKey() string }); isKeyer {
+		return keyer.DecoderKey()
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// precompilePath check and compile the path if the node solver support it, so it is compiled once when the config
+// is loaded, see config.PathValidator
+func precompilePath(lookup config.NodeLookup, path string) error {
+	if validator, isValidator := lookup.(config.PathValidator); isValidator {
+		return validator.ValidatePath(path)
+	}
+	return nil
+}
+
+// NewBodyParser create a parser with the registered decoder for decoderDef and the registered node solver for
+// nSolver, see config.RegisterDecoder and config.RegisterNodeSolver
+func NewBodyParser(decoderDef config.RextDecoderDef, nSolver config.RextNodeSolver) (parser BodyParser, err error) {
+	const generalScopeErr = "error creating a body parser"
+	var p registeredParser
+	if p.BodyDecoder, err = config.NewBodyDecoder(decoderDef); err != nil {
+		errCause := fmt.Sprintln("can not create the decoder: ", err.Error())
+		return parser, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if p.NodeLookup, err = config.NewNodeLookup(decoderDef.GetType(), nSolver); err != nil {
+		errCause := fmt.Sprintln("can not create the node solver: ", err.Error())
+		return parser, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if err = precompilePath(p.NodeLookup, nSolver.GetNodePath()); err != nil {
+		errCause := fmt.Sprintln("can not compile the path: ", err.Error())
+		return parser, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	p.decoderKey = decoderKey(decoderDef)
+	return p, nil
+}
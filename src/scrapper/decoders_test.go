@@ -0,0 +1,118 @@
+package scrapper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+// upperDecoder is an in-house format for the tests, the body is a single upper case word
+type upperDecoder struct{}
+
+func (d upperDecoder) DecodeBody(body []byte) (interface{}, error) {
+	return string(body), nil
+}
+
+func (d upperDecoder) PathLookup(path string, doc interface{}) (interface{}, error) {
+	if path != "/word" {
+		return nil, errors.New("unknown path")
+	}
+	return doc, nil
+}
+
+func init() {
+	config.RegisterDecoder("upper", "upper", func(config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return upperDecoder{}, nil
+	})
+	config.RegisterNodeSolver("upper", func(config.RextKeyValueStore) (config.NodeLookup, error) {
+		return upperDecoder{}, nil
+	})
+}
+
+type decodersSuit struct {
+	suite.Suite
+}
+
+func TestDecodersSuit(t *testing.T) {
+	suite.Run(t, new(decodersSuit))
+}
+
+func (suite *decodersSuit) TestBuiltInTypes() {
+	// NOTE(denisacostaq@gmail.com): Giving
+
+	// NOTE(denisacostaq@gmail.com): When
+	decoderTypes := config.DecoderTypes()
+	nodeSolverTypes := config.NodeSolverTypes()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Subset(decoderTypes, []string{config.DecoderJSON, config.DecoderText, config.DecoderXML})
+	suite.Subset(nodeSolverTypes, []string{config.RextNodeSolverTypeJSONPath, config.RextNodeSolverTypeXPath})
+}
+
+func (suite *decodersSuit) TestDefaultNodeSolver() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	decoderDef := memconfig.NewDecoder(config.DecoderXML, nil)
+	nSolver := memconfig.NewNodeSolver("", "//connection/@address", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	parser, err := NewBodyParser(decoderDef, nSolver)
+	suite.Require().Nil(err)
+	doc, errDecode := parser.DecodeBody([]byte(xmlHealth))
+	node, errLookup := parser.PathLookup(nSolver.GetNodePath(), doc)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(errDecode)
+	suite.Nil(errLookup)
+	suite.Equal([]interface{}{"139.162.161.41:20002", "185.120.34.60:6000", "172.104.85.6:6000"}, node)
+}
+
+func (suite *decodersSuit) TestInHouseFormat() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	decoderDef := memconfig.NewDecoder("upper", nil)
+	nSolver := memconfig.NewNodeSolver("upper", "/word", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	parser, err := NewBodyParser(decoderDef, nSolver)
+	suite.Require().Nil(err)
+	doc, errDecode := parser.DecodeBody([]byte("READY"))
+	node, errLookup := parser.PathLookup(nSolver.GetNodePath(), doc)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(errDecode)
+	suite.Nil(errLookup)
+	suite.Equal("READY", node)
+	suite.False(decoderDef.Validate())
+	suite.False(nSolver.Validate())
+}
+
+func (suite *decodersSuit) TestUnknownTypes() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	unknownDecoder := memconfig.NewDecoder("rar", nil)
+	unknownNodeSolver := memconfig.NewNodeSolver("jmespath", "/a", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, errDecoder := NewBodyParser(unknownDecoder, memconfig.NewNodeSolver("", "/a", nil))
+	_, errNodeSolver := NewBodyParser(memconfig.NewDecoder(config.DecoderJSON, nil), unknownNodeSolver)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(errDecoder)
+	suite.NotNil(errNodeSolver)
+}
+
+func (suite *decodersSuit) TestRegisterTwicePanics() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	factory := func(config.RextKeyValueStore) (config.BodyDecoder, error) {
+		return JSONParser{}, nil
+	}
+
+	// NOTE(denisacostaq@gmail.com): When
+	register := func() {
+		config.RegisterDecoder(config.DecoderJSON, config.RextNodeSolverTypeJSONPath, factory)
+	}
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Panics(register)
+}
@@ -1,6 +1,7 @@
 package scrapper
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -34,15 +35,18 @@ func newHistogram(cf client.Factory, parser BodyParser, datasource, jobName, ins
 }
 
 // GetMetric return a histogram metrics val
-func (h Histogram) GetMetric(metricsCollector chan<- prometheus.Metric) (val interface{}, err error) {
+func (h Histogram) GetMetric(ctx context.Context, metricsCollector chan<- prometheus.Metric) (val interface{}, err error) {
 	const generalScopeErr = "error scrapping histogram metric"
 	var iBody interface{}
-	if iBody, err = getData(h.clientFactory, h.parser, metricsCollector); err != nil {
+	if iBody, err = getData(ctx, h.clientFactory, h.parser, metricsCollector); err != nil {
+		if isTypedClientError(err) {
+			return val, err
+		}
 		errCause := "histogram client can not decode the body"
 		return val, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
 	var iVal interface{}
-	if iVal, err = h.parser.pathLookup(h.jsonPath, iBody); err != nil {
+	if iVal, err = h.parser.PathLookup(h.jsonPath, iBody); err != nil {
 		errCause := fmt.Sprintln("can not get node: ", err.Error())
 		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
@@ -76,11 +80,7 @@ func newHistogramValue(buckets []float64) HistogramValue {
 
 func createHistogramValueFromData(buckets []float64, data interface{}) (histogram HistogramValue, err error) {
 	generalScopeErr := "creating histogram from data"
-	collection, okCollection := data.([]interface{})
-	if !okCollection {
-		errCause := fmt.Sprintln("can not assert the metric type as slice")
-		return histogram, util.ErrorFromThisScope(errCause, generalScopeErr)
-	}
+	collection := nodeCollection(data)
 	histogram = newHistogramValue(buckets)
 	for _, item := range collection {
 		histogram.Count++
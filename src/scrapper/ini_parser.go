@@ -0,0 +1,68 @@
+package scrapper
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/simelo/rextporter/src/config"
+)
+
+// INIParser is a body parser for the "key=value" files like the ini ones or some /proc files, each key is decoded
+// as a field in an object and each section as an object, so the values can be found with paths like
+// "/section/key" or "/key" for the keys before any section
+type INIParser struct {
+	JSONParser
+	separator string
+}
+
+// NewINIParser create an INIParser with the separator in the decoder options, "=" if not present
+func NewINIParser(decoderOpts config.RextKeyValueStore) INIParser {
+	p := INIParser{separator: "="}
+	if decoderOpts != nil {
+		if separator, err := decoderOpts.GetString(config.OptKeyRextDecoderTextSeparator); err == nil && len(separator) != 0 {
+			p.separator = separator
+		}
+	}
+	return p
+}
+
+// iniValue decode a value as float64 if it is a number, a number followed by a unit like "16334 kB" is decoded
+// as the number too
+func iniValue(val string) interface{} {
+	if fields := strings.Fields(val); len(fields) == 2 {
+		if num, err := strconv.ParseFloat(fields[0], 64); err == nil {
+			return num
+		}
+	}
+	return textValue(val)
+}
+
+// DecodeBody decode each line in a field of the last section, the empty lines, the comments(starting with '#' or
+// ';') and the lines without a separator are ignored
+func (p INIParser) DecodeBody(body []byte) (val interface{}, err error) {
+	fields := make(map[string]interface{})
+	section := fields
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			var okSection bool
+			if section, okSection = fields[name].(map[string]interface{}); !okSection {
+				section = make(map[string]interface{})
+				fields[name] = section
+			}
+			continue
+		}
+		idx := strings.Index(line, p.separator)
+		if idx <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:idx])
+		strVal := strings.Trim(strings.TrimSpace(line[idx+len(p.separator):]), "\"")
+		section[key] = iniValue(strVal)
+	}
+	return fields, nil
+}
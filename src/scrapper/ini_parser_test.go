@@ -0,0 +1,61 @@
+package scrapper
+
+import (
+	"testing"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+type iniParserSuit struct {
+	suite.Suite
+}
+
+func TestINIParserSuit(t *testing.T) {
+	suite.Run(t, new(iniParserSuit))
+}
+
+func (suite *iniParserSuit) TestSections() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := `; node settings
+version = 0.24.1
+[network]
+port=6000
+max_connections = 128
+# wallet
+[wallet]
+dir = "/var/lib/skycoin"
+`
+	p := NewINIParser(nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(body))
+	maxConnections, errLookup := p.PathLookup("/network/max_connections", val)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Nil(errLookup)
+	suite.Equal(float64(128), maxConnections)
+	suite.Equal(map[string]interface{}{
+		"version": "0.24.1",
+		"network": map[string]interface{}{"port": float64(6000), "max_connections": float64(128)},
+		"wallet":  map[string]interface{}{"dir": "/var/lib/skycoin"},
+	}, val)
+}
+
+func (suite *iniParserSuit) TestProcFile() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "MemTotal:       16334712 kB\nMemFree:         1032624 kB\nHugePages_Total:       0\n"
+	opts := memconfig.NewOptionsMap()
+	_, err := opts.SetString(config.OptKeyRextDecoderTextSeparator, ":")
+	suite.Nil(err)
+	p := NewINIParser(opts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(body))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(map[string]interface{}{"MemTotal": float64(16334712), "MemFree": float64(1032624), "HugePages_Total": float64(0)}, val)
+}
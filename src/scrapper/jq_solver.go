@@ -0,0 +1,1133 @@
+package scrapper
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// jqFilter produce the output stream of a jq expression for an input
+type jqFilter func(input interface{}) (outputs []interface{}, err error)
+
+// JQSolver find the nodes in a json like document with a jq expression like
+// `.connections[] | select(.outgoing == true) | .height`, every value in the output stream is a node. The supported
+// subset is:
+//   - the `|` and `,` operators and the parentheses
+//   - the paths `.`, `..`, `.a.b`, `."a-b"`, `.["a-b"]`, `.a[0]`, `.a[-1]`, `.a[]` and the slices `.a[1:]`,
+//     `.a[:2]` and `.a[1:2]`, the field names can have any unicode letter, digit or `_`
+//   - the literals: numbers, strings without interpolation, `true`, `false`, `null` and the array construction `[...]`
+//   - the `//` alternative, `or`, `and`, `==`, `!=`, `<`, `<=`, `>`, `>=`, `+`, `-`, `*` and `/` operators
+//   - the `if ... then ... elif ... else ... end` conditionals, the `else` branch can be omitted
+//   - the functions select(f), map(f), has(key), empty, not, length, keys, values, add, min, max, first, last, sort,
+//     reverse, to_entries, tonumber, tostring, type and the type selectors numbers, strings, booleans, nulls, arrays
+//     and objects
+//
+// Anything else(object construction, `?`, `%`, variables, `as`, `reduce`, `try`, `def`, assignments, formats like
+// `@csv`, the string interpolation, ...) is rejected by ValidatePath.
+type JQSolver struct {
+}
+
+// jqPrograms are the compiled jq expressions
+var jqPrograms = newCompiledPaths(func(expr string) (interface{}, error) {
+	return compileJQExpr(expr)
+})
+
+// compileJQ return the filter for a jq expression, the expressions are compiled once and shared
+func compileJQ(expr string) (filter jqFilter, err error) {
+	var compiled interface{}
+	if compiled, err = jqPrograms.get(expr); err != nil {
+		return nil, err
+	}
+	return compiled.(jqFilter), nil
+}
+
+// compileJQExpr compile a jq expression in a filter
+func compileJQExpr(expr string) (filter jqFilter, err error) {
+	generalScopeErr := "error compiling a jq expression"
+	var tokens []jqToken
+	if tokens, err = jqTokenize(expr); err != nil {
+		return nil, util.ErrorFromThisScope(err.Error(), generalScopeErr)
+	}
+	p := &jqParser{tokens: tokens}
+	if filter, err = p.parsePipe(); err != nil {
+		return nil, util.ErrorFromThisScope(err.Error(), generalScopeErr)
+	}
+	if tok := p.peek(); tok.kind != jqTokenEOF {
+		errCause := fmt.Sprintf("unexpected %q at position %d", tok.text, tok.pos)
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return filter, nil
+}
+
+// ValidatePath compile the jq expression, so it is compiled once when the config is loaded
+func (s JQSolver) ValidatePath(path string) error {
+	_, err := compileJQ(path)
+	return err
+}
+
+// PathLookup return the output of the jq expression in path, a single output is returned as a value and many as a
+// collection
+func (s JQSolver) PathLookup(path string, doc interface{}) (node interface{}, err error) {
+	if len(path) == 0 {
+		log.Errorln("node path is required")
+		return nil, config.ErrKeyEmptyValue
+	}
+	generalScopeErr := "error running a jq expression"
+	var filter jqFilter
+	if filter, err = compileJQ(path); err != nil {
+		return nil, err
+	}
+	var outputs []interface{}
+	if outputs, err = filter(doc); err != nil {
+		errCause := fmt.Sprintf("can not run %s: %s", path, err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if len(outputs) == 0 {
+		errCause := fmt.Sprintf("the expression %s have not output", path)
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return nodeOrCollection(outputs), nil
+}
+
+type jqTokenKind int
+
+const (
+	jqTokenEOF jqTokenKind = iota
+	// jqTokenField is a .name or ."name" path
+	jqTokenField
+	// jqTokenIdent is a function name or keyword
+	jqTokenIdent
+	jqTokenNumber
+	jqTokenString
+	// jqTokenOp is a punctuation or operator like | , [ ] ( ) == + .
+	jqTokenOp
+)
+
+type jqToken struct {
+	kind jqTokenKind
+	text string
+	pos  int
+}
+
+// jqOps are sorted so the longest operators are matched first
+var jqOps = []string{"..", "==", "!=", "<=", ">=", "//", "|", ",", "[", "]", "(", ")", "<", ">", "+", "-", "*", "/", ".", ":"}
+
+// jqKeywords can not be used as function names
+var jqKeywords = map[string]bool{"if": true, "then": true, "elif": true, "else": true, "end": true, "and": true, "or": true}
+
+// jqTokenize split a jq expression in tokens, the positions are byte offsets in expr
+func jqTokenize(expr string) (tokens []jqToken, err error) {
+	isIdent := func(r rune, first bool) bool {
+		return r == '_' || unicode.IsLetter(r) || (!first && unicode.IsDigit(r))
+	}
+	// runeAt return the rune starting at pos, utf8.RuneError after the end
+	runeAt := func(pos int) (r rune, size int) {
+		if pos >= len(expr) {
+			return utf8.RuneError, 0
+		}
+		return utf8.DecodeRuneInString(expr[pos:])
+	}
+	// identEnd return the end of the identifier starting at pos
+	identEnd := func(pos int) int {
+		for pos < len(expr) {
+			r, size := runeAt(pos)
+			if !isIdent(r, false) {
+				break
+			}
+			pos += size
+		}
+		return pos
+	}
+	readString := func(pos int) (text string, end int, err error) {
+		end = pos + 1
+		for end < len(expr) && expr[end] != '"' {
+			if expr[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(expr) {
+			return "", end, fmt.Errorf("unterminated string at position %d", pos)
+		}
+		if text, err = strconv.Unquote(expr[pos : end+1]); err != nil {
+			return "", end, fmt.Errorf("invalid string at position %d: %s", pos, err.Error())
+		}
+		return text, end + 1, nil
+	}
+	for pos := 0; pos < len(expr); {
+		c, size := runeAt(pos)
+		next, _ := runeAt(pos + size)
+		switch {
+		case c == utf8.RuneError && size <= 1:
+			return nil, fmt.Errorf("invalid utf-8 encoding at position %d", pos)
+		case unicode.IsSpace(c):
+			pos += size
+		case c == '.' && isIdent(next, true):
+			end := identEnd(pos + 1)
+			tokens = append(tokens, jqToken{kind: jqTokenField, text: expr[pos+1 : end], pos: pos})
+			pos = end
+		case c == '.' && next == '"':
+			text, end, err := readString(pos + 1)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, jqToken{kind: jqTokenField, text: text, pos: pos})
+			pos = end
+		case isIdent(c, true):
+			end := identEnd(pos)
+			tokens = append(tokens, jqToken{kind: jqTokenIdent, text: expr[pos:end], pos: pos})
+			pos = end
+		case c >= '0' && c <= '9':
+			end := pos
+			for end < len(expr) && (expr[end] >= '0' && expr[end] <= '9' || expr[end] == '.' || expr[end] == 'e' || expr[end] == 'E') {
+				end++
+			}
+			tokens = append(tokens, jqToken{kind: jqTokenNumber, text: expr[pos:end], pos: pos})
+			pos = end
+		case c == '"':
+			text, end, err := readString(pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, jqToken{kind: jqTokenString, text: text, pos: pos})
+			pos = end
+		default:
+			found := false
+			for _, op := range jqOps {
+				if strings.HasPrefix(expr[pos:], op) {
+					tokens = append(tokens, jqToken{kind: jqTokenOp, text: op, pos: pos})
+					pos += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, pos)
+			}
+		}
+	}
+	return append(tokens, jqToken{kind: jqTokenEOF, pos: len(expr)}), nil
+}
+
+// jqParser build the filters for a tokenized jq expression by recursive descent, from the lowest precedence: pipe,
+// comma, alternative, or, and, comparison, additive, multiplicative and postfix
+type jqParser struct {
+	tokens []jqToken
+	pos    int
+}
+
+func (p *jqParser) peek() jqToken {
+	return p.tokens[p.pos]
+}
+
+func (p *jqParser) next() jqToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != jqTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consume the next token if it is the op
+func (p *jqParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == jqTokenOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// acceptKeyword consume the next token if it is the keyword
+func (p *jqParser) acceptKeyword(keyword string) bool {
+	if tok := p.peek(); tok.kind == jqTokenIdent && tok.text == keyword {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *jqParser) expect(op string) error {
+	if !p.accept(op) {
+		tok := p.peek()
+		if tok.kind == jqTokenEOF {
+			return fmt.Errorf("expected %q at the end of the expression", op)
+		}
+		return fmt.Errorf("expected %q at position %d, found %q", op, tok.pos, tok.text)
+	}
+	return nil
+}
+
+func (p *jqParser) parsePipe() (filter jqFilter, err error) {
+	if filter, err = p.parseComma(); err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		var right jqFilter
+		if right, err = p.parseComma(); err != nil {
+			return nil, err
+		}
+		filter = jqCompose(filter, right)
+	}
+	return filter, nil
+}
+
+// jqCompose feed every output of left to right
+func jqCompose(left, right jqFilter) jqFilter {
+	return func(input interface{}) (outputs []interface{}, err error) {
+		var lefts []interface{}
+		if lefts, err = left(input); err != nil {
+			return nil, err
+		}
+		for _, l := range lefts {
+			var rights []interface{}
+			if rights, err = right(l); err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, rights...)
+		}
+		return outputs, nil
+	}
+}
+
+func (p *jqParser) parseComma() (filter jqFilter, err error) {
+	if filter, err = p.parseAlternative(); err != nil {
+		return nil, err
+	}
+	for p.accept(",") {
+		var right jqFilter
+		if right, err = p.parseAlternative(); err != nil {
+			return nil, err
+		}
+		left := filter
+		filter = func(input interface{}) (outputs []interface{}, err error) {
+			if outputs, err = left(input); err != nil {
+				return nil, err
+			}
+			var rights []interface{}
+			if rights, err = right(input); err != nil {
+				return nil, err
+			}
+			return append(outputs, rights...), nil
+		}
+	}
+	return filter, nil
+}
+
+// parseAlternative parse a right associative a // b, the output is the outputs of a not being false or null, or the
+// outputs of b if there are none, the errors in a are ignored
+func (p *jqParser) parseAlternative() (filter jqFilter, err error) {
+	if filter, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	if !p.accept("//") {
+		return filter, nil
+	}
+	var alternative jqFilter
+	if alternative, err = p.parseAlternative(); err != nil {
+		return nil, err
+	}
+	left := filter
+	return func(input interface{}) (outputs []interface{}, err error) {
+		lefts, errLeft := left(input)
+		if errLeft == nil {
+			for _, l := range lefts {
+				if jqTruthy(l) {
+					outputs = append(outputs, l)
+				}
+			}
+		}
+		if len(outputs) != 0 {
+			return outputs, nil
+		}
+		return alternative(input)
+	}, nil
+}
+
+// jqBinary apply op to every pair of outputs from left and right
+func jqBinary(left, right jqFilter, op func(l, r interface{}) (interface{}, error)) jqFilter {
+	return func(input interface{}) (outputs []interface{}, err error) {
+		var lefts, rights []interface{}
+		if lefts, err = left(input); err != nil {
+			return nil, err
+		}
+		if rights, err = right(input); err != nil {
+			return nil, err
+		}
+		for _, l := range lefts {
+			for _, r := range rights {
+				var out interface{}
+				if out, err = op(l, r); err != nil {
+					return nil, err
+				}
+				outputs = append(outputs, out)
+			}
+		}
+		return outputs, nil
+	}
+}
+
+// parseBinary parse a left associative level of binary operators
+func (p *jqParser) parseBinary(operand func() (jqFilter, error), ops map[string]func(l, r interface{}) (interface{}, error)) (filter jqFilter, err error) {
+	if filter, err = operand(); err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		op, found := ops[tok.text]
+		if !found || (tok.kind != jqTokenOp && tok.kind != jqTokenIdent) {
+			return filter, nil
+		}
+		p.next()
+		var right jqFilter
+		if right, err = operand(); err != nil {
+			return nil, err
+		}
+		filter = jqBinary(filter, right, op)
+	}
+}
+
+func (p *jqParser) parseOr() (jqFilter, error) {
+	return p.parseBinary(p.parseAnd, map[string]func(l, r interface{}) (interface{}, error){
+		"or": func(l, r interface{}) (interface{}, error) { return jqTruthy(l) || jqTruthy(r), nil },
+	})
+}
+
+func (p *jqParser) parseAnd() (jqFilter, error) {
+	return p.parseBinary(p.parseComparison, map[string]func(l, r interface{}) (interface{}, error){
+		"and": func(l, r interface{}) (interface{}, error) { return jqTruthy(l) && jqTruthy(r), nil },
+	})
+}
+
+func (p *jqParser) parseComparison() (jqFilter, error) {
+	return p.parseBinary(p.parseAdditive, map[string]func(l, r interface{}) (interface{}, error){
+		"==": func(l, r interface{}) (interface{}, error) { return jqCompare(l, r) == 0, nil },
+		"!=": func(l, r interface{}) (interface{}, error) { return jqCompare(l, r) != 0, nil },
+		"<":  func(l, r interface{}) (interface{}, error) { return jqCompare(l, r) < 0, nil },
+		"<=": func(l, r interface{}) (interface{}, error) { return jqCompare(l, r) <= 0, nil },
+		">":  func(l, r interface{}) (interface{}, error) { return jqCompare(l, r) > 0, nil },
+		">=": func(l, r interface{}) (interface{}, error) { return jqCompare(l, r) >= 0, nil },
+	})
+}
+
+func (p *jqParser) parseAdditive() (jqFilter, error) {
+	return p.parseBinary(p.parseMultiplicative, map[string]func(l, r interface{}) (interface{}, error){
+		"+": jqAdd,
+		"-": jqArithmetic("-", func(l, r float64) float64 { return l - r }),
+	})
+}
+
+func (p *jqParser) parseMultiplicative() (jqFilter, error) {
+	return p.parseBinary(p.parsePostfix, map[string]func(l, r interface{}) (interface{}, error){
+		"*": jqArithmetic("*", func(l, r float64) float64 { return l * r }),
+		"/": jqArithmetic("/", func(l, r float64) float64 { return l / r }),
+	})
+}
+
+// parsePostfix parse a term followed by any number of .name, [index] and [] suffixes
+func (p *jqParser) parsePostfix() (filter jqFilter, err error) {
+	if filter, err = p.parseTerm(); err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == jqTokenField:
+			p.next()
+			filter = jqCompose(filter, jqIndexFilter(jqLiteral(tok.text)))
+		case tok.kind == jqTokenOp && tok.text == "[":
+			var suffix jqFilter
+			if suffix, err = p.parseBracket(); err != nil {
+				return nil, err
+			}
+			filter = jqCompose(filter, suffix)
+		default:
+			return filter, nil
+		}
+	}
+}
+
+// parseBracket parse a [] iteration, a [index] suffix or a [from:to] slice
+func (p *jqParser) parseBracket() (filter jqFilter, err error) {
+	if err = p.expect("["); err != nil {
+		return nil, err
+	}
+	if p.accept("]") {
+		return jqIterate, nil
+	}
+	var from, to jqFilter = jqLiteral(nil), jqLiteral(nil)
+	isSlice := p.accept(":")
+	if !isSlice {
+		if from, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+		isSlice = p.accept(":")
+	}
+	if isSlice {
+		if tok := p.peek(); tok.kind != jqTokenOp || tok.text != "]" {
+			if to, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err = p.expect("]"); err != nil {
+		return nil, err
+	}
+	if isSlice {
+		return jqSliceFilter(from, to), nil
+	}
+	return jqIndexFilter(from), nil
+}
+
+// parseIf parse the rest of a if cond then a elif cond then b else c end conditional
+func (p *jqParser) parseIf() (filter jqFilter, err error) {
+	var cond, then jqFilter
+	if cond, err = p.parsePipe(); err != nil {
+		return nil, err
+	}
+	if !p.acceptKeyword("then") {
+		return nil, fmt.Errorf("expected then at position %d", p.peek().pos)
+	}
+	if then, err = p.parsePipe(); err != nil {
+		return nil, err
+	}
+	otherwise := jqFilter(func(input interface{}) ([]interface{}, error) { return []interface{}{input}, nil })
+	switch {
+	case p.acceptKeyword("elif"):
+		if otherwise, err = p.parseIf(); err != nil {
+			return nil, err
+		}
+		return jqIf(cond, then, otherwise), nil
+	case p.acceptKeyword("else"):
+		if otherwise, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.acceptKeyword("end") {
+		return nil, fmt.Errorf("expected end at position %d", p.peek().pos)
+	}
+	return jqIf(cond, then, otherwise), nil
+}
+
+// jqIf apply then or otherwise to the input for each output of cond
+func jqIf(cond, then, otherwise jqFilter) jqFilter {
+	return func(input interface{}) (outputs []interface{}, err error) {
+		var conds []interface{}
+		if conds, err = cond(input); err != nil {
+			return nil, err
+		}
+		for _, c := range conds {
+			branch := otherwise
+			if jqTruthy(c) {
+				branch = then
+			}
+			var branchOutputs []interface{}
+			if branchOutputs, err = branch(input); err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, branchOutputs...)
+		}
+		return outputs, nil
+	}
+}
+
+// parseTerm parse a path, literal, parenthesized expression, array construction or function call
+func (p *jqParser) parseTerm() (filter jqFilter, err error) {
+	tok := p.next()
+	switch tok.kind {
+	case jqTokenField:
+		return jqIndexFilter(jqLiteral(tok.text)), nil
+	case jqTokenNumber:
+		var num float64
+		if num, err = strconv.ParseFloat(tok.text, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return jqLiteral(num), nil
+	case jqTokenString:
+		return jqLiteral(tok.text), nil
+	case jqTokenIdent:
+		return p.parseFunction(tok)
+	case jqTokenOp:
+		switch tok.text {
+		case ".":
+			return func(input interface{}) ([]interface{}, error) { return []interface{}{input}, nil }, nil
+		case "..":
+			return func(input interface{}) ([]interface{}, error) { return jqRecurse(input, nil), nil }, nil
+		case "-":
+			var operand jqFilter
+			if operand, err = p.parsePostfix(); err != nil {
+				return nil, err
+			}
+			return jqBinary(jqLiteral(float64(0)), operand, jqArithmetic("-", func(l, r float64) float64 { return l - r })), nil
+		case "(":
+			if filter, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+			return filter, p.expect(")")
+		case "[":
+			if p.accept("]") {
+				return func(input interface{}) ([]interface{}, error) { return []interface{}{[]interface{}{}}, nil }, nil
+			}
+			var items jqFilter
+			if items, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+			if err = p.expect("]"); err != nil {
+				return nil, err
+			}
+			return func(input interface{}) (outputs []interface{}, err error) {
+				var collected []interface{}
+				if collected, err = items(input); err != nil {
+					return nil, err
+				}
+				if collected == nil {
+					collected = []interface{}{}
+				}
+				return []interface{}{collected}, nil
+			}, nil
+		}
+	case jqTokenEOF:
+		return nil, fmt.Errorf("unexpected end of the expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+// jqFunctions are the functions without arguments, they are applied to the input
+var jqFunctions = map[string]func(input interface{}) ([]interface{}, error){
+	"true":  func(interface{}) ([]interface{}, error) { return []interface{}{true}, nil },
+	"false": func(interface{}) ([]interface{}, error) { return []interface{}{false}, nil },
+	"null":  func(interface{}) ([]interface{}, error) { return []interface{}{nil}, nil },
+	"empty": func(interface{}) ([]interface{}, error) { return nil, nil },
+	"not":   func(input interface{}) ([]interface{}, error) { return []interface{}{!jqTruthy(input)}, nil },
+	"length": func(input interface{}) ([]interface{}, error) {
+		switch v := input.(type) {
+		case nil:
+			return []interface{}{float64(0)}, nil
+		case bool:
+			return nil, fmt.Errorf("boolean has no length")
+		case float64:
+			return []interface{}{math.Abs(v)}, nil
+		case string:
+			return []interface{}{float64(len([]rune(v)))}, nil
+		case []interface{}:
+			return []interface{}{float64(len(v))}, nil
+		case map[string]interface{}:
+			return []interface{}{float64(len(v))}, nil
+		}
+		return nil, fmt.Errorf("%s has no length", jqType(input))
+	},
+	"keys": func(input interface{}) ([]interface{}, error) {
+		switch v := input.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			out := make([]interface{}, len(keys))
+			for idx, key := range keys {
+				out[idx] = key
+			}
+			return []interface{}{out}, nil
+		case []interface{}:
+			out := make([]interface{}, len(v))
+			for idx := range v {
+				out[idx] = float64(idx)
+			}
+			return []interface{}{out}, nil
+		}
+		return nil, fmt.Errorf("%s has no keys", jqType(input))
+	},
+	"values": func(input interface{}) ([]interface{}, error) {
+		if input == nil {
+			return nil, nil
+		}
+		return []interface{}{input}, nil
+	},
+	"add": func(input interface{}) (outputs []interface{}, err error) {
+		var items []interface{}
+		if items, err = jqIterate(input); err != nil {
+			return nil, err
+		}
+		var sum interface{}
+		for _, item := range items {
+			if sum, err = jqAdd(sum, item); err != nil {
+				return nil, err
+			}
+		}
+		return []interface{}{sum}, nil
+	},
+	"min":   jqExtreme(-1),
+	"max":   jqExtreme(1),
+	"first": func(input interface{}) ([]interface{}, error) { return jqIndexFilter(jqLiteral(float64(0)))(input) },
+	"last":  func(input interface{}) ([]interface{}, error) { return jqIndexFilter(jqLiteral(float64(-1)))(input) },
+	"sort": func(input interface{}) ([]interface{}, error) {
+		items, isArray := input.([]interface{})
+		if !isArray {
+			return nil, fmt.Errorf("%s can not be sorted", jqType(input))
+		}
+		sorted := append([]interface{}{}, items...)
+		sort.SliceStable(sorted, func(i, j int) bool { return jqCompare(sorted[i], sorted[j]) < 0 })
+		return []interface{}{sorted}, nil
+	},
+	"tonumber": func(input interface{}) ([]interface{}, error) {
+		switch v := input.(type) {
+		case float64:
+			return []interface{}{v}, nil
+		case string:
+			num, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("can not parse %q as a number", v)
+			}
+			return []interface{}{num}, nil
+		}
+		return nil, fmt.Errorf("%s can not be parsed as a number", jqType(input))
+	},
+	"tostring": func(input interface{}) ([]interface{}, error) {
+		switch v := input.(type) {
+		case string:
+			return []interface{}{v}, nil
+		case float64:
+			return []interface{}{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+		}
+		return []interface{}{fmt.Sprint(input)}, nil
+	},
+	"type": func(input interface{}) ([]interface{}, error) { return []interface{}{jqType(input)}, nil },
+	"reverse": func(input interface{}) ([]interface{}, error) {
+		switch v := input.(type) {
+		case nil:
+			return []interface{}{[]interface{}{}}, nil
+		case string:
+			runes := []rune(v)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return []interface{}{string(runes)}, nil
+		case []interface{}:
+			reversed := make([]interface{}, len(v))
+			for idx, item := range v {
+				reversed[len(v)-1-idx] = item
+			}
+			return []interface{}{reversed}, nil
+		}
+		return nil, fmt.Errorf("%s can not be reversed", jqType(input))
+	},
+	"to_entries": func(input interface{}) ([]interface{}, error) {
+		obj, isObject := input.(map[string]interface{})
+		if !isObject {
+			return nil, fmt.Errorf("%s has no entries", jqType(input))
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]interface{}, len(keys))
+		for idx, key := range keys {
+			entries[idx] = map[string]interface{}{"key": key, "value": obj[key]}
+		}
+		return []interface{}{entries}, nil
+	},
+	"numbers":  jqTypeSelector("number"),
+	"strings":  jqTypeSelector("string"),
+	"booleans": jqTypeSelector("boolean"),
+	"nulls":    jqTypeSelector("null"),
+	"arrays":   jqTypeSelector("array"),
+	"objects":  jqTypeSelector("object"),
+}
+
+// jqTypeSelector output the input if it is of the jq type typeName
+func jqTypeSelector(typeName string) func(input interface{}) ([]interface{}, error) {
+	return func(input interface{}) ([]interface{}, error) {
+		if jqType(input) != typeName {
+			return nil, nil
+		}
+		return []interface{}{input}, nil
+	}
+}
+
+// jqFunctionsWithArg are the functions with an argument, the argument is a filter applied to the input
+var jqFunctionsWithArg = map[string]func(arg jqFilter) jqFilter{
+	"select": func(arg jqFilter) jqFilter {
+		return func(input interface{}) (outputs []interface{}, err error) {
+			var conds []interface{}
+			if conds, err = arg(input); err != nil {
+				return nil, err
+			}
+			for _, cond := range conds {
+				if jqTruthy(cond) {
+					outputs = append(outputs, input)
+				}
+			}
+			return outputs, nil
+		}
+	},
+	"map": func(arg jqFilter) jqFilter {
+		mapped := jqCompose(jqIterate, arg)
+		return func(input interface{}) (outputs []interface{}, err error) {
+			if outputs, err = mapped(input); err != nil {
+				return nil, err
+			}
+			if outputs == nil {
+				outputs = []interface{}{}
+			}
+			return []interface{}{outputs}, nil
+		}
+	},
+	"has": func(arg jqFilter) jqFilter {
+		return func(input interface{}) (outputs []interface{}, err error) {
+			var keys []interface{}
+			if keys, err = arg(input); err != nil {
+				return nil, err
+			}
+			for _, key := range keys {
+				switch v := input.(type) {
+				case map[string]interface{}:
+					k, isString := key.(string)
+					if !isString {
+						return nil, fmt.Errorf("object keys should be strings, not %s", jqType(key))
+					}
+					_, found := v[k]
+					outputs = append(outputs, found)
+				case []interface{}:
+					idx, isNumber := key.(float64)
+					if !isNumber {
+						return nil, fmt.Errorf("array indexes should be numbers, not %s", jqType(key))
+					}
+					outputs = append(outputs, idx >= 0 && int(idx) < len(v))
+				default:
+					return nil, fmt.Errorf("can not check if %s has a key", jqType(input))
+				}
+			}
+			return outputs, nil
+		}
+	},
+}
+
+// parseFunction parse a conditional, a function without arguments or a function with a argument like
+// select(.a > 1)
+func (p *jqParser) parseFunction(tok jqToken) (filter jqFilter, err error) {
+	if tok.text == "if" {
+		return p.parseIf()
+	}
+	if jqKeywords[tok.text] {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok.text, tok.pos)
+	}
+	if withArg, found := jqFunctionsWithArg[tok.text]; found {
+		if err = p.expect("("); err != nil {
+			return nil, fmt.Errorf("function %s require an argument: %s", tok.text, err.Error())
+		}
+		var arg jqFilter
+		if arg, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(")"); err != nil {
+			return nil, err
+		}
+		return withArg(arg), nil
+	}
+	if fn, found := jqFunctions[tok.text]; found {
+		return fn, nil
+	}
+	return nil, fmt.Errorf("unknown function %s at position %d", tok.text, tok.pos)
+}
+
+// jqLiteral always output val
+func jqLiteral(val interface{}) jqFilter {
+	return func(interface{}) ([]interface{}, error) {
+		return []interface{}{val}, nil
+	}
+}
+
+// jqIterate output the items of an array or the values of an object sorted by key
+func jqIterate(input interface{}) (outputs []interface{}, err error) {
+	switch v := input.(type) {
+	case []interface{}:
+		return v, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			outputs = append(outputs, v[key])
+		}
+		return outputs, nil
+	}
+	return nil, fmt.Errorf("can not iterate over %s", jqType(input))
+}
+
+// jqIndexFilter output the field or item of the input selected by each output of index, null is the output for a
+// missing field or item, or a null input
+func jqIndexFilter(index jqFilter) jqFilter {
+	return func(input interface{}) (outputs []interface{}, err error) {
+		var keys []interface{}
+		if keys, err = index(input); err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			switch v := input.(type) {
+			case nil:
+				outputs = append(outputs, nil)
+			case map[string]interface{}:
+				k, isString := key.(string)
+				if !isString {
+					return nil, fmt.Errorf("can not index an object with %s", jqType(key))
+				}
+				outputs = append(outputs, v[k])
+			case []interface{}:
+				idx, isNumber := key.(float64)
+				if !isNumber {
+					return nil, fmt.Errorf("can not index an array with %s", jqType(key))
+				}
+				i := int(idx)
+				if i < 0 {
+					i += len(v)
+				}
+				if i < 0 || i >= len(v) {
+					outputs = append(outputs, nil)
+				} else {
+					outputs = append(outputs, v[i])
+				}
+			default:
+				return nil, fmt.Errorf("can not index %s with %v", jqType(input), key)
+			}
+		}
+		return outputs, nil
+	}
+}
+
+// jqSliceFilter output the part of an array or string between each pair of outputs of from and to, a null index is
+// the start or the end, the negative indexes count from the end and a null input output null
+func jqSliceFilter(from, to jqFilter) jqFilter {
+	return func(input interface{}) (outputs []interface{}, err error) {
+		var froms, tos []interface{}
+		if froms, err = from(input); err != nil {
+			return nil, err
+		}
+		if tos, err = to(input); err != nil {
+			return nil, err
+		}
+		var length int
+		switch v := input.(type) {
+		case nil:
+			for range froms {
+				for range tos {
+					outputs = append(outputs, nil)
+				}
+			}
+			return outputs, nil
+		case string:
+			length = len([]rune(v))
+		case []interface{}:
+			length = len(v)
+		default:
+			return nil, fmt.Errorf("can not slice %s", jqType(input))
+		}
+		bound := func(idx interface{}, def int) (int, error) {
+			if idx == nil {
+				return def, nil
+			}
+			num, isNumber := idx.(float64)
+			if !isNumber {
+				return 0, fmt.Errorf("slice indexes should be numbers, not %s", jqType(idx))
+			}
+			i := int(math.Floor(num))
+			if i < 0 {
+				i += length
+			}
+			if i < 0 {
+				return 0, nil
+			}
+			if i > length {
+				return length, nil
+			}
+			return i, nil
+		}
+		for _, f := range froms {
+			for _, t := range tos {
+				var start, end int
+				if start, err = bound(f, 0); err != nil {
+					return nil, err
+				}
+				if end, err = bound(t, length); err != nil {
+					return nil, err
+				}
+				if end < start {
+					end = start
+				}
+				switch v := input.(type) {
+				case string:
+					outputs = append(outputs, string([]rune(v)[start:end]))
+				case []interface{}:
+					outputs = append(outputs, append([]interface{}{}, v[start:end]...))
+				}
+			}
+		}
+		return outputs, nil
+	}
+}
+
+// jqRecurse output the input and all its descendants in pre order
+func jqRecurse(input interface{}, outputs []interface{}) []interface{} {
+	outputs = append(outputs, input)
+	switch input.(type) {
+	case []interface{}, map[string]interface{}:
+		children, _ := jqIterate(input)
+		for _, child := range children {
+			outputs = jqRecurse(child, outputs)
+		}
+	}
+	return outputs
+}
+
+// jqTruthy return false for false and null only
+func jqTruthy(val interface{}) bool {
+	if b, isBool := val.(bool); isBool {
+		return b
+	}
+	return val != nil
+}
+
+// jqType return the jq type name of a value
+func jqType(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return reflect.TypeOf(val).String()
+}
+
+// jqTypeOrder is the order between the values of different types
+var jqTypeOrder = map[string]int{"null": 0, "boolean": 1, "number": 2, "string": 3, "array": 4, "object": 5}
+
+// jqCompare return -1, 0 or 1 if l is less, equal or greater than r, the values of different types are ordered like
+// null < false < true < numbers < strings < arrays < objects
+func jqCompare(l, r interface{}) int {
+	lType, rType := jqType(l), jqType(r)
+	if lType != rType {
+		if jqTypeOrder[lType] < jqTypeOrder[rType] {
+			return -1
+		}
+		return 1
+	}
+	switch lv := l.(type) {
+	case bool:
+		rv := r.(bool)
+		if lv == rv {
+			return 0
+		} else if !lv {
+			return -1
+		}
+		return 1
+	case float64:
+		rv := r.(float64)
+		if lv < rv {
+			return -1
+		} else if lv > rv {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(lv, r.(string))
+	case []interface{}:
+		rv := r.([]interface{})
+		for idx := 0; idx < len(lv) && idx < len(rv); idx++ {
+			if c := jqCompare(lv[idx], rv[idx]); c != 0 {
+				return c
+			}
+		}
+		return jqCompare(float64(len(lv)), float64(len(rv)))
+	}
+	if reflect.DeepEqual(l, r) {
+		return 0
+	}
+	return jqCompare(fmt.Sprint(l), fmt.Sprint(r))
+}
+
+// jqAdd add numbers, concatenate strings and arrays and merge objects, null is the identity
+func jqAdd(l, r interface{}) (interface{}, error) {
+	if l == nil {
+		return r, nil
+	}
+	if r == nil {
+		return l, nil
+	}
+	switch lv := l.(type) {
+	case float64:
+		if rv, ok := r.(float64); ok {
+			return lv + rv, nil
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			return lv + rv, nil
+		}
+	case []interface{}:
+		if rv, ok := r.([]interface{}); ok {
+			return append(append([]interface{}{}, lv...), rv...), nil
+		}
+	case map[string]interface{}:
+		if rv, ok := r.(map[string]interface{}); ok {
+			merged := make(map[string]interface{}, len(lv)+len(rv))
+			for k, v := range lv {
+				merged[k] = v
+			}
+			for k, v := range rv {
+				merged[k] = v
+			}
+			return merged, nil
+		}
+	}
+	return nil, fmt.Errorf("%s and %s can not be added", jqType(l), jqType(r))
+}
+
+// jqArithmetic apply a numeric operator
+func jqArithmetic(op string, fn func(l, r float64) float64) func(l, r interface{}) (interface{}, error) {
+	return func(l, r interface{}) (interface{}, error) {
+		lv, lIsNumber := l.(float64)
+		rv, rIsNumber := r.(float64)
+		if !lIsNumber || !rIsNumber {
+			return nil, fmt.Errorf("%s and %s can not be operated with %s", jqType(l), jqType(r), op)
+		}
+		if op == "/" && rv == 0 {
+			return nil, fmt.Errorf("%v can not be divided by zero", lv)
+		}
+		return fn(lv, rv), nil
+	}
+}
+
+// jqExtreme output the minimum(sign -1) or maximum(sign 1) item of an array, null for an empty array
+func jqExtreme(sign int) func(input interface{}) ([]interface{}, error) {
+	return func(input interface{}) ([]interface{}, error) {
+		items, isArray := input.([]interface{})
+		if !isArray {
+			return nil, fmt.Errorf("%s has no items", jqType(input))
+		}
+		var extreme interface{}
+		for idx, item := range items {
+			if idx == 0 || jqCompare(item, extreme)*sign > 0 {
+				extreme = item
+			}
+		}
+		return []interface{}{extreme}, nil
+	}
+}
@@ -0,0 +1,187 @@
+package scrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+const jqConnections = `{
+	"connections": [
+		{"address": "139.162.161.41:20002", "outgoing": true, "height": 180, "user_agent": "skycoin:0.24.1"},
+		{"address": "185.120.34.60:6000", "outgoing": false, "height": 57, "user_agent": "skycoin:0.25.0"},
+		{"address": "172.104.85.6:6000", "outgoing": true, "height": 5, "user_agent": null}
+	],
+	"unconfirmed": {"txn-a": 2, "txn-b": 7},
+	"состояние": {"высота": 42}
+}`
+
+type jqSolverSuit struct {
+	suite.Suite
+	doc interface{}
+}
+
+func TestJQSolverSuit(t *testing.T) {
+	suite.Run(t, new(jqSolverSuit))
+}
+
+func (suite *jqSolverSuit) SetupTest() {
+	var err error
+	suite.doc, err = JSONParser{}.DecodeBody([]byte(jqConnections))
+	suite.Require().Nil(err)
+}
+
+func (suite *jqSolverSuit) TestPathLookup() {
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: ".connections[0].height", expected: float64(180)},
+		{path: ".connections[-1].address", expected: "172.104.85.6:6000"},
+		{path: ".connections[].height", expected: []interface{}{float64(180), float64(57), float64(5)}},
+		{path: ".connections[] | select(.outgoing == true) | .height", expected: []interface{}{float64(180), float64(5)}},
+		{path: ".connections[] | select(.outgoing and .height > 100) | .address", expected: "139.162.161.41:20002"},
+		{path: ".connections[] | select(.user_agent != null) | .height", expected: []interface{}{float64(180), float64(57)}},
+		{path: ".connections | length", expected: float64(3)},
+		{path: "[.connections[] | select(.outgoing | not)] | length", expected: float64(1)},
+		{path: ".unconfirmed | keys", expected: []interface{}{"txn-a", "txn-b"}},
+		{path: ".unconfirmed | keys[]", expected: []interface{}{"txn-a", "txn-b"}},
+		{path: `.unconfirmed["txn-b"]`, expected: float64(7)},
+		{path: `.unconfirmed."txn-a"`, expected: float64(2)},
+		{path: ".unconfirmed[]", expected: []interface{}{float64(2), float64(7)}},
+		{path: "[.connections[].height] | add / length", expected: float64(80.66666666666667)},
+		{path: "[.connections[].height] | max - min", expected: float64(175)},
+		{path: ".connections | map(.height * 2) | sort | first", expected: float64(10)},
+		{path: ".connections[0] | has(\"height\"), has(\"missing\")", expected: []interface{}{true, false}},
+		{path: ".connections[1].user_agent | tostring", expected: "skycoin:0.25.0"},
+		{path: "[..] | length", expected: float64(22)},
+		{path: ".connections[2].user_agent // \"unknown\"", expected: "unknown"},
+		{path: ".missing // .connections[0].height // 0", expected: float64(180)},
+		{path: ".connections[1:] | map(.height)", expected: []interface{}{float64(57), float64(5)}},
+		{path: ".connections[:-2][].height", expected: float64(180)},
+		{path: ".connections[0].address[:3]", expected: "139"},
+		{path: ".connections[] | if .outgoing then .height elif .height > 50 then -1 else 0 end", expected: []interface{}{float64(180), float64(-1), float64(5)}},
+		{path: ".connections[] | if .user_agent == null then .height end | numbers", expected: float64(5)},
+		{path: ".unconfirmed | to_entries | map(.key)", expected: []interface{}{"txn-a", "txn-b"}},
+		{path: ".unconfirmed | to_entries[1].value", expected: float64(7)},
+		{path: "[.connections[].height] | reverse", expected: []interface{}{float64(5), float64(57), float64(180)}},
+		{path: ".connections[].user_agent | strings", expected: []interface{}{"skycoin:0.24.1", "skycoin:0.25.0"}},
+		{path: ".состояние.высота", expected: float64(42)},
+		{path: `.["состояние"]."высота"`, expected: float64(42)},
+	}
+	for _, test := range tests {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := JQSolver{}.PathLookup(test.path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.Nil(err, test.path)
+		suite.Equal(test.expected, node, test.path)
+	}
+}
+
+func (suite *jqSolverSuit) TestInvalidExpressions() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	paths := []string{
+		".connections[",
+		".connections[] | select(.outgoing",
+		".connections | unknown",
+		".connections | select",
+		`.unconfirmed["txn-a]`,
+		".connections ]",
+		".connections # comment",
+		"{height: .connections[0].height}",
+		".connections[0].height?",
+		".connections[] as $c | $c.height",
+		"reduce .connections[] as $c (0; . + $c.height)",
+		"try .connections",
+		"def f: .height; .connections[] | f",
+		".connections[0].height = 1",
+		".connections[0].height % 2",
+		".connections[0].address | @base64",
+		`"\(.connections[0].height)"`,
+		"if .connections then 1",
+		"if .connections else 1 end",
+		".connections | then",
+		".connections[1:2:3]",
+	}
+	for _, path := range paths {
+		// NOTE(denisacostaq@gmail.com): When
+		errValidate := JQSolver{}.ValidatePath(path)
+		node, err := JQSolver{}.PathLookup(path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.NotNil(errValidate, path)
+		suite.NotNil(err, path)
+		suite.Nil(node, path)
+	}
+}
+
+func (suite *jqSolverSuit) TestRuntimeErrors() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	paths := []string{
+		".connections.height",
+		".connections[0].height[]",
+		".connections[] | select(.height > 1000)",
+		".connections[0].address * 2",
+		".connections | length / 0",
+		".connections[0].height[1:]",
+		".connections[\"a\":]",
+		".connections[0].height | reverse",
+		".connections | to_entries",
+	}
+	for _, path := range paths {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := JQSolver{}.PathLookup(path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.NotNil(err, path)
+		suite.Nil(node, path)
+	}
+}
+
+func (suite *jqSolverSuit) TestNumericVec() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srvConf := memconfig.NewServiceConf("localhost:6420", "http", nil, nil, memconfig.NewOptionsMap())
+	_, err := srvConf.GetOptions().SetString(config.OptKeyRextServiceDefJobName, "skycoin")
+	suite.Nil(err)
+	_, err = srvConf.GetOptions().SetString(config.OptKeyRextServiceDefInstanceName, "localhost:6420")
+	suite.Nil(err)
+	resConf := memconfig.NewResourceDef(config.ResourceTypeRestAPI, "/api/v1/network/connections", nil, nil, memconfig.NewDecoder(config.DecoderJSON, nil), nil)
+	nSolver := memconfig.NewNodeSolver(config.RextNodeSolverTypeJQ, ".connections[] | select(.outgoing) | .height", nil)
+	mtrConf := &memconfig.MetricDef{}
+	mtrConf.SetMetricType(config.KeyMetricTypeGauge)
+	mtrConf.AddLabel(memconfig.NewLabelDef("address", memconfig.NewNodeSolver(config.RextNodeSolverTypeJQ, ".connections[] | select(.outgoing) | .address", nil)))
+	suite.False(nSolver.Validate())
+	parser, err := NewBodyParser(resConf.GetDecoder(), nSolver)
+	suite.Require().Nil(err)
+	s, err := NewScrapper(xmlClient{body: jqConnections}, parser, resConf, srvConf, mtrConf, nSolver)
+	suite.Require().Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := s.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(NumericVecVals{
+		{Val: 180, Labels: []string{"139.162.161.41:20002"}},
+		{Val: 5, Labels: []string{"172.104.85.6:6000"}},
+	}, val)
+}
+
+func (suite *jqSolverSuit) TestUnsupportedExpressionsAreInvalidConfig() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	valid := memconfig.NewNodeSolver(config.RextNodeSolverTypeJQ, ".connections[1:] | map(.height // 0)", nil)
+	invalid := memconfig.NewNodeSolver(config.RextNodeSolverTypeJQ, ".connections[] | {height}", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	validHasError := valid.Validate()
+	invalidHasError := invalid.Validate()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(validHasError)
+	suite.True(invalidHasError)
+}
@@ -15,7 +15,8 @@ import (
 type JSONParser struct {
 }
 
-func (p JSONParser) decodeBody(body []byte) (val interface{}, err error) {
+// DecodeBody decode the body as json
+func (p JSONParser) DecodeBody(body []byte) (val interface{}, err error) {
 	generalScopeErr := "error decoding body as json"
 	if err = json.Unmarshal(body, &val); err != nil {
 		errCause := fmt.Sprintf("can not decode the body: %s. Err: %s", string(body), err.Error())
@@ -24,14 +25,30 @@ func (p JSONParser) decodeBody(body []byte) (val interface{}, err error) {
 	return val, err
 }
 
-func (p JSONParser) pathLookup(path string, val interface{}) (node interface{}, err error) {
+// jsonPaths are the compiled json paths, a path like "/blockchain/head/seq" is compiled like "$.blockchain.head.seq"
+var jsonPaths = newCompiledPaths(func(path string) (interface{}, error) {
+	return jsonpath.Compile("$" + strings.Replace(path, "/", ".", -1))
+})
+
+// ValidatePath compile the json path
+func (p JSONParser) ValidatePath(path string) error {
+	_, err := jsonPaths.get(path)
+	return err
+}
+
+// PathLookup find the node in a path like "/blockchain/head/seq"
+func (p JSONParser) PathLookup(path string, val interface{}) (node interface{}, err error) {
 	if len(path) == 0 {
 		log.Errorln("node path is required")
 		return nil, config.ErrKeyEmptyValue
 	}
 	generalScopeErr := "error looking for node in val"
-	jPath := "$" + strings.Replace(path, "/", ".", -1)
-	if node, err = jsonpath.JsonPathLookup(val, jPath); err != nil {
+	var compiled interface{}
+	if compiled, err = jsonPaths.get(path); err != nil {
+		errCause := fmt.Sprintln("can not compile the path: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	if node, err = compiled.(*jsonpath.Compiled).Lookup(val); err != nil {
 		errCause := fmt.Sprintln("can not locate the path: ", err.Error())
 		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
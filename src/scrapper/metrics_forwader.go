@@ -1,6 +1,7 @@
 package scrapper
 
 import (
+	"context"
 	"errors"
 	"io/ioutil"
 	"net/http/httptest"
@@ -46,7 +47,7 @@ func NewMetricsForwader(pmcls client.ProxyMetricClientCreator, fDefMetrics *metr
 }
 
 // GetMetric return the original metrics but with a service name as prefix in his names
-func (scrapper MetricsForwader) GetMetric() (val interface{}, err error) {
+func (scrapper MetricsForwader) GetMetric(ctx context.Context) (val interface{}, err error) {
 	getFordwadedMetrics := func() (data []byte, err error) {
 		successResponse := false
 		defer func(startTime time.Time) {
@@ -64,7 +65,7 @@ func (scrapper MetricsForwader) GetMetric() (val interface{}, err error) {
 			return data, util.ErrorFromThisScope(errCause, generalScopeErr)
 		}
 		var exposedMetricsData []byte
-		if exposedMetricsData, err = cl.GetData(); err != nil {
+		if exposedMetricsData, err = cl.GetData(ctx); err != nil {
 			log.WithError(err).Error("error getting metrics from service " + scrapper.GetJobName())
 			errCause := "can not get the data"
 			return data, util.ErrorFromThisScope(errCause, generalScopeErr)
@@ -112,6 +113,10 @@ func (scrapper MetricsForwader) GetMetric() (val interface{}, err error) {
 		val = fordwadedMetrics
 	} else {
 		log.WithError(err).Errorln("error getting fordwaded metrics")
+		if ctx.Err() != nil {
+			labels := []string{scrapper.GetJobName(), scrapper.GetInstanceName()}
+			scrapper.defFordwaderMetrics.FordwaderScrapeTimeouts.WithLabelValues(labels...).Inc()
+		}
 	}
 	return val, nil
 }
@@ -1,6 +1,7 @@
 package scrapper
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -29,14 +30,17 @@ func newNumeric(cf client.Factory, p BodyParser, path, jobName, instanceName, da
 }
 
 // GetMetric returns a single number with the metric value, is a counter or a gauge
-func (n Numeric) GetMetric(metricsCollector chan<- prometheus.Metric) (val interface{}, err error) {
+func (n Numeric) GetMetric(ctx context.Context, metricsCollector chan<- prometheus.Metric) (val interface{}, err error) {
 	const generalScopeErr = "error scrapping numeric(gauge|counter) metric"
 	var iBody interface{}
-	if iBody, err = getData(n.clientFactory, n.parser, metricsCollector); err != nil {
+	if iBody, err = getData(ctx, n.clientFactory, n.parser, metricsCollector); err != nil {
+		if isTypedClientError(err) {
+			return val, err
+		}
 		errCause := "numeric client can not decode the body"
 		return val, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
-	if val, err = n.parser.pathLookup(n.jsonPath, iBody); err != nil {
+	if val, err = n.parser.PathLookup(n.jsonPath, iBody); err != nil {
 		errCause := fmt.Sprintln("can not get node: ", err.Error())
 		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
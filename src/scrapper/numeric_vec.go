@@ -1,6 +1,8 @@
 package scrapper
 
 import (
+	"context"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/simelo/rextporter/src/client"
 	"github.com/simelo/rextporter/src/config"
@@ -10,10 +12,12 @@ import (
 // NumericVec implements the Client interface(is able to get numeric metrics through `GetMetric` like Gauge and Counter)
 type NumericVec struct {
 	baseAPIScrapper
-	labels []config.RextLabelDef
+	labels       []config.RextLabelDef
+	labelLookups []config.NodeLookup
 }
 
-func newNumericVec(cf client.Factory, p BodyParser, jobName, instanceName, dataSource string, nSolver config.RextNodeSolver, mtrConf config.RextMetricDef) Scrapper {
+// newNumericVec create a NumericVec, the labels values are found with labelLookups(one for each label)
+func newNumericVec(cf client.Factory, p BodyParser, labelLookups []config.NodeLookup, jobName, instanceName, dataSource string, nSolver config.RextNodeSolver, mtrConf config.RextMetricDef) Scrapper {
 	return NumericVec{
 		baseAPIScrapper: baseAPIScrapper{
 			baseScrapper: baseScrapper{
@@ -25,7 +29,8 @@ func newNumericVec(cf client.Factory, p BodyParser, jobName, instanceName, dataS
 			parser:        p,
 			jsonPath:      nSolver.GetNodePath(),
 		},
-		labels: mtrConf.GetLabels(),
+		labels:       mtrConf.GetLabels(),
+		labelLookups: labelLookups,
 	}
 }
 
@@ -39,21 +44,33 @@ type NumericVecItemVal struct {
 type NumericVecVals []NumericVecItemVal
 
 // GetMetric returns a numeric(Gauge or Counter) vector metric by using remote data.
-func (nv NumericVec) GetMetric(metricsCollector chan<- prometheus.Metric) (val interface{}, err error) {
+func (nv NumericVec) GetMetric(ctx context.Context, metricsCollector chan<- prometheus.Metric) (val interface{}, err error) {
 	var iBody interface{}
-	if iBody, err = getData(nv.clientFactory, nv.parser, metricsCollector); err != nil {
+	if iBody, err = getData(ctx, nv.clientFactory, nv.parser, metricsCollector); err != nil {
+		if isTypedClientError(err) {
+			return val, err
+		}
 		log.WithError(err).Errorln("can not get data for numeric vec")
 		return val, config.ErrKeyNotSuccessResponse
 	}
 	var iValColl interface{}
-	if iValColl, err = nv.parser.pathLookup(nv.jsonPath, iBody); err != nil {
+	if iValColl, err = nv.parser.PathLookup(nv.jsonPath, iBody); err != nil {
 		log.WithFields(log.Fields{"err": err, "body": iBody, "path": nv.jsonPath}).Errorln("can not get node from body")
 		return val, config.ErrKeyDecodingFile
 	}
-	metricCollection, okMetricCollection := iValColl.([]interface{})
-	if !okMetricCollection {
-		log.WithField("val", iValColl).Errorln("can not assert value as []interface{}")
-		return val, config.ErrKeyInvalidType
+	metricCollection := nodeCollection(iValColl)
+	labelsVals := make([][]interface{}, len(nv.labels))
+	for idxLabel, label := range nv.labels {
+		var iLabelValColl interface{}
+		ns := label.GetNodeSolver()
+		if iLabelValColl, err = nv.labelLookups[idxLabel].PathLookup(ns.GetNodePath(), iBody); err != nil {
+			log.WithFields(log.Fields{"err": err, "body": iBody, "path": ns.GetNodePath()}).Errorln("can not get node from body")
+			return val, config.ErrKeyDecodingFile
+		}
+		if labelsVals[idxLabel] = nodeCollection(iLabelValColl); len(labelsVals[idxLabel]) != len(metricCollection) {
+			log.WithFields(log.Fields{"labels": labelsVals[idxLabel], "values": metricCollection}).Errorln("the labels and values count are different")
+			return val, config.ErrKeyInvalidType
+		}
 	}
 	metricsVal := make(NumericVecVals, len(metricCollection))
 	for idxIMetricVal, iMetricVal := range metricCollection {
@@ -64,20 +81,7 @@ func (nv NumericVec) GetMetric(metricsCollector chan<- prometheus.Metric) (val i
 		}
 		metricsVal[idxIMetricVal].Val = metricVal
 		metricsVal[idxIMetricVal].Labels = make([]string, len(nv.labels))
-		for idxLabel, label := range nv.labels {
-			var iLabelValColl interface{}
-			ns := label.GetNodeSolver()
-			// FIXME(denisacostaq@gmail.com): This should be optimized, calling pathLookup over iBody multiple times,
-			// aditionally one for each metric, should be only one for each label
-			if iLabelValColl, err = nv.parser.pathLookup(ns.GetNodePath(), iBody); err != nil {
-				log.WithFields(log.Fields{"err": err, "body": iBody, "path": ns.GetNodePath()}).Errorln("can not get node from body")
-				return val, config.ErrKeyDecodingFile
-			}
-			iLabelVals, okLabelVal := iLabelValColl.([]interface{})
-			if !okLabelVal {
-				log.WithField("val", iLabelValColl).Errorln("can not assert value as []interface{}")
-				return val, config.ErrKeyInvalidType
-			}
+		for idxLabel, iLabelVals := range labelsVals {
 			labelVal, okLabelVal := iLabelVals[idxIMetricVal].(string)
 			if !okLabelVal {
 				log.WithField("val", iLabelVals[idxIMetricVal]).Errorln("can not assert value as string")
@@ -0,0 +1,164 @@
+package scrapper
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// PlainTextParser is a body parser that keep the body as text, the nodes are found by line with a LineSolver by
+// default or with a RegexSolver
+type PlainTextParser struct {
+	LineSolver
+}
+
+// DecodeBody return the body as a string
+func (p PlainTextParser) DecodeBody(body []byte) (val interface{}, err error) {
+	return string(body), nil
+}
+
+// plainText return the text in a document decoded by a PlainTextParser
+func plainText(doc interface{}) (text string, err error) {
+	text, okText := doc.(string)
+	if !okText {
+		log.WithField("val", doc).Errorln("value is not a plain text document")
+		return "", config.ErrKeyInvalidType
+	}
+	return text, nil
+}
+
+// nodeOrCollection return the only item in vals or vals if there are more than one, like a xpath expression
+func nodeOrCollection(vals []interface{}) interface{} {
+	if len(vals) == 1 {
+		return vals[0]
+	}
+	return vals
+}
+
+// LineSolver find the nodes in a plain text by line, the path is a line number(starting in 1) like "3" or a key
+// prefix like "connections:". For a prefix the node is the text after it in each line starting with it.
+type LineSolver struct {
+}
+
+// PathLookup return the line(or the lines for a prefix) in path decoded as a number if possible
+func (s LineSolver) PathLookup(path string, doc interface{}) (node interface{}, err error) {
+	if len(path) == 0 {
+		log.Errorln("node path is required")
+		return nil, config.ErrKeyEmptyValue
+	}
+	generalScopeErr := "error looking for line in val"
+	var text string
+	if text, err = plainText(doc); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	if lineNumber, err := strconv.Atoi(path); err == nil {
+		if lineNumber < 1 || lineNumber > len(lines) {
+			errCause := fmt.Sprintf("line %d out of range, the text have %d lines", lineNumber, len(lines))
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		return textValue(strings.TrimSpace(lines[lineNumber-1])), nil
+	}
+	var vals []interface{}
+	for _, line := range lines {
+		line = strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(line, path) {
+			vals = append(vals, textValue(strings.TrimSpace(strings.TrimPrefix(line, path))))
+		}
+	}
+	if len(vals) == 0 {
+		errCause := fmt.Sprintf("can not find a line starting with %s", path)
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return nodeOrCollection(vals), nil
+}
+
+// regexValueGroup is the capture group used for the node value if a group is not configured
+const regexValueGroup = "value"
+
+// RegexSolver find the nodes in a plain text with a regular expression, every match is a node
+type RegexSolver struct {
+	group string
+}
+
+// NewRegexSolver create a RegexSolver with the capture group in the node solver options
+func NewRegexSolver(nodeSolverOpts config.RextKeyValueStore) RegexSolver {
+	var s RegexSolver
+	if nodeSolverOpts != nil {
+		s.group, _ = nodeSolverOpts.GetString(config.OptKeyRextNodeSolverRegexGroup)
+	}
+	return s
+}
+
+// regexps are the compiled regular expressions
+var regexps = newCompiledPaths(func(path string) (interface{}, error) {
+	return regexp.Compile(path)
+})
+
+// ValidatePath compile the regular expression
+func (s RegexSolver) ValidatePath(path string) error {
+	_, err := regexps.get(path)
+	return err
+}
+
+// subexpIndex return the index of the group with name in re or -1 if there is no such group
+func subexpIndex(re *regexp.Regexp, name string) int {
+	for idx, subexpName := range re.SubexpNames() {
+		if idx != 0 && subexpName == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+// PathLookup return the configured capture group of each match, or the group named value, the first group or the
+// whole match if the group is not configured. The value group is decoded as a number if possible, the other ones
+// are kept as strings to be used as label values.
+func (s RegexSolver) PathLookup(path string, doc interface{}) (node interface{}, err error) {
+	if len(path) == 0 {
+		log.Errorln("node path is required")
+		return nil, config.ErrKeyEmptyValue
+	}
+	generalScopeErr := "error looking for regular expression matches in val"
+	var text string
+	if text, err = plainText(doc); err != nil {
+		return nil, err
+	}
+	var compiled interface{}
+	if compiled, err = regexps.get(path); err != nil {
+		errCause := fmt.Sprintln("can not compile the regular expression: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	re := compiled.(*regexp.Regexp)
+	group, isValue := 0, true
+	switch {
+	case len(s.group) != 0:
+		if group = subexpIndex(re, s.group); group == -1 {
+			errCause := fmt.Sprintf("the regular expression have not a group named %s", s.group)
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		isValue = s.group == regexValueGroup
+	case subexpIndex(re, regexValueGroup) != -1:
+		group = subexpIndex(re, regexValueGroup)
+	case re.NumSubexp() != 0:
+		group = 1
+	}
+	var vals []interface{}
+	for _, match := range re.FindAllStringSubmatch(text, -1) {
+		if isValue {
+			vals = append(vals, textValue(strings.TrimSpace(match[group])))
+		} else {
+			vals = append(vals, match[group])
+		}
+	}
+	if len(vals) == 0 {
+		errCause := fmt.Sprintf("the regular expression %s does not match", path)
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return nodeOrCollection(vals), nil
+}
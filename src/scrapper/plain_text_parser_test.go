@@ -0,0 +1,145 @@
+package scrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+const plainTextStatus = `node status
+connections: 12
+peer 139.162.161.41:20002 height=180
+peer 185.120.34.60:6000 height=57
+uptime: 6m30s
+`
+
+type plainTextParserSuit struct {
+	suite.Suite
+	doc interface{}
+}
+
+func TestPlainTextParserSuit(t *testing.T) {
+	suite.Run(t, new(plainTextParserSuit))
+}
+
+func (suite *plainTextParserSuit) SetupTest() {
+	var err error
+	suite.doc, err = PlainTextParser{}.DecodeBody([]byte(plainTextStatus))
+	suite.Require().Nil(err)
+}
+
+func (suite *plainTextParserSuit) TestLineSolver() {
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: "1", expected: "node status"},
+		{path: "connections:", expected: float64(12)},
+		{path: "uptime:", expected: "6m30s"},
+		{path: "peer ", expected: []interface{}{"139.162.161.41:20002 height=180", "185.120.34.60:6000 height=57"}},
+	}
+	for _, test := range tests {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := LineSolver{}.PathLookup(test.path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.Nil(err, test.path)
+		suite.Equal(test.expected, node, test.path)
+	}
+}
+
+func (suite *plainTextParserSuit) TestLineSolverErrors() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	paths := []string{"0", "100", "missing:"}
+	for _, path := range paths {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := LineSolver{}.PathLookup(path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.NotNil(err, path)
+		suite.Nil(node, path)
+	}
+	_, err := LineSolver{}.PathLookup("1", map[string]interface{}{})
+	suite.Equal(config.ErrKeyInvalidType, err)
+}
+
+func (suite *plainTextParserSuit) TestRegexSolver() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	opts := memconfig.NewOptionsMap()
+	_, err := opts.SetString(config.OptKeyRextNodeSolverRegexGroup, "address")
+	suite.Nil(err)
+	tests := []struct {
+		solver   RegexSolver
+		path     string
+		expected interface{}
+	}{
+		{solver: RegexSolver{}, path: `connections: (\d+)`, expected: float64(12)},
+		{solver: RegexSolver{}, path: `height=(?P<value>\d+)`, expected: []interface{}{float64(180), float64(57)}},
+		{solver: RegexSolver{}, path: `\d+m\d+s`, expected: "6m30s"},
+		{solver: NewRegexSolver(opts), path: `peer (?P<address>\S+) height=(?P<value>\d+)`, expected: []interface{}{"139.162.161.41:20002", "185.120.34.60:6000"}},
+	}
+	for _, test := range tests {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := test.solver.PathLookup(test.path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.Nil(err, test.path)
+		suite.Equal(test.expected, node, test.path)
+	}
+}
+
+func (suite *plainTextParserSuit) TestRegexSolverErrors() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	tests := []struct {
+		solver RegexSolver
+		path   string
+	}{
+		{solver: RegexSolver{}, path: `connections: (\d+`},
+		{solver: RegexSolver{}, path: `blocks: (\d+)`},
+		{solver: RegexSolver{group: "peer"}, path: `height=(?P<value>\d+)`},
+	}
+	for _, test := range tests {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := test.solver.PathLookup(test.path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.NotNil(err, test.path)
+		suite.Nil(node, test.path)
+	}
+}
+
+func (suite *plainTextParserSuit) TestRegexVec() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	const path = `peer (?P<address>\S+) height=(?P<value>\d+)`
+	srvConf := memconfig.NewServiceConf("localhost:6420", "http", nil, nil, memconfig.NewOptionsMap())
+	_, err := srvConf.GetOptions().SetString(config.OptKeyRextServiceDefJobName, "skycoin")
+	suite.Nil(err)
+	_, err = srvConf.GetOptions().SetString(config.OptKeyRextServiceDefInstanceName, "localhost:6420")
+	suite.Nil(err)
+	resConf := memconfig.NewResourceDef(config.ResourceTypeRestAPI, "/status", nil, nil, memconfig.NewDecoder(config.DecoderPlainText, nil), nil)
+	nSolver := memconfig.NewNodeSolver(config.RextNodeSolverTypeRegex, path, nil)
+	lSolverOpts := memconfig.NewOptionsMap()
+	_, err = lSolverOpts.SetString(config.OptKeyRextNodeSolverRegexGroup, "address")
+	suite.Nil(err)
+	mtrConf := &memconfig.MetricDef{}
+	mtrConf.SetMetricType(config.KeyMetricTypeGauge)
+	mtrConf.AddLabel(memconfig.NewLabelDef("address", memconfig.NewNodeSolver(config.RextNodeSolverTypeRegex, path, lSolverOpts)))
+	parser, err := NewBodyParser(resConf.GetDecoder(), nSolver)
+	suite.Require().Nil(err)
+	s, err := NewScrapper(xmlClient{body: plainTextStatus}, parser, resConf, srvConf, mtrConf, nSolver)
+	suite.Require().Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := s.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(NumericVecVals{
+		{Val: 180, Labels: []string{"139.162.161.41:20002"}},
+		{Val: 57, Labels: []string{"185.120.34.60:6000"}},
+	}, val)
+}
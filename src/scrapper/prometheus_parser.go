@@ -0,0 +1,276 @@
+package scrapper
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// promSample is a single series value in a Prometheus exposition, the histograms and summaries are flattened in
+// their _bucket, _sum and _count series like in the text format
+type promSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// PrometheusParser decode a body in the Prometheus text exposition format and find the series values with a selector
+// like process_open_fds{job="x"}
+type PrometheusParser struct {
+}
+
+// DecodeBody decode the body in a list of series sorted by metric family name
+func (p PrometheusParser) DecodeBody(body []byte) (val interface{}, err error) {
+	generalScopeErr := "error decoding a prometheus exposition"
+	var parser expfmt.TextParser
+	var families map[string]*dto.MetricFamily
+	if families, err = parser.TextToMetricFamilies(bytes.NewReader(body)); err != nil {
+		log.WithError(err).Errorln("can not parse the prometheus exposition")
+		errCause := fmt.Sprintln("can not parse the prometheus exposition: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	samples := []promSample{}
+	for _, name := range names {
+		for _, metric := range families[name].GetMetric() {
+			samples = append(samples, promSamples(name, metric)...)
+		}
+	}
+	return samples, nil
+}
+
+// promSamples flatten a metric in its series
+func promSamples(name string, metric *dto.Metric) (samples []promSample) {
+	labels := make(map[string]string, len(metric.GetLabel()))
+	for _, label := range metric.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	withLabel := func(labelName string, labelValue float64) map[string]string {
+		sampleLabels := make(map[string]string, len(labels)+1)
+		for k, v := range labels {
+			sampleLabels[k] = v
+		}
+		sampleLabels[labelName] = strconv.FormatFloat(labelValue, 'g', -1, 64)
+		return sampleLabels
+	}
+	switch {
+	case metric.GetGauge() != nil:
+		samples = append(samples, promSample{name: name, labels: labels, value: metric.GetGauge().GetValue()})
+	case metric.GetCounter() != nil:
+		samples = append(samples, promSample{name: name, labels: labels, value: metric.GetCounter().GetValue()})
+	case metric.GetUntyped() != nil:
+		samples = append(samples, promSample{name: name, labels: labels, value: metric.GetUntyped().GetValue()})
+	case metric.GetSummary() != nil:
+		summary := metric.GetSummary()
+		for _, quantile := range summary.GetQuantile() {
+			samples = append(samples, promSample{name: name, labels: withLabel("quantile", quantile.GetQuantile()), value: quantile.GetValue()})
+		}
+		samples = append(samples, promSample{name: name + "_sum", labels: labels, value: summary.GetSampleSum()})
+		samples = append(samples, promSample{name: name + "_count", labels: labels, value: float64(summary.GetSampleCount())})
+	case metric.GetHistogram() != nil:
+		histogram := metric.GetHistogram()
+		for _, bucket := range histogram.GetBucket() {
+			samples = append(samples, promSample{name: name + "_bucket", labels: withLabel("le", bucket.GetUpperBound()), value: float64(bucket.GetCumulativeCount())})
+		}
+		samples = append(samples, promSample{name: name + "_sum", labels: labels, value: histogram.GetSampleSum()})
+		samples = append(samples, promSample{name: name + "_count", labels: labels, value: float64(histogram.GetSampleCount())})
+	}
+	return samples
+}
+
+// promMatcher match the value of a label like job="x", job!="x", job=~"x.*" or job!~"x.*"
+type promMatcher struct {
+	label string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+// matches return true if the label value in labels satisfy the matcher, a missing label match an empty value
+func (m promMatcher) matches(name string, labels map[string]string) bool {
+	val := labels[m.label]
+	if m.label == "__name__" {
+		val = name
+	}
+	switch m.op {
+	case "=":
+		return val == m.value
+	case "!=":
+		return val != m.value
+	case "=~":
+		return m.re.MatchString(val)
+	default:
+		return !m.re.MatchString(val)
+	}
+}
+
+// promSelector select the series with a metric name and label matchers, and optionally return the values of a label
+// instead of the series values
+type promSelector struct {
+	name     string
+	matchers []promMatcher
+	label    string
+}
+
+var (
+	promNameRe       = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*`)
+	promLabelNameRe  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`)
+	promLabelValueRe = regexp.MustCompile(`^"(?:[^"\\]|\\.)*"`)
+)
+
+const promLabelValuesFunc = "label_values("
+
+// promSelectors are the parsed series selectors
+var promSelectors = newCompiledPaths(func(path string) (interface{}, error) {
+	return parsePromSelector(path)
+})
+
+// parsePromSelector parse a path like process_open_fds{job="x",instance=~"node.*"}, or
+// label_values(process_open_fds{job="x"}, instance) to select the values of the instance label
+func parsePromSelector(path string) (sel promSelector, err error) {
+	generalScopeErr := "error parsing a prometheus series selector"
+	expr := strings.TrimSpace(path)
+	if strings.HasPrefix(expr, promLabelValuesFunc) {
+		if !strings.HasSuffix(expr, ")") {
+			return sel, util.ErrorFromThisScope("missing ) closing label_values", generalScopeErr)
+		}
+		args := strings.TrimSuffix(strings.TrimPrefix(expr, promLabelValuesFunc), ")")
+		idx := strings.LastIndex(args, ",")
+		if idx == -1 {
+			return sel, util.ErrorFromThisScope("label_values require a selector and a label name", generalScopeErr)
+		}
+		sel.label = strings.TrimSpace(args[idx+1:])
+		if promLabelNameRe.FindString(sel.label) != sel.label || len(sel.label) == 0 {
+			errCause := fmt.Sprintf("invalid label name %q in label_values", sel.label)
+			return sel, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		expr = strings.TrimSpace(args[:idx])
+	}
+	sel.name = promNameRe.FindString(expr)
+	rest := strings.TrimSpace(expr[len(sel.name):])
+	if len(rest) != 0 {
+		if sel.matchers, err = parsePromMatchers(rest); err != nil {
+			return sel, util.ErrorFromThisScope(err.Error(), generalScopeErr)
+		}
+	}
+	if len(sel.name) == 0 && len(sel.matchers) == 0 {
+		errCause := fmt.Sprintf("selector %q require a metric name or a label matcher", path)
+		return sel, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return sel, nil
+}
+
+// parsePromMatchers parse the label matchers between braces like {job="x", instance!~"node.*"}
+func parsePromMatchers(expr string) (matchers []promMatcher, err error) {
+	if !strings.HasPrefix(expr, "{") || !strings.HasSuffix(expr, "}") {
+		return nil, fmt.Errorf("unexpected %q, the label matchers should be between braces", expr)
+	}
+	rest := strings.TrimSpace(expr[1 : len(expr)-1])
+	for len(rest) != 0 {
+		var m promMatcher
+		if m.label = promLabelNameRe.FindString(rest); len(m.label) == 0 {
+			return nil, fmt.Errorf("expected a label name in %q", rest)
+		}
+		rest = strings.TrimSpace(rest[len(m.label):])
+		for _, op := range []string{"=~", "!~", "!=", "="} {
+			if strings.HasPrefix(rest, op) {
+				m.op = op
+				break
+			}
+		}
+		if len(m.op) == 0 {
+			return nil, fmt.Errorf("expected =, !=, =~ or !~ after label %s", m.label)
+		}
+		rest = strings.TrimSpace(rest[len(m.op):])
+		quoted := promLabelValueRe.FindString(rest)
+		if len(quoted) == 0 {
+			return nil, fmt.Errorf("expected a double quoted value for label %s", m.label)
+		}
+		if m.value, err = strconv.Unquote(quoted); err != nil {
+			return nil, fmt.Errorf("invalid value %s for label %s: %s", quoted, m.label, err.Error())
+		}
+		if m.op == "=~" || m.op == "!~" {
+			if m.re, err = regexp.Compile("^(?:" + m.value + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid regular expression for label %s: %s", m.label, err.Error())
+			}
+		}
+		matchers = append(matchers, m)
+		rest = strings.TrimSpace(rest[len(quoted):])
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		} else if len(rest) != 0 {
+			return nil, fmt.Errorf("expected , or } before %q", rest)
+		}
+	}
+	return matchers, nil
+}
+
+// selects return true if the series have the selector name and satisfy all the matchers
+func (sel promSelector) selects(sample promSample) bool {
+	if len(sel.name) != 0 && sel.name != sample.name {
+		return false
+	}
+	for _, m := range sel.matchers {
+		if !m.matches(sample.name, sample.labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidatePath check the series selector syntax
+func (p PrometheusParser) ValidatePath(path string) error {
+	_, err := promSelectors.get(path)
+	return err
+}
+
+// PathLookup return the values of the series selected by path in the exposition order, or the label values for a
+// label_values(selector, label) path. A single series is returned as a value and many as a collection.
+func (p PrometheusParser) PathLookup(path string, doc interface{}) (node interface{}, err error) {
+	if len(path) == 0 {
+		log.Errorln("node path is required")
+		return nil, config.ErrKeyEmptyValue
+	}
+	generalScopeErr := "error looking for series in a prometheus exposition"
+	samples, okSamples := doc.([]promSample)
+	if !okSamples {
+		log.WithField("val", doc).Errorln("value is not a prometheus exposition")
+		return nil, config.ErrKeyInvalidType
+	}
+	var compiled interface{}
+	if compiled, err = promSelectors.get(path); err != nil {
+		return nil, err
+	}
+	sel := compiled.(promSelector)
+	var vals []interface{}
+	for _, sample := range samples {
+		if !sel.selects(sample) {
+			continue
+		}
+		if sel.label == "__name__" {
+			vals = append(vals, sample.name)
+		} else if len(sel.label) != 0 {
+			vals = append(vals, sample.labels[sel.label])
+		} else {
+			vals = append(vals, sample.value)
+		}
+	}
+	if len(vals) == 0 {
+		errCause := fmt.Sprintf("no series selected by %s", path)
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return nodeOrCollection(vals), nil
+}
@@ -0,0 +1,132 @@
+package scrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+const promExposition = `# HELP process_open_fds Number of open file descriptors.
+# TYPE process_open_fds gauge
+process_open_fds{job="skycoin",instance="node1"} 12
+process_open_fds{job="skycoin",instance="node2"} 17
+process_open_fds{job="explorer",instance="node1"} 40
+# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{code="200",path="/api/v1/health"} 1027
+http_requests_total{code="500",path="/api/v1/health"} 3
+# HELP request_duration_seconds Request duration.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 30
+request_duration_seconds_bucket{le="1"} 45
+request_duration_seconds_bucket{le="+Inf"} 50
+request_duration_seconds_sum 21.5
+request_duration_seconds_count 50
+`
+
+type prometheusParserSuit struct {
+	suite.Suite
+	doc interface{}
+}
+
+func TestPrometheusParserSuit(t *testing.T) {
+	suite.Run(t, new(prometheusParserSuit))
+}
+
+func (suite *prometheusParserSuit) SetupTest() {
+	var err error
+	suite.doc, err = PrometheusParser{}.DecodeBody([]byte(promExposition))
+	suite.Require().Nil(err)
+}
+
+func (suite *prometheusParserSuit) TestPathLookup() {
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: `process_open_fds{job="skycoin"}`, expected: []interface{}{float64(12), float64(17)}},
+		{path: `process_open_fds{job="skycoin", instance="node2"}`, expected: float64(17)},
+		{path: `process_open_fds{job!="skycoin"}`, expected: float64(40)},
+		{path: `http_requests_total{code=~"5.."}`, expected: float64(3)},
+		{path: `http_requests_total{code!~"5.."}`, expected: float64(1027)},
+		{path: `{__name__=~"request_duration_seconds_(sum|count)"}`, expected: []interface{}{float64(21.5), float64(50)}},
+		{path: `request_duration_seconds_bucket{le="+Inf"}`, expected: float64(50)},
+		{path: `label_values(process_open_fds{job="skycoin"}, instance)`, expected: []interface{}{"node1", "node2"}},
+		{path: `label_values(http_requests_total, path)`, expected: []interface{}{"/api/v1/health", "/api/v1/health"}},
+	}
+	for _, test := range tests {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := PrometheusParser{}.PathLookup(test.path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.Nil(err, test.path)
+		suite.Equal(test.expected, node, test.path)
+	}
+}
+
+func (suite *prometheusParserSuit) TestInvalidPaths() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	paths := []string{
+		`process_open_fds{job="skycoin"`,
+		`process_open_fds{job=skycoin}`,
+		`process_open_fds{job=="skycoin"}`,
+		`process_open_fds{job=~"("}`,
+		`{}`,
+		`label_values(process_open_fds)`,
+		`label_values(process_open_fds, 1nstance)`,
+		`process_open_fds{job="missing"}`,
+	}
+	for _, path := range paths {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := PrometheusParser{}.PathLookup(path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.NotNil(err, path)
+		suite.Nil(node, path)
+	}
+}
+
+func (suite *prometheusParserSuit) TestInvalidBody() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "# TYPE process_open_fds gauge\nprocess_open_fds{job=\"skycoin\" 12\n"
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := PrometheusParser{}.DecodeBody([]byte(body))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Nil(val)
+}
+
+func (suite *prometheusParserSuit) TestNumericVec() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srvConf := memconfig.NewServiceConf("localhost:6420", "http", nil, nil, memconfig.NewOptionsMap())
+	_, err := srvConf.GetOptions().SetString(config.OptKeyRextServiceDefJobName, "skycoin")
+	suite.Nil(err)
+	_, err = srvConf.GetOptions().SetString(config.OptKeyRextServiceDefInstanceName, "localhost:6420")
+	suite.Nil(err)
+	resConf := memconfig.NewResourceDef(config.ResourceTypeRestAPI, "/metrics", nil, nil, memconfig.NewDecoder(config.DecoderPrometheus, nil), nil)
+	nSolver := memconfig.NewNodeSolver(config.RextNodeSolverTypePrometheus, `process_open_fds{job="skycoin"}`, nil)
+	mtrConf := &memconfig.MetricDef{}
+	mtrConf.SetMetricName("skycoin_open_fds")
+	mtrConf.SetMetricType(config.KeyMetricTypeGauge)
+	mtrConf.AddLabel(memconfig.NewLabelDef("node", memconfig.NewNodeSolver(config.RextNodeSolverTypePrometheus, `label_values(process_open_fds{job="skycoin"}, instance)`, nil)))
+	parser, err := NewBodyParser(resConf.GetDecoder(), nSolver)
+	suite.Require().Nil(err)
+	s, err := NewScrapper(xmlClient{body: promExposition}, parser, resConf, srvConf, mtrConf, nSolver)
+	suite.Require().Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := s.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(NumericVecVals{
+		{Val: 12, Labels: []string{"node1"}},
+		{Val: 17, Labels: []string{"node2"}},
+	}, val)
+}
@@ -1,7 +1,9 @@
 package scrapper
 
 import (
+	"context"
 	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,8 +15,9 @@ import (
 
 // Scrapper get metrics from raw data
 type Scrapper interface {
-	// GetMetric recive the metrics collector channel and should return the metric val
-	GetMetric(metricsCollector chan<- prometheus.Metric) (val interface{}, err error)
+	// GetMetric recive the metrics collector channel and should return the metric val, it should give up when
+	// ctx is done
+	GetMetric(ctx context.Context, metricsCollector chan<- prometheus.Metric) (val interface{}, err error)
 	GetJobName() string
 	GetInstanceName() string
 	GetDataSource() string
@@ -22,8 +25,8 @@ type Scrapper interface {
 
 // FordwaderScrapper get metrics from an already metrics endpoint
 type FordwaderScrapper interface {
-	// GetMetric should return the metrics vals as raw string
-	GetMetric() (val interface{}, err error)
+	// GetMetric should return the metrics vals as raw string, it should give up when ctx is done
+	GetMetric(ctx context.Context) (val interface{}, err error)
 	GetJobName() string
 	GetInstanceName() string
 }
@@ -60,8 +63,8 @@ func (s baseAPIScrapper) GetDataSource() string {
 
 // BodyParser decode body from different formats, an get some data node
 type BodyParser interface {
-	decodeBody(body []byte) (val interface{}, err error)
-	pathLookup(path string, val interface{}) (node interface{}, err error)
+	config.BodyDecoder
+	config.NodeLookup
 }
 
 // NewScrapper will put all the required info to scrap metrics from the body returned by the client.
@@ -79,14 +82,29 @@ func NewScrapper(cf client.Factory, parser BodyParser, resConf config.RextResour
 		return scrapper, err
 	}
 	if len(mtrConf.GetLabels()) > 0 {
-		return createVecScrapper(cf, parser, jobName, instanceName, dataSource, nSolver, mtrConf)
+		var decoderType string
+		if resConf.GetDecoder() != nil {
+			decoderType = resConf.GetDecoder().GetType()
+		}
+		return createVecScrapper(cf, parser, decoderType, jobName, instanceName, dataSource, nSolver, mtrConf)
 	}
 	return createAtomicScrapper(cf, parser, jobName, instanceName, dataSource, mtrConf, nSolver)
 }
 
-func createVecScrapper(cf client.Factory, parser BodyParser, jobName, instanceName, dataSource string, nSolver config.RextNodeSolver, mtrConf config.RextMetricDef) (scrapper Scrapper, err error) {
+func createVecScrapper(cf client.Factory, parser BodyParser, decoderType, jobName, instanceName, dataSource string, nSolver config.RextNodeSolver, mtrConf config.RextMetricDef) (scrapper Scrapper, err error) {
 	if mtrConf.GetMetricType() == config.KeyMetricTypeCounter || mtrConf.GetMetricType() == config.KeyMetricTypeGauge {
-		return newNumericVec(cf, parser, jobName, instanceName, dataSource, nSolver, mtrConf), nil
+		labelLookups := make([]config.NodeLookup, len(mtrConf.GetLabels()))
+		for idx, label := range mtrConf.GetLabels() {
+			if labelLookups[idx], err = config.NewNodeLookup(decoderType, label.GetNodeSolver()); err != nil {
+				log.WithFields(log.Fields{"err": err, "label": label.GetName()}).Errorln("can not create the label node solver")
+				return NumericVec{}, err
+			}
+			if err = precompilePath(labelLookups[idx], label.GetNodeSolver().GetNodePath()); err != nil {
+				log.WithFields(log.Fields{"err": err, "label": label.GetName()}).Errorln("can not compile the label path")
+				return NumericVec{}, err
+			}
+		}
+		return newNumericVec(cf, parser, labelLookups, jobName, instanceName, dataSource, nSolver, mtrConf), nil
 	}
 	log.WithError(errors.New("histogram vec and summary vec are not supported yet")).Errorln("invalid operation")
 	return NumericVec{}, config.ErrKeyNotSupported
@@ -113,19 +131,56 @@ func createAtomicScrapper(cf client.Factory, parser BodyParser, jobName, instanc
 	return newNumeric(cf, parser, nSolver.GetNodePath(), jobName, instanceName, dataSource), nil
 }
 
-func getData(cf client.Factory, p BodyParser, metricsCollector chan<- prometheus.Metric) (data interface{}, err error) {
+// isTypedClientError return true for the client errors returned as they are, so the collector can handle them
+func isTypedClientError(err error) bool {
+	return client.IsCircuitOpen(err) || client.IsJSONRPCError(err) || client.IsGraphQLError(err)
+}
+
+// textValue decode a text as float64 if it is a number, as string otherwise
+func textValue(text string) interface{} {
+	if num, err := strconv.ParseFloat(text, 64); err == nil {
+		return num
+	}
+	return text
+}
+
+// nodeCollection return the node as a collection, a single value is a collection with one item, like a xpath
+// expression matching only one node
+func nodeCollection(node interface{}) []interface{} {
+	if collection, okCollection := node.([]interface{}); okCollection {
+		return collection
+	}
+	return []interface{}{node}
+}
+
+// getData return the decoded data, it is decoded once for all the metrics reading the same data in a scrape if the
+// client can share the decoded document
+func getData(ctx context.Context, cf client.Factory, p BodyParser, metricsCollector chan<- prometheus.Metric) (data interface{}, err error) {
 	const generalScopeErr = "error getting data"
 	var cl client.Client
 	if cl, err = cf.CreateClient(); err != nil {
 		errCause := "can ot create client"
 		return data, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
+	if dcl, isDocumentClient := cl.(client.DocumentClient); isDocumentClient {
+		if data, err = dcl.GetDocument(ctx, metricsCollector, parserDecoderKey(p), p.DecodeBody); err != nil {
+			if isTypedClientError(err) {
+				return data, err
+			}
+			errCause := "client can not get or decode the data"
+			return data, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		return data, nil
+	}
 	var body []byte
-	if body, err = cl.GetData(metricsCollector); err != nil {
+	if body, err = cl.GetData(ctx, metricsCollector); err != nil {
+		if isTypedClientError(err) {
+			return data, err
+		}
 		errCause := "client can not get data"
 		return data, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
-	if data, err = p.decodeBody(body); err != nil {
+	if data, err = p.DecodeBody(body); err != nil {
 		errCause := "scrapper can not decode the body"
 		return data, util.ErrorFromThisScope(errCause, generalScopeErr)
 	}
@@ -0,0 +1,200 @@
+package scrapper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/cache"
+	"github.com/simelo/rextporter/src/client"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+const skycoinHealth = `{
+	"blockchain": {
+		"head": {"seq": 58894, "fee": 485194, "timestamp": 1537581604},
+		"unspents": 38171,
+		"unconfirmed": 1
+	},
+	"open_connections": 8
+}`
+
+// countingClient return the same body always and count the requests
+type countingClient struct {
+	body     string
+	requests *int32
+}
+
+func (cl countingClient) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) ([]byte, error) {
+	atomic.AddInt32(cl.requests, 1)
+	return []byte(cl.body), nil
+}
+
+func (cl countingClient) DataPath() string {
+	return "http://localhost:6420/api/v1/health"
+}
+
+func (cl countingClient) CreateClient() (client.CacheableClient, error) {
+	return cl, nil
+}
+
+// countingParser count the decoded bodies
+type countingParser struct {
+	JSONParser
+	decodes *int32
+}
+
+func (p countingParser) DecodeBody(body []byte) (interface{}, error) {
+	atomic.AddInt32(p.decodes, 1)
+	return p.JSONParser.DecodeBody(body)
+}
+
+type sharedDocumentSuit struct {
+	suite.Suite
+	requests, decodes int32
+	cache             cache.Cache
+	cf                client.Factory
+	parser            BodyParser
+}
+
+func TestSharedDocumentSuit(t *testing.T) {
+	suite.Run(t, new(sharedDocumentSuit))
+}
+
+func (suite *sharedDocumentSuit) SetupTest() {
+	suite.requests, suite.decodes = 0, 0
+	suite.cache = cache.NewCache()
+	suite.cf = client.CatcherCreator{Cache: suite.cache, ClientFactory: countingClient{body: skycoinHealth, requests: &suite.requests}}
+	suite.parser = countingParser{decodes: &suite.decodes}
+}
+
+func (suite *sharedDocumentSuit) TestDecodeOncePerScrape() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	scrappers := []Scrapper{
+		newNumeric(suite.cf, suite.parser, "/blockchain/head/seq", "skycoin", "localhost:6420", "/api/v1/health"),
+		newNumeric(suite.cf, suite.parser, "/blockchain/unspents", "skycoin", "localhost:6420", "/api/v1/health"),
+		newHistogram(suite.cf, suite.parser, "/api/v1/health", "skycoin", "localhost:6420", "/open_connections", histogramClientOptions{10}),
+	}
+	expected := []interface{}{float64(58894), float64(38171), HistogramValue{Count: 1, Sum: 8, Buckets: map[float64]uint64{10: 1}}}
+
+	for scrape := 0; scrape < 2; scrape++ {
+		// NOTE(denisacostaq@gmail.com): When
+		for idx, s := range scrappers {
+			val, err := s.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+
+			// NOTE(denisacostaq@gmail.com): Assert
+			suite.Nil(err)
+			suite.Equal(expected[idx], val)
+		}
+		suite.Equal(int32(scrape+1), atomic.LoadInt32(&suite.requests))
+		suite.Equal(int32(scrape+1), atomic.LoadInt32(&suite.decodes))
+		suite.cache.Reset()
+	}
+}
+
+func (suite *sharedDocumentSuit) TestDecodeOncePerDecoder() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	jsonParser, err := NewBodyParser(memconfig.NewDecoder(config.DecoderJSON, nil), memconfig.NewNodeSolver("", "/open_connections", nil))
+	suite.Require().Nil(err)
+	textParser, err := NewBodyParser(memconfig.NewDecoder(config.DecoderPlainText, nil), memconfig.NewNodeSolver(config.RextNodeSolverTypeRegex, `"seq": (\d+)`, nil))
+	suite.Require().Nil(err)
+
+	// NOTE(denisacostaq@gmail.com): When
+	jsonDoc, errJSON := getData(context.Background(), suite.cf, jsonParser, make(chan prometheus.Metric, 10))
+	textDoc, errText := getData(context.Background(), suite.cf, textParser, make(chan prometheus.Metric, 10))
+	seq, errSeq := textParser.PathLookup(`"seq": (\d+)`, textDoc)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(errJSON)
+	suite.Nil(errText)
+	suite.Nil(errSeq)
+	suite.IsType(map[string]interface{}{}, jsonDoc)
+	suite.Equal(float64(58894), seq)
+	suite.Equal(int32(1), atomic.LoadInt32(&suite.requests))
+}
+
+func (suite *sharedDocumentSuit) TestInvalidPathFailOnCreation() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	decoderDef := memconfig.NewDecoder(config.DecoderJSON, nil)
+	nSolver := memconfig.NewNodeSolver(config.RextNodeSolverTypeJQ, ".connections[", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	_, err := NewBodyParser(decoderDef, nSolver)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+}
+
+type streamCountSuit struct {
+	suite.Suite
+	testServer *httptest.Server
+}
+
+func TestStreamCountSuit(t *testing.T) {
+	suite.Run(t, new(streamCountSuit))
+}
+
+func (suite *streamCountSuit) SetupTest() {
+	suite.testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for seq := 1; seq <= 3; seq++ {
+			fmt.Fprintf(w, "data: {\"seq\": %d}\n\n", seq)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+}
+
+func (suite *streamCountSuit) TearDownTest() {
+	client.ResetSharedState()
+	suite.testServer.CloseClientConnections()
+	suite.testServer.Close()
+}
+
+func (suite *streamCountSuit) TestScrapTheMessagesCount() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	srv := memconfig.NewServiceConf(suite.testServer.URL, "http", nil, nil, memconfig.NewOptionsMap())
+	_, err := srv.GetOptions().SetString(config.OptKeyRextServiceDefJobName, "node")
+	suite.Require().Nil(err)
+	_, err = srv.GetOptions().SetString(config.OptKeyRextServiceDefInstanceName, "localhost:6420")
+	suite.Require().Nil(err)
+	res := memconfig.NewResourceDef(config.ResourceTypeStream, "/events", nil, nil, nil, memconfig.NewOptionsMap())
+	_, err = res.GetOptions().SetString(config.OptKeyRextResourceDefStreamKind, config.StreamSSE)
+	suite.Require().Nil(err)
+	_, err = res.GetOptions().SetObject(config.OptKeyRextResourceDefStreamWithCount, true)
+	suite.Require().Nil(err)
+	desc := prometheus.NewDesc("data_source_response_duration_seconds", "", []string{"job", "instance", "data_source"}, nil)
+	streamCreator, err := client.CreateStreamCreator(res, srv, desc, nil)
+	suite.Require().Nil(err)
+	c := cache.NewCache()
+	cf := client.CatcherCreator{Cache: c, ClientFactory: streamCreator}
+	parser, err := NewBodyParser(memconfig.NewDecoder(config.DecoderJSON, nil), memconfig.NewNodeSolver("", "/count", nil))
+	suite.Require().Nil(err)
+	messages := newNumeric(cf, parser, "/count", "node", "localhost:6420", "/events")
+	seq := newNumeric(cf, parser, "/latest/seq", "node", "localhost:6420", "/events")
+
+	// NOTE(denisacostaq@gmail.com): When
+	var count, latest interface{}
+	var errCount, errLatest error
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		c.Reset()
+		count, errCount = messages.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+		latest, errLatest = seq.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+		if count == float64(3) {
+			break
+		}
+	}
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(errCount)
+	suite.Nil(errLatest)
+	suite.Equal(float64(3), count)
+	suite.Equal(float64(3), latest)
+}
@@ -0,0 +1,72 @@
+package scrapper
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/simelo/rextporter/src/config"
+)
+
+// TextParser is a body parser for the line oriented text protocols, each line like "key value" or "key:value" is
+// decoded as a field in an object, so the values can be found with the paths used for json like "/key"
+type TextParser struct {
+	JSONParser
+	separator  string
+	linePrefix string
+}
+
+// NewTextParser create a TextParser with the settings in the decoder options
+func NewTextParser(decoderOpts config.RextKeyValueStore) TextParser {
+	var p TextParser
+	if decoderOpts != nil {
+		p.separator, _ = decoderOpts.GetString(config.OptKeyRextDecoderTextSeparator)
+		p.linePrefix, _ = decoderOpts.GetString(config.OptKeyRextDecoderTextLinePrefix)
+	}
+	return p
+}
+
+// splitLine return the key and the value in a line, ok is false if the line have no separator
+func (p TextParser) splitLine(line string) (key, val string, ok bool) {
+	idx, sepLen := -1, 0
+	switch {
+	case len(p.separator) != 0:
+		idx, sepLen = strings.Index(line, p.separator), len(p.separator)
+	case strings.Contains(line, ":"):
+		idx, sepLen = strings.Index(line, ":"), 1
+	default:
+		idx, sepLen = strings.IndexFunc(line, unicode.IsSpace), 1
+	}
+	if idx <= 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+sepLen:]), true
+}
+
+// DecodeBody decode each line in a field, the numeric values are decoded as float64, the empty lines, the comments
+// (starting with '#') and the lines without a separator are ignored
+func (p TextParser) DecodeBody(body []byte) (val interface{}, err error) {
+	fields := make(map[string]interface{})
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(p.linePrefix) != 0 {
+			if !strings.HasPrefix(line, p.linePrefix) {
+				continue
+			}
+			line = strings.TrimPrefix(line, p.linePrefix)
+		}
+		key, strVal, ok := p.splitLine(line)
+		if !ok {
+			continue
+		}
+		if num, err := strconv.ParseFloat(strVal, 64); err == nil {
+			fields[key] = num
+		} else {
+			fields[key] = strVal
+		}
+	}
+	return fields, nil
+}
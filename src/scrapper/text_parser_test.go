@@ -0,0 +1,65 @@
+package scrapper
+
+import (
+	"testing"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+type textParserSuit struct {
+	suite.Suite
+}
+
+func TestTextParserSuit(t *testing.T) {
+	suite.Run(t, new(textParserSuit))
+}
+
+func (suite *textParserSuit) TestRedisInfo() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "$64\r\n# Server\r\nredis_version:5.0.3\r\nuptime_in_seconds:120\r\n\r\n# Clients\r\nconnected_clients:7\r\n"
+	p := NewTextParser(nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(body))
+	clients, errLookup := p.PathLookup("/connected_clients", val)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Nil(errLookup)
+	suite.Equal(float64(7), clients)
+	suite.Equal(map[string]interface{}{"redis_version": "5.0.3", "uptime_in_seconds": float64(120), "connected_clients": float64(7)}, val)
+}
+
+func (suite *textParserSuit) TestLinePrefix() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "STAT pid 1234\r\nSTAT curr_connections 10\r\nEND\r\n"
+	opts := memconfig.NewOptionsMap()
+	_, err := opts.SetString(config.OptKeyRextDecoderTextLinePrefix, "STAT ")
+	suite.Nil(err)
+	p := NewTextParser(opts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(body))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(map[string]interface{}{"pid": float64(1234), "curr_connections": float64(10)}, val)
+}
+
+func (suite *textParserSuit) TestSeparator() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "requests = 10\nlast_error = none: timeout\n"
+	opts := memconfig.NewOptionsMap()
+	_, err := opts.SetString(config.OptKeyRextDecoderTextSeparator, "=")
+	suite.Nil(err)
+	p := NewTextParser(opts)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(body))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(map[string]interface{}{"requests": float64(10), "last_error": "none: timeout"}, val)
+}
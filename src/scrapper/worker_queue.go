@@ -1,6 +1,7 @@
 package scrapper
 
 import (
+	"context"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -26,6 +27,7 @@ type ScrapErrResult struct {
 
 // ScrapRequest have the scrapper to do an scrap, the channels to put the result, and the metric index to return
 type ScrapRequest struct {
+	Ctx              context.Context
 	Scrap            Scrapper
 	Res              chan ScrapResult
 	ConstMetricIdxIn int
@@ -37,6 +39,7 @@ type ScrapRequest struct {
 }
 
 type scrapWork struct {
+	ctx              context.Context
 	scrapper         Scrapper
 	res              chan ScrapResult
 	constMetricIdxIn int
@@ -76,6 +79,7 @@ func (p *Pool) Wait() {
 // Apply push a scrapper task to the workers pool to be executed
 func (p *Pool) Apply(ri ScrapRequest) {
 	work := scrapWork{
+		ctx:              ri.Ctx,
 		scrapper:         ri.Scrap,
 		res:              ri.Res,
 		constMetricIdxIn: ri.ConstMetricIdxIn,
@@ -113,7 +117,12 @@ func (w *workerT) start() {
 			select {
 			// Wait for a work request.
 			case work := <-w.works:
-				val, err := work.scrapper.GetMetric(work.metricsCollector)
+				var val interface{}
+				// NOTE(denisacostaq@gmail.com): a work queued after the scrape deadline is not even started
+				err := work.ctx.Err()
+				if err == nil {
+					val, err = work.scrapper.GetMetric(work.ctx, work.metricsCollector)
+				}
 				if err == nil {
 					work.res <- ScrapResult{Val: val, ConstMetricIdxOut: work.constMetricIdxIn, JobName: work.jobName, InstanceName: work.instanceName, DataSource: work.dataSource}
 				} else {
@@ -0,0 +1,661 @@
+package scrapper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// XMLParser is a body parser for xml documents, the nodes are found with XPath expressions like
+// "/health/blockchain/head/seq", "//connection/@address" or "//connection[@outgoing='true']/height". The supported
+// XPath subset is:
+//   - absolute and relative location paths with the "/" and "//" separators, or a count(path)
+//   - the ".", "..", "*", "name", "prefix:name", "prefix:*", "@name", "@prefix:name", "@*" and "text()" steps, the
+//     names without prefix match the local name in any namespace and the prefixes are resolved with the namespace
+//     declarations in the document
+//   - the predicates [2], [last()], [@name], [name], [. = 'text'], [@name != 'text'], [height >= 100],
+//     [contains(@name, 'text')], [starts-with(name, 'text')] and [not(...)] of any other non positional predicate,
+//     the operands are a step without predicates and the <, <=, > and >= operators only work with numbers
+//
+// Anything else(other axes like "child::", other functions, variables, unions, "and"/"or" inside a predicate,
+// arithmetic, ...) is rejected by ValidatePath, many predicates like [@a='1'][@b='2'] can be used instead of "and".
+type XMLParser struct {
+}
+
+// xmlNode is an element in a decoded xml document, the document itself is a node without name
+type xmlNode struct {
+	name     string
+	space    string
+	attrs    []xml.Attr
+	text     string
+	parent   *xmlNode
+	children []*xmlNode
+}
+
+// stringValue return the concatenation of all the text inside the node
+func (n *xmlNode) stringValue() string {
+	if len(n.children) == 0 {
+		return strings.TrimSpace(n.text)
+	}
+	var buf bytes.Buffer
+	var collect func(node *xmlNode)
+	collect = func(node *xmlNode) {
+		buf.WriteString(node.text)
+		for _, child := range node.children {
+			collect(child)
+		}
+	}
+	collect(n)
+	return strings.TrimSpace(buf.String())
+}
+
+// namespace return the namespace bound to prefix in the declarations of n or its ancestors, false if not declared
+func (n *xmlNode) namespace(prefix string) (space string, found bool) {
+	for node := n; node != nil; node = node.parent {
+		for _, attr := range node.attrs {
+			if attr.Name.Space == "xmlns" && attr.Name.Local == prefix {
+				return attr.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// descendantsOrSelf return n and all the elements inside it in document order
+func (n *xmlNode) descendantsOrSelf() (nodes []*xmlNode) {
+	nodes = append(nodes, n)
+	for _, child := range n.children {
+		nodes = append(nodes, child.descendantsOrSelf()...)
+	}
+	return nodes
+}
+
+// DecodeBody decode the body as a xml document
+func (p XMLParser) DecodeBody(body []byte) (val interface{}, err error) {
+	generalScopeErr := "error decoding body as xml"
+	doc := &xmlNode{}
+	current := doc
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		var token xml.Token
+		if token, err = decoder.Token(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			errCause := fmt.Sprintf("can not decode the body: %s. Err: %s", string(body), err.Error())
+			return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, space: t.Name.Space, attrs: t.Attr, parent: current}
+			current.children = append(current.children, node)
+			current = node
+		case xml.EndElement:
+			current = current.parent
+		case xml.CharData:
+			current.text += string(t)
+		}
+	}
+	if len(doc.children) == 0 {
+		errCause := fmt.Sprintf("can not find a root element in the body: %s", string(body))
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return doc, nil
+}
+
+// xpathExprs are the compiled xpath expressions
+var xpathExprs = newCompiledPaths(func(path string) (interface{}, error) {
+	return compileXPath(path)
+})
+
+// ValidatePath compile the xpath expression
+func (p XMLParser) ValidatePath(path string) error {
+	_, err := xpathExprs.get(path)
+	return err
+}
+
+// PathLookup find the nodes selected by a xpath expression, a single value is returned if only one node is selected
+func (p XMLParser) PathLookup(path string, val interface{}) (node interface{}, err error) {
+	if len(path) == 0 {
+		log.Errorln("node path is required")
+		return nil, config.ErrKeyEmptyValue
+	}
+	generalScopeErr := "error looking for node in val"
+	doc, okDoc := val.(*xmlNode)
+	if !okDoc {
+		log.WithField("val", val).Errorln("value is not a xml document")
+		return nil, config.ErrKeyInvalidType
+	}
+	var expr interface{}
+	if expr, err = xpathExprs.get(path); err != nil {
+		errCause := fmt.Sprintln("can not compile the path: ", err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	xpath := expr.(xpathExpr)
+	items := xpath.eval(doc)
+	if xpath.count {
+		return float64(len(items)), nil
+	}
+	if len(items) == 0 {
+		errCause := fmt.Sprintf("can not locate the path %s", path)
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	vals := make([]interface{}, len(items))
+	for idx, item := range items {
+		vals[idx] = textValue(item.stringValue())
+	}
+	if len(vals) == 1 {
+		return vals[0], nil
+	}
+	return vals, nil
+}
+
+// xpathItem is an element or the value of an attribute or text node selected by a xpath expression
+type xpathItem struct {
+	node  *xmlNode
+	value string
+}
+
+func (item xpathItem) stringValue() string {
+	if item.node != nil {
+		return item.node.stringValue()
+	}
+	return strings.TrimSpace(item.value)
+}
+
+const (
+	xpathAxisChild = iota
+	xpathAxisSelf
+	xpathAxisParent
+	xpathAxisAttribute
+	xpathAxisText
+)
+
+// xpathStep is a location step like "connection[@outgoing='true']"
+type xpathStep struct {
+	descendant bool
+	axis       int
+	prefix     string
+	name       string
+	predicates []xpathPredicate
+}
+
+// xpathPredicate filter the items selected by a step, it can be a position, last(), an existence test like "@address",
+// a comparison like "height>=100" or "@outgoing='true'", a contains or starts-with function call or any of them but
+// the positional ones inside not()
+type xpathPredicate struct {
+	position int
+	last     bool
+	negate   bool
+	function string
+	operand  xpathStep
+	operator string
+	literal  string
+	number   *float64
+}
+
+// xpathExpr is a compiled location path, the number of selected items is the value for a count(path)
+type xpathExpr struct {
+	steps []xpathStep
+	count bool
+}
+
+// errInvalidXPath is returned for the expressions out of the supported XPath subset
+var errInvalidXPath = errors.New("invalid or unsupported xpath expression")
+
+// compileXPath compile a location path or a count(path), the supported subset is in the XMLParser doc
+func compileXPath(path string) (expr xpathExpr, err error) {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "count(") && strings.HasSuffix(path, ")") {
+		if expr, err = compileXPath(path[len("count(") : len(path)-1]); err != nil {
+			return expr, err
+		}
+		if expr.count {
+			return expr, errInvalidXPath
+		}
+		expr.count = true
+		return expr, nil
+	}
+	descendant := false
+	if strings.HasPrefix(path, "//") {
+		descendant, path = true, path[2:]
+	} else {
+		path = strings.TrimPrefix(path, "/")
+	}
+	for len(path) != 0 {
+		end := xpathStepEnd(path)
+		if end == -1 {
+			return expr, errInvalidXPath
+		}
+		var step xpathStep
+		if step, err = compileXPathStep(path[:end]); err != nil {
+			return expr, err
+		}
+		step.descendant = descendant
+		expr.steps = append(expr.steps, step)
+		path = path[end:]
+		descendant = strings.HasPrefix(path, "//")
+		if descendant {
+			path = path[2:]
+		} else {
+			path = strings.TrimPrefix(path, "/")
+		}
+	}
+	if len(expr.steps) == 0 {
+		return expr, errInvalidXPath
+	}
+	return expr, nil
+}
+
+// xpathStepEnd return the index of the "/" after the first step in path, -1 if the brackets or quotes are not closed
+func xpathStepEnd(path string) int {
+	depth := 0
+	var quote rune
+	for idx, c := range path {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '/' && depth == 0:
+			return idx
+		}
+	}
+	if depth != 0 || quote != 0 {
+		return -1
+	}
+	return len(path)
+}
+
+func compileXPathStep(text string) (step xpathStep, err error) {
+	name := text
+	if idx := strings.Index(text, "["); idx != -1 {
+		name = text[:idx]
+		rest := text[idx:]
+		for len(rest) != 0 {
+			if rest[0] != '[' {
+				return step, errInvalidXPath
+			}
+			end := xpathPredicateEnd(rest)
+			if end == -1 {
+				return step, errInvalidXPath
+			}
+			var predicate xpathPredicate
+			if predicate, err = compileXPathPredicate(rest[1:end]); err != nil {
+				return step, err
+			}
+			step.predicates = append(step.predicates, predicate)
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+	name = strings.TrimSpace(name)
+	switch {
+	case len(name) == 0:
+		return step, errInvalidXPath
+	case name == ".":
+		step.axis = xpathAxisSelf
+	case name == "..":
+		step.axis = xpathAxisParent
+	case name == "text()":
+		step.axis = xpathAxisText
+	case strings.HasPrefix(name, "@"):
+		step.axis = xpathAxisAttribute
+		if step.prefix, step.name, err = xpathQName(name[1:]); err != nil {
+			return step, err
+		}
+	default:
+		step.axis = xpathAxisChild
+		if step.prefix, step.name, err = xpathQName(name); err != nil {
+			return step, err
+		}
+	}
+	return step, nil
+}
+
+// xpathPredicateEnd return the index of the "]" closing the predicate at the start of text, -1 if not closed
+func xpathPredicateEnd(text string) int {
+	depth := 0
+	var quote rune
+	for idx, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return idx
+			}
+		}
+	}
+	return -1
+}
+
+// xpathQName split a name test like "name", "prefix:name", "prefix:*" or "*" in the prefix and the local name
+func xpathQName(qname string) (prefix, name string, err error) {
+	name = qname
+	if idx := strings.Index(qname, ":"); idx != -1 {
+		prefix, name = qname[:idx], qname[idx+1:]
+		if !xpathIsNCName(prefix) {
+			return "", "", errInvalidXPath
+		}
+	}
+	if name != "*" && !xpathIsNCName(name) {
+		return "", "", errInvalidXPath
+	}
+	return prefix, name, nil
+}
+
+// xpathIsNCName return true if name is a xml name without colons
+func xpathIsNCName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for idx, c := range name {
+		if c == '_' || unicode.IsLetter(c) {
+			continue
+		}
+		if idx != 0 && (unicode.IsDigit(c) || c == '-' || c == '.') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// xpathFunctionArgs return the arguments of a function call like "contains(@name, 'text')" if text is a call to
+// function, the arguments are split by the commas outside quotes
+func xpathFunctionArgs(text, function string) (args []string, isCall bool) {
+	if !strings.HasPrefix(text, function+"(") || !strings.HasSuffix(text, ")") {
+		return nil, false
+	}
+	text = text[len(function)+1 : len(text)-1]
+	var quote rune
+	start := 0
+	for idx, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ',':
+			args = append(args, strings.TrimSpace(text[start:idx]))
+			start = idx + 1
+		}
+	}
+	return append(args, strings.TrimSpace(text[start:])), true
+}
+
+// xpathString return the content of a quoted literal like 'text' or "text"
+func xpathString(literal string) (text string, isString bool) {
+	if len(literal) >= 2 && (literal[0] == '\'' || literal[0] == '"') && literal[len(literal)-1] == literal[0] {
+		text = literal[1 : len(literal)-1]
+		return text, !strings.ContainsRune(text, rune(literal[0]))
+	}
+	return "", false
+}
+
+func compileXPathPredicate(text string) (predicate xpathPredicate, err error) {
+	text = strings.TrimSpace(text)
+	if position, err := strconv.Atoi(text); err == nil {
+		if position < 1 {
+			return predicate, errInvalidXPath
+		}
+		predicate.position = position
+		return predicate, nil
+	}
+	if text == "last()" {
+		predicate.last = true
+		return predicate, nil
+	}
+	if args, isCall := xpathFunctionArgs(text, "not"); isCall {
+		if len(args) != 1 {
+			return predicate, errInvalidXPath
+		}
+		if predicate, err = compileXPathPredicate(args[0]); err != nil {
+			return predicate, err
+		}
+		if predicate.position != 0 || predicate.last {
+			return predicate, errInvalidXPath
+		}
+		predicate.negate = !predicate.negate
+		return predicate, nil
+	}
+	for _, function := range []string{"contains", "starts-with"} {
+		if args, isCall := xpathFunctionArgs(text, function); isCall {
+			literal, isString := "", false
+			if len(args) == 2 {
+				literal, isString = xpathString(args[1])
+			}
+			if !isString {
+				return predicate, errInvalidXPath
+			}
+			predicate.function, predicate.literal = function, literal
+			return predicate, predicate.compileOperand(args[0])
+		}
+	}
+	operand := text
+	if idx, operator := xpathOperator(text); idx != -1 {
+		predicate.operator = operator
+		operand = text[:idx]
+		literal := strings.TrimSpace(text[idx+len(operator):])
+		if str, isString := xpathString(literal); isString {
+			predicate.literal = str
+		} else if num, err := strconv.ParseFloat(literal, 64); err == nil {
+			predicate.literal, predicate.number = literal, &num
+		} else {
+			return predicate, errInvalidXPath
+		}
+		if predicate.number == nil && operator != "=" && operator != "!=" {
+			return predicate, errInvalidXPath
+		}
+	}
+	return predicate, predicate.compileOperand(operand)
+}
+
+// compileOperand compile the step compared in the predicate, it can not have predicates nor be the parent
+func (predicate *xpathPredicate) compileOperand(operand string) (err error) {
+	if predicate.operand, err = compileXPathStep(operand); err != nil {
+		return err
+	}
+	if len(predicate.operand.predicates) != 0 || predicate.operand.axis == xpathAxisParent {
+		return errInvalidXPath
+	}
+	return nil
+}
+
+// xpathOperator return the first comparison operator outside quotes in text and its index, -1 if not found
+func xpathOperator(text string) (idx int, operator string) {
+	var quote rune
+	for idx, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '!' || c == '<' || c == '>':
+			if strings.HasPrefix(text[idx+1:], "=") {
+				return idx, text[idx : idx+2]
+			}
+			if c != '!' {
+				return idx, text[idx : idx+1]
+			}
+		case c == '=':
+			return idx, "="
+		}
+	}
+	return -1, ""
+}
+
+// eval return the items selected by the expression in document order
+func (expr xpathExpr) eval(doc *xmlNode) []xpathItem {
+	items := []xpathItem{{node: doc}}
+	for _, step := range expr.steps {
+		var next []xpathItem
+		seen := make(map[*xmlNode]bool)
+		for _, item := range items {
+			if item.node == nil {
+				continue
+			}
+			contexts := []*xmlNode{item.node}
+			if step.descendant {
+				contexts = item.node.descendantsOrSelf()
+			}
+			for _, context := range contexts {
+				for _, selected := range step.filter(step.selectFrom(context)) {
+					if selected.node != nil {
+						if seen[selected.node] {
+							continue
+						}
+						seen[selected.node] = true
+					}
+					next = append(next, selected)
+				}
+			}
+		}
+		items = next
+	}
+	return items
+}
+
+// selectFrom return the items in the step axis from the context node
+func (step xpathStep) selectFrom(context *xmlNode) (items []xpathItem) {
+	switch step.axis {
+	case xpathAxisSelf:
+		items = append(items, xpathItem{node: context})
+	case xpathAxisParent:
+		if context.parent != nil {
+			items = append(items, xpathItem{node: context.parent})
+		}
+	case xpathAxisText:
+		if len(strings.TrimSpace(context.text)) != 0 {
+			items = append(items, xpathItem{value: context.text})
+		}
+	case xpathAxisAttribute:
+		for _, attr := range context.attrs {
+			if attr.Name.Space == "xmlns" || (len(attr.Name.Space) == 0 && attr.Name.Local == "xmlns") {
+				continue
+			}
+			if step.match(context, attr.Name.Space, attr.Name.Local) {
+				items = append(items, xpathItem{value: attr.Value})
+			}
+		}
+	default:
+		for _, child := range context.children {
+			if step.match(child, child.space, child.name) {
+				items = append(items, xpathItem{node: child})
+			}
+		}
+	}
+	return items
+}
+
+// match return true if the name test of the step select a node with the space and local name, the prefix is
+// resolved with the declarations in scope for the node
+func (step xpathStep) match(node *xmlNode, space, name string) bool {
+	if step.name != "*" && step.name != name {
+		return false
+	}
+	if len(step.prefix) == 0 {
+		return true
+	}
+	bound, found := node.namespace(step.prefix)
+	return found && bound == space
+}
+
+// filter apply the predicates in order, the positions are relative to the items that passed the previous ones
+func (step xpathStep) filter(items []xpathItem) []xpathItem {
+	for _, predicate := range step.predicates {
+		var matched []xpathItem
+		for idx, item := range items {
+			if predicate.match(item, idx+1, len(items)) {
+				matched = append(matched, item)
+			}
+		}
+		items = matched
+	}
+	return items
+}
+
+func (predicate xpathPredicate) match(item xpathItem, position, size int) bool {
+	switch {
+	case predicate.position != 0:
+		return position == predicate.position
+	case predicate.last:
+		return position == size
+	case item.node == nil:
+		return false
+	}
+	for _, operand := range predicate.operand.selectFrom(item.node) {
+		if predicate.test(operand.stringValue()) {
+			return !predicate.negate
+		}
+	}
+	return predicate.negate
+}
+
+// test return true if the operand value pass the function or the comparison in the predicate, or always for an
+// existence test
+func (predicate xpathPredicate) test(val string) bool {
+	switch {
+	case predicate.function == "contains":
+		return strings.Contains(val, predicate.literal)
+	case predicate.function == "starts-with":
+		return strings.HasPrefix(val, predicate.literal)
+	case len(predicate.operator) == 0:
+		return true
+	}
+	return predicate.compare(val)
+}
+
+// compare the operand value with the predicate literal, numerically if the literal is a number
+func (predicate xpathPredicate) compare(val string) bool {
+	if predicate.number == nil {
+		if predicate.operator == "=" {
+			return val == predicate.literal
+		}
+		return val != predicate.literal
+	}
+	num, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return false
+	}
+	switch predicate.operator {
+	case "=":
+		return num == *predicate.number
+	case "!=":
+		return num != *predicate.number
+	case "<":
+		return num < *predicate.number
+	case "<=":
+		return num <= *predicate.number
+	case ">":
+		return num > *predicate.number
+	default:
+		return num >= *predicate.number
+	}
+}
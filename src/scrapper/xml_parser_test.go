@@ -0,0 +1,229 @@
+package scrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/client"
+	"github.com/simelo/rextporter/src/config"
+	"github.com/simelo/rextporter/src/memconfig"
+	"github.com/stretchr/testify/suite"
+)
+
+const xmlHealth = `<?xml version="1.0" encoding="UTF-8"?>
+<health xmlns:sky="http://skycoin.net" xmlns:other="http://example.com">
+	<blockchain>
+		<head><seq>58894</seq><fee>485194</fee></head>
+		<unspents>38171</unspents>
+	</blockchain>
+	<connections>
+		<connection address="139.162.161.41:20002" outgoing="true"><height>180</height></connection>
+		<connection address="185.120.34.60:6000" outgoing="false"><height>57</height></connection>
+		<connection address="172.104.85.6:6000" outgoing="true"><sky:height>5</sky:height></connection>
+	</connections>
+	<version>0.24.1</version>
+</health>`
+
+// xmlClient return the same body always
+type xmlClient struct {
+	body string
+}
+
+func (cl xmlClient) GetData(ctx context.Context, metricsCollector chan<- prometheus.Metric) ([]byte, error) {
+	return []byte(cl.body), nil
+}
+
+func (cl xmlClient) CreateClient() (client.Client, error) {
+	return cl, nil
+}
+
+type xmlParserSuit struct {
+	suite.Suite
+	doc interface{}
+}
+
+func TestXMLParserSuit(t *testing.T) {
+	suite.Run(t, new(xmlParserSuit))
+}
+
+func (suite *xmlParserSuit) SetupTest() {
+	var err error
+	suite.doc, err = XMLParser{}.DecodeBody([]byte(xmlHealth))
+	suite.Require().Nil(err)
+}
+
+func (suite *xmlParserSuit) TestPathLookup() {
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: "/health/blockchain/head/seq", expected: float64(58894)},
+		{path: "health/blockchain/unspents", expected: float64(38171)},
+		{path: "/health/version/text()", expected: "0.24.1"},
+		{path: "//head/fee", expected: float64(485194)},
+		{path: "//connection/height", expected: []interface{}{float64(180), float64(57), float64(5)}},
+		{path: "//connection/@address", expected: []interface{}{"139.162.161.41:20002", "185.120.34.60:6000", "172.104.85.6:6000"}},
+		{path: "//connection[@outgoing='true']/height", expected: []interface{}{float64(180), float64(5)}},
+		{path: "//connection[height>=57][1]/@address", expected: "139.162.161.41:20002"},
+		{path: "//connection[last()]/@outgoing", expected: "true"},
+		{path: "//height[. < 100]/../@address", expected: []interface{}{"185.120.34.60:6000", "172.104.85.6:6000"}},
+		{path: "/health/*/head/*", expected: []interface{}{float64(58894), float64(485194)}},
+		{path: "/health/connections/connection[2]/@*", expected: []interface{}{"185.120.34.60:6000", "false"}},
+		{path: "//sky:height", expected: float64(5)},
+		{path: "//connection[sky:height]/@address", expected: "172.104.85.6:6000"},
+		{path: "//connection[not(sky:height)]/height", expected: []interface{}{float64(180), float64(57)}},
+		{path: "//connection[not(@outgoing='true')]/height", expected: float64(57)},
+		{path: "//connection[contains(@address, ':6000')]/@outgoing", expected: []interface{}{"false", "true"}},
+		{path: "//connection[starts-with(@address, \"139.\")]/height", expected: float64(180)},
+		{path: "count(//connection)", expected: float64(3)},
+		{path: "count(//connection[@outgoing='true'][height > 100])", expected: float64(1)},
+		{path: "count(//missing)", expected: float64(0)},
+	}
+	for _, test := range tests {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := XMLParser{}.PathLookup(test.path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.Nil(err, test.path)
+		suite.Equal(test.expected, node, test.path)
+	}
+}
+
+func (suite *xmlParserSuit) TestInvalidPaths() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	paths := []string{"/", "/health/connection[", "//connection[@outgoing='true]", "//connection[0]", "//connection[@address>'a']", "/health/missing", "//other:height", "//undeclared:height"}
+	for _, path := range paths {
+		// NOTE(denisacostaq@gmail.com): When
+		node, err := XMLParser{}.PathLookup(path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.NotNil(err, path)
+		suite.Nil(node, path)
+	}
+}
+
+func (suite *xmlParserSuit) TestUnsupportedPaths() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	paths := []string{
+		"child::health",
+		"//connection/count(height)",
+		"count(count(//connection))",
+		"count(//connection",
+		"//connection | //head",
+		"//connection[@address and @outgoing]",
+		"//connection[position() < 2]",
+		"//connection[1 + 1]",
+		"//connection[contains(@address)]",
+		"//connection[contains(@address, address)]",
+		"//connection[not(1)]",
+		"//connection[not(last())]",
+		"//connection[string-length(@address) > 10]",
+		"//connection[@outgoing='t'rue']",
+		"//connection[height = $min]",
+		"//1connection",
+		"//sky:",
+		"//:height",
+	}
+	for _, path := range paths {
+		// NOTE(denisacostaq@gmail.com): When
+		errValidate := XMLParser{}.ValidatePath(path)
+		node, err := XMLParser{}.PathLookup(path, suite.doc)
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.NotNil(errValidate, path)
+		suite.NotNil(err, path)
+		suite.Nil(node, path)
+	}
+}
+
+func (suite *xmlParserSuit) TestUnsupportedPathsAreInvalidConfig() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	valid := memconfig.NewNodeSolver(config.RextNodeSolverTypeXPath, "//connection[contains(@address, ':6000')]/sky:height", nil)
+	invalid := memconfig.NewNodeSolver(config.RextNodeSolverTypeXPath, "sum(//connection/height)", nil)
+
+	// NOTE(denisacostaq@gmail.com): When
+	validHasError := valid.Validate()
+	invalidHasError := invalid.Validate()
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.False(validHasError)
+	suite.True(invalidHasError)
+}
+
+func (suite *xmlParserSuit) TestInvalidBody() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	bodies := []string{"<health><seq>1</health>", "", `{"seq": 1}`}
+	for _, body := range bodies {
+		// NOTE(denisacostaq@gmail.com): When
+		val, err := XMLParser{}.DecodeBody([]byte(body))
+
+		// NOTE(denisacostaq@gmail.com): Assert
+		suite.NotNil(err, body)
+		suite.Nil(val, body)
+	}
+}
+
+func (suite *xmlParserSuit) TestNumericVec() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	nSolver := &memconfig.NodeSolver{MType: config.RextNodeSolverTypeXPath}
+	nSolver.SetNodePath("//connection/height")
+	lSolver := &memconfig.NodeSolver{MType: config.RextNodeSolverTypeXPath}
+	lSolver.SetNodePath("//connection/@address")
+	label := &memconfig.LabelDef{}
+	label.SetName("address")
+	label.SetNodeSolver(lSolver)
+	mtrConf := &memconfig.MetricDef{}
+	mtrConf.SetMetricType(config.KeyMetricTypeGauge)
+	mtrConf.AddLabel(label)
+	s := newNumericVec(xmlClient{body: xmlHealth}, XMLParser{}, []config.NodeLookup{XMLParser{}}, "skycoin", "localhost:6420", "/health", nSolver, mtrConf)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := s.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(NumericVecVals{
+		{Val: 180, Labels: []string{"139.162.161.41:20002"}},
+		{Val: 57, Labels: []string{"185.120.34.60:6000"}},
+		{Val: 5, Labels: []string{"172.104.85.6:6000"}},
+	}, val)
+}
+
+func (suite *xmlParserSuit) TestNumericVecSingleNode() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	nSolver := &memconfig.NodeSolver{MType: config.RextNodeSolverTypeXPath}
+	nSolver.SetNodePath("//connection[@outgoing='false']/height")
+	lSolver := &memconfig.NodeSolver{MType: config.RextNodeSolverTypeXPath}
+	lSolver.SetNodePath("//connection[@outgoing='false']/@address")
+	label := &memconfig.LabelDef{}
+	label.SetName("address")
+	label.SetNodeSolver(lSolver)
+	mtrConf := &memconfig.MetricDef{}
+	mtrConf.SetMetricType(config.KeyMetricTypeGauge)
+	mtrConf.AddLabel(label)
+	s := newNumericVec(xmlClient{body: xmlHealth}, XMLParser{}, []config.NodeLookup{XMLParser{}}, "skycoin", "localhost:6420", "/health", nSolver, mtrConf)
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := s.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Equal(NumericVecVals{{Val: 57, Labels: []string{"185.120.34.60:6000"}}}, val)
+}
+
+func (suite *xmlParserSuit) TestNumericAndHistogram() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	numeric := newNumeric(xmlClient{body: xmlHealth}, XMLParser{}, "/health/blockchain/head/seq", "skycoin", "localhost:6420", "/health")
+	histogram := newHistogram(xmlClient{body: xmlHealth}, XMLParser{}, "/health", "skycoin", "localhost:6420", "//connection/height", histogramClientOptions{10, 100})
+
+	// NOTE(denisacostaq@gmail.com): When
+	numericVal, errNumeric := numeric.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+	histogramVal, errHistogram := histogram.GetMetric(context.Background(), make(chan prometheus.Metric, 10))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(errNumeric)
+	suite.Equal(float64(58894), numericVal)
+	suite.Nil(errHistogram)
+	suite.Equal(HistogramValue{Count: 3, Sum: 242, Buckets: map[float64]uint64{10: 1, 100: 2}}, histogramVal)
+}
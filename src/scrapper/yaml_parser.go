@@ -0,0 +1,49 @@
+package scrapper
+
+import (
+	"fmt"
+
+	"github.com/simelo/rextporter/src/util"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// YAMLParser is a body parser for yaml documents, the document is decoded like json so the values can be found with
+// the same paths like "/blockchain/head/seq"
+type YAMLParser struct {
+	JSONParser
+}
+
+// DecodeBody decode the body as yaml, the mappings are decoded as map[string]interface{} and the numbers as float64
+func (p YAMLParser) DecodeBody(body []byte) (val interface{}, err error) {
+	generalScopeErr := "error decoding body as yaml"
+	if err = yaml.Unmarshal(body, &val); err != nil {
+		errCause := fmt.Sprintf("can not decode the body: %s. Err: %s", string(body), err.Error())
+		return nil, util.ErrorFromThisScope(errCause, generalScopeErr)
+	}
+	return jsonLike(val), nil
+}
+
+// jsonLike convert the yaml values to the types decoded by encoding/json
+func jsonLike(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			obj[fmt.Sprint(key)] = jsonLike(item)
+		}
+		return obj
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = jsonLike(item)
+		}
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return v
+	}
+}
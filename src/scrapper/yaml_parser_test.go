@@ -0,0 +1,63 @@
+package scrapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type yamlParserSuit struct {
+	suite.Suite
+}
+
+func TestYAMLParserSuit(t *testing.T) {
+	suite.Run(t, new(yamlParserSuit))
+}
+
+func (suite *yamlParserSuit) TestPathLookup() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := `
+blockchain:
+  head:
+    seq: 58894
+    fee: 485194.5
+  unspents: 38171
+connections:
+  - address: 139.162.161.41:20002
+    height: 180
+  - address: 185.120.34.60:6000
+    height: 57
+csrf_enabled: true
+`
+	p := YAMLParser{}
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := p.DecodeBody([]byte(body))
+	seq, errSeq := p.PathLookup("/blockchain/head/seq", val)
+	fee, errFee := p.PathLookup("/blockchain/head/fee", val)
+	heights, errHeights := p.PathLookup("/connections/height", val)
+	addresses, errAddresses := p.PathLookup("/connections/address", val)
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.Nil(err)
+	suite.Nil(errSeq)
+	suite.Equal(float64(58894), seq)
+	suite.Nil(errFee)
+	suite.Equal(485194.5, fee)
+	suite.Nil(errHeights)
+	suite.Equal([]interface{}{float64(180), float64(57)}, heights)
+	suite.Nil(errAddresses)
+	suite.Equal([]interface{}{"139.162.161.41:20002", "185.120.34.60:6000"}, addresses)
+}
+
+func (suite *yamlParserSuit) TestInvalidBody() {
+	// NOTE(denisacostaq@gmail.com): Giving
+	body := "blockchain:\n  head: [1, 2\n"
+
+	// NOTE(denisacostaq@gmail.com): When
+	val, err := YAMLParser{}.DecodeBody([]byte(body))
+
+	// NOTE(denisacostaq@gmail.com): Assert
+	suite.NotNil(err)
+	suite.Nil(val)
+}
@@ -2,6 +2,7 @@ package toml2config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/simelo/rextporter/src/config"
 	"github.com/simelo/rextporter/src/memconfig"
@@ -29,14 +30,31 @@ func createService(srv tomlconfig.Service, metricsMapping serviceName2MetricName
 	service = &memconfig.Service{}
 	service.SetProtocol(srv.Protocol)
 	basePath := fmt.Sprintf("%s://%s:%d", service.GetProtocol(), srv.Location.Location, srv.Port)
-	service.SetBasePath(basePath)
+	instanceName := fmt.Sprintf("%s:%d", srv.Location.Location, srv.Port)
 	srvOpts := service.GetOptions()
+	switch service.GetProtocol() {
+	case config.ProtocolFile, config.ProtocolExec, config.ProtocolTCP, config.ProtocolSQL:
+		basePath = srv.Location.Location
+		instanceName = srv.Location.Location
+		if len(instanceName) == 0 {
+			instanceName = "localhost"
+		}
+	case config.ProtocolHTTPUnix:
+		// NOTE(denisacostaq@gmail.com): the host is not used to connect, the transport dial the socket
+		basePath = "http://localhost"
+		instanceName = srv.Socket
+		if _, err = srvOpts.SetString(config.OptKeyRextServiceDefSocketPath, srv.Socket); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextServiceDefSocketPath, "val": srv.Socket}).Errorln("error saving socket path")
+			return service, err
+		}
+	}
+	service.SetBasePath(basePath)
 	if _, err = srvOpts.SetString(config.OptKeyRextServiceDefJobName, srv.Name); err != nil {
 		log.WithFields(log.Fields{"key": config.OptKeyRextServiceDefJobName, "val": srv.Name}).Errorln("error saving job name")
 		return service, err
 	}
-	if _, err = srvOpts.SetString(config.OptKeyRextServiceDefInstanceName, fmt.Sprintf("%s:%d", srv.Location.Location, srv.Port)); err != nil {
-		log.WithFields(log.Fields{"key": config.OptKeyRextServiceDefInstanceName, "val": fmt.Sprintf("%s:%d", srv.Location.Location, srv.Port)}).Errorln("error saving instance name")
+	if _, err = srvOpts.SetString(config.OptKeyRextServiceDefInstanceName, instanceName); err != nil {
+		log.WithFields(log.Fields{"key": config.OptKeyRextServiceDefInstanceName, "val": instanceName}).Errorln("error saving instance name")
 		return service, err
 	}
 	auth := &memconfig.HTTPAuth{}
@@ -54,35 +72,590 @@ func createService(srv tomlconfig.Service, metricsMapping serviceName2MetricName
 		log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefTokenGenEndpoint, "val": srv.GenTokenEndpoint}).Errorln("error saving token endpoint")
 		return service, err
 	}
+	if len(srv.GenTokenHTTPMethod) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefTokenGenHTTPMethod, srv.GenTokenHTTPMethod); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefTokenGenHTTPMethod, "val": srv.GenTokenHTTPMethod}).Errorln("error saving token endpoint http method")
+			return service, err
+		}
+	}
+	if len(srv.GenTokenBody) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefTokenGenBody, srv.GenTokenBody); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefTokenGenBody, "val": srv.GenTokenBody}).Errorln("error saving token endpoint body")
+			return service, err
+		}
+	}
+	if len(srv.GenTokenHeaders) != 0 {
+		if _, err = authOpts.SetObject(config.OptKeyRextAuthDefTokenGenHeaders, srv.GenTokenHeaders); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefTokenGenHeaders, "val": srv.GenTokenHeaders}).Errorln("error saving token endpoint headers")
+			return service, err
+		}
+	}
+	if len(srv.TokenSource) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefTokenSource, srv.TokenSource); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefTokenSource, "val": srv.TokenSource}).Errorln("error saving token source")
+			return service, err
+		}
+	}
+	if len(srv.TokenInjectAs) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefTokenInjectAs, srv.TokenInjectAs); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefTokenInjectAs, "val": srv.TokenInjectAs}).Errorln("error saving token injection")
+			return service, err
+		}
+	}
+	if len(srv.LoginEndpoint) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefLoginEndpoint, srv.LoginEndpoint); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefLoginEndpoint, "val": srv.LoginEndpoint}).Errorln("error saving login endpoint")
+			return service, err
+		}
+	}
+	if len(srv.LoginHTTPMethod) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefLoginHTTPMethod, srv.LoginHTTPMethod); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefLoginHTTPMethod, "val": srv.LoginHTTPMethod}).Errorln("error saving login http method")
+			return service, err
+		}
+	}
+	if len(srv.LoginBody) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefLoginBody, srv.LoginBody); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefLoginBody, "val": srv.LoginBody}).Errorln("error saving login body")
+			return service, err
+		}
+	}
+	if len(srv.LoginHeaders) != 0 {
+		if _, err = authOpts.SetObject(config.OptKeyRextAuthDefLoginHeaders, srv.LoginHeaders); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefLoginHeaders, "val": srv.LoginHeaders}).Errorln("error saving login headers")
+			return service, err
+		}
+	}
+	if len(srv.LoginPage) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefLoginPage, srv.LoginPage); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefLoginPage, "val": srv.LoginPage}).Errorln("error saving login page")
+			return service, err
+		}
+	}
+	if len(srv.HMACSecret) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefHMACSecret, srv.HMACSecret); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefHMACSecret, "val": "***"}).Errorln("error saving hmac secret")
+			return service, err
+		}
+	}
+	if len(srv.HMACSigningString) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefHMACSigningString, srv.HMACSigningString); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefHMACSigningString, "val": srv.HMACSigningString}).Errorln("error saving hmac signing string")
+			return service, err
+		}
+	}
+	if len(srv.HMACSignatureHeader) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefHMACSignatureHeader, srv.HMACSignatureHeader); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefHMACSignatureHeader, "val": srv.HMACSignatureHeader}).Errorln("error saving hmac signature header")
+			return service, err
+		}
+	}
+	if len(srv.HMACTimestampHeader) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefHMACTimestampHeader, srv.HMACTimestampHeader); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefHMACTimestampHeader, "val": srv.HMACTimestampHeader}).Errorln("error saving hmac timestamp header")
+			return service, err
+		}
+	}
+	if len(srv.HMACBodyHashHeader) != 0 {
+		if _, err = authOpts.SetString(config.OptKeyRextAuthDefHMACBodyHashHeader, srv.HMACBodyHashHeader); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextAuthDefHMACBodyHashHeader, "val": srv.HMACBodyHashHeader}).Errorln("error saving hmac body hash header")
+			return service, err
+		}
+	}
 	service.SetAuthForBaseURL(auth)
+	if err = fillTLS(srvOpts, srv.TLS); err != nil {
+		log.WithError(err).Errorln("error saving service tls settings")
+		return service, err
+	}
+	if err = fillHTTP(srvOpts, srv.HTTP); err != nil {
+		log.WithError(err).Errorln("error saving service http settings")
+		return service, err
+	}
+	if err = fillLimits(srvOpts, srv.Limits); err != nil {
+		log.WithError(err).Errorln("error saving service limits")
+		return service, err
+	}
+	if err = fillSQL(srvOpts, srv.SQL); err != nil {
+		log.WithError(err).Errorln("error saving service database settings")
+		return service, err
+	}
+	if err = fillRetry(srvOpts, srv.Retry); err != nil {
+		log.WithError(err).Errorln("error saving service retry policy")
+		return service, err
+	}
+	if err = fillCircuitBreaker(srvOpts, srv.CircuitBreaker); err != nil {
+		log.WithError(err).Errorln("error saving service circuit breaker settings")
+		return service, err
+	}
 	for _, resPath := range srv.ResourcePaths {
 		var resDef config.RextResourceDef
 		switch resPath.PathType {
-		case "rest_api":
+		case config.ResourceTypeRestAPI:
 			resDef = createResourceFrom4API(mtrN2Metric, resPath)
 			resDef.SetType(resPath.PathType)
 			resDef.SetResourceURI(resPath.Path)
-			decoder := memconfig.NewDecoder(resPath.PathType, nil)
-			resDef.SetDecoder(decoder)
+			resDef.SetDecoder(memconfig.NewDecoder(config.DecoderJSON, nil))
 			resOpts := resDef.GetOptions()
 			// FIXME(denisacostaq@gmail.com): OptKeyRextResourceDefHTTPMethod should be inside the service or the resource
 			if _, err = resOpts.SetString(config.OptKeyRextResourceDefHTTPMethod, resPath.HTTPMethod); err != nil {
 				log.WithFields(log.Fields{"key": config.OptKeyRextResourceDefHTTPMethod, "val": resPath.HTTPMethod}).Errorln("error saving http method")
 				return service, err
 			}
-		case "metrics_fordwader":
-			resDef = createResourceFrom4ExposedMetrics(resPath)
-			decoder := memconfig.NewDecoder(resPath.PathType, nil)
+			if err = fillPagination(resOpts, resPath.Pagination); err != nil {
+				log.WithError(err).Errorln("error saving resource pagination settings")
+				return service, err
+			}
+		case config.ResourceTypeFile:
+			resDef = createResourceFrom4API(mtrN2Metric, resPath)
+			resDef.SetDecoder(memconfig.NewDecoder(config.DecoderJSON, nil))
+			if resPath.MaxFileSize != nil {
+				if _, err = resDef.GetOptions().SetObject(config.OptKeyRextResourceDefFileMaxSize, *resPath.MaxFileSize); err != nil {
+					log.WithFields(log.Fields{"key": config.OptKeyRextResourceDefFileMaxSize, "val": *resPath.MaxFileSize}).Errorln("error saving file max size")
+					return service, err
+				}
+			}
+		case config.ResourceTypeExec:
+			resDef = createResourceFrom4API(mtrN2Metric, resPath)
+			resDef.SetDecoder(memconfig.NewDecoder(config.DecoderJSON, nil))
+			if err = fillExec(resDef.GetOptions(), resPath.Exec); err != nil {
+				log.WithError(err).Errorln("error saving resource exec settings")
+				return service, err
+			}
+		case config.ResourceTypeJSONRPC:
+			resDef = createResourceFrom4API(mtrN2Metric, resPath)
+			resDef.SetDecoder(memconfig.NewDecoder(config.DecoderJSON, nil))
+			if err = fillJSONRPC(resDef.GetOptions(), resPath.JSONRPC); err != nil {
+				log.WithError(err).Errorln("error saving resource json-rpc settings")
+				return service, err
+			}
+		case config.ResourceTypeGraphQL:
+			resDef = createResourceFrom4API(mtrN2Metric, resPath)
+			resDef.SetDecoder(memconfig.NewDecoder(config.DecoderJSON, nil))
+			if err = fillGraphQL(resDef.GetOptions(), resPath.GraphQL); err != nil {
+				log.WithError(err).Errorln("error saving resource graphql settings")
+				return service, err
+			}
+		case config.ResourceTypeStream:
+			resDef = createResourceFrom4API(mtrN2Metric, resPath)
+			resDef.SetDecoder(memconfig.NewDecoder(config.DecoderJSON, nil))
+			if err = fillStream(resDef.GetOptions(), resPath.Stream); err != nil {
+				log.WithError(err).Errorln("error saving resource stream settings")
+				return service, err
+			}
+		case config.ResourceTypeTCP:
+			resDef = createResourceFrom4API(mtrN2Metric, resPath)
+			var decoder *memconfig.Decoder
+			if decoder, err = fillTCP(resDef.GetOptions(), resPath.TCP); err != nil {
+				log.WithError(err).Errorln("error saving resource tcp settings")
+				return service, err
+			}
 			resDef.SetDecoder(decoder)
+		case config.ResourceTypeSQL:
+			resDef = createResourceFrom4API(mtrN2Metric, resPath)
+			resDef.SetDecoder(memconfig.NewDecoder(config.DecoderJSON, nil))
+			if err = fillSQLQuery(resDef.GetOptions(), resPath.SQL); err != nil {
+				log.WithError(err).Errorln("error saving resource sql query")
+				return service, err
+			}
+		case config.ResourceTypeMetricsFordwader:
+			resDef = createResourceFrom4ExposedMetrics(resPath)
 		default:
-			log.WithField("resource_path_type", resPath.PathType).Errorln("valid types are rest_api or metrics_fordwader")
+			log.WithField("resource_path_type", resPath.PathType).Errorln("valid types are rest_api, file, exec, json_rpc, graphql, stream, tcp, sql or metrics_fordwader")
 			return service, config.ErrKeyInvalidType
 		}
+		fillDecoder(resDef, resPath.Decoder)
+		if err = fillCSV(resDef.GetDecoder(), resPath.CSV); err != nil {
+			log.WithError(err).Errorln("error saving resource csv settings")
+			return service, err
+		}
+		if err = fillINI(resDef.GetDecoder(), resPath.INI); err != nil {
+			log.WithError(err).Errorln("error saving resource ini settings")
+			return service, err
+		}
+		if err = fillTLS(resDef.GetOptions(), resPath.TLS); err != nil {
+			log.WithError(err).Errorln("error saving resource tls settings")
+			return service, err
+		}
+		if err = fillRetry(resDef.GetOptions(), resPath.Retry); err != nil {
+			log.WithError(err).Errorln("error saving resource retry policy")
+			return service, err
+		}
+		if err = fillCircuitBreaker(resDef.GetOptions(), resPath.CircuitBreaker); err != nil {
+			log.WithError(err).Errorln("error saving resource circuit breaker settings")
+			return service, err
+		}
 		service.AddResource(resDef)
 	}
 	return service, err
 }
 
+// fillTLS save the tls settings(can be nil) in opts, only the defined values are saved
+func fillTLS(opts config.RextKeyValueStore, tls *tomlconfig.TLS) (err error) {
+	if tls == nil {
+		return nil
+	}
+	strVals := []struct {
+		key string
+		val string
+	}{
+		{key: config.OptKeyRextTLSCAFile, val: tls.CAFile},
+		{key: config.OptKeyRextTLSCertFile, val: tls.CertFile},
+		{key: config.OptKeyRextTLSKeyFile, val: tls.KeyFile},
+		{key: config.OptKeyRextTLSServerName, val: tls.ServerName},
+		{key: config.OptKeyRextTLSMinVersion, val: tls.MinVersion},
+	}
+	for _, strVal := range strVals {
+		if len(strVal.val) == 0 {
+			continue
+		}
+		if _, err = opts.SetString(strVal.key, strVal.val); err != nil {
+			log.WithFields(log.Fields{"key": strVal.key, "val": strVal.val}).Errorln("error saving tls setting")
+			return err
+		}
+	}
+	if tls.InsecureSkipVerify != nil {
+		if _, err = opts.SetObject(config.OptKeyRextTLSInsecureSkipVerify, *tls.InsecureSkipVerify); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextTLSInsecureSkipVerify, "val": *tls.InsecureSkipVerify}).Errorln("error saving tls insecure skip verify")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillHTTP save the http settings(can be nil) in opts, only the defined values are saved
+func fillHTTP(opts config.RextKeyValueStore, http *tomlconfig.HTTP) (err error) {
+	if http == nil {
+		return nil
+	}
+	vals := make(map[string]interface{})
+	durations := map[string]time.Duration{
+		config.OptKeyRextServiceDefHTTPTimeout:             http.Timeout,
+		config.OptKeyRextServiceDefHTTPConnectTimeout:      http.ConnectTimeout,
+		config.OptKeyRextServiceDefHTTPTLSHandshakeTimeout: http.TLSHandshakeTimeout,
+		config.OptKeyRextServiceDefHTTPKeepAlive:           http.KeepAlive,
+		config.OptKeyRextServiceDefHTTPIdleConnTimeout:     http.IdleConnTimeout,
+	}
+	for key, val := range durations {
+		if val != 0 {
+			vals[key] = val
+		}
+	}
+	ints := map[string]*int{
+		config.OptKeyRextServiceDefHTTPMaxIdleConns:        http.MaxIdleConns,
+		config.OptKeyRextServiceDefHTTPMaxIdleConnsPerHost: http.MaxIdleConnsPerHost,
+		config.OptKeyRextServiceDefHTTPMaxConnsPerHost:     http.MaxConnsPerHost,
+	}
+	for key, val := range ints {
+		if val != nil {
+			vals[key] = *val
+		}
+	}
+	if http.DisableKeepAlives != nil {
+		vals[config.OptKeyRextServiceDefHTTPDisableKeepAlives] = *http.DisableKeepAlives
+	}
+	if len(http.ProxyURL) != 0 {
+		vals[config.OptKeyRextServiceDefHTTPProxyURL] = http.ProxyURL
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving http setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillExec save the command settings(can be nil) in opts, only the defined values are saved
+func fillExec(opts config.RextKeyValueStore, e *tomlconfig.Exec) (err error) {
+	if e == nil {
+		return nil
+	}
+	vals := make(map[string]interface{})
+	if len(e.Args) != 0 {
+		vals[config.OptKeyRextResourceDefExecArgs] = e.Args
+	}
+	if len(e.Env) != 0 {
+		vals[config.OptKeyRextResourceDefExecEnv] = e.Env
+	}
+	if len(e.Dir) != 0 {
+		vals[config.OptKeyRextResourceDefExecDir] = e.Dir
+	}
+	if e.Timeout != 0 {
+		vals[config.OptKeyRextResourceDefExecTimeout] = e.Timeout
+	}
+	if e.MaxConcurrent != nil {
+		vals[config.OptKeyRextResourceDefExecMaxConcurrent] = *e.MaxConcurrent
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving exec setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillJSONRPC save the JSON-RPC call settings in opts, the method is required
+func fillJSONRPC(opts config.RextKeyValueStore, jsonRPC *tomlconfig.JSONRPC) (err error) {
+	if jsonRPC == nil || len(jsonRPC.Method) == 0 {
+		log.Errorln("json_rpc resources require a method")
+		return config.ErrKeyEmptyValue
+	}
+	vals := map[string]interface{}{
+		config.OptKeyRextResourceDefJSONRPCMethod: jsonRPC.Method,
+		config.OptKeyRextResourceDefJSONRPCBatch:  jsonRPC.Batch,
+	}
+	if len(jsonRPC.Params) != 0 {
+		vals[config.OptKeyRextResourceDefJSONRPCParams] = jsonRPC.Params
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving json-rpc setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillGraphQL save the GraphQL query settings in opts, the query is required
+func fillGraphQL(opts config.RextKeyValueStore, graphQL *tomlconfig.GraphQL) (err error) {
+	if graphQL == nil || len(graphQL.Query) == 0 {
+		log.Errorln("graphql resources require a query")
+		return config.ErrKeyEmptyValue
+	}
+	vals := map[string]interface{}{
+		config.OptKeyRextResourceDefGraphQLQuery: graphQL.Query,
+	}
+	if len(graphQL.Variables) != 0 {
+		vals[config.OptKeyRextResourceDefGraphQLVariables] = graphQL.Variables
+	}
+	if len(graphQL.OperationName) != 0 {
+		vals[config.OptKeyRextResourceDefGraphQLOperationName] = graphQL.OperationName
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving graphql setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillStream save the stream settings in opts, the kind is required
+func fillStream(opts config.RextKeyValueStore, stream *tomlconfig.Stream) (err error) {
+	if stream == nil || len(stream.Kind) == 0 {
+		log.Errorln("stream resources require a kind")
+		return config.ErrKeyEmptyValue
+	}
+	vals := map[string]interface{}{
+		config.OptKeyRextResourceDefStreamKind: stream.Kind,
+	}
+	if len(stream.Subscribe) != 0 {
+		vals[config.OptKeyRextResourceDefStreamSubscribe] = stream.Subscribe
+	}
+	if stream.Window != nil {
+		vals[config.OptKeyRextResourceDefStreamWindow] = *stream.Window
+	}
+	if stream.WithCount {
+		vals[config.OptKeyRextResourceDefStreamWithCount] = true
+	}
+	durations := map[string]time.Duration{
+		config.OptKeyRextResourceDefStreamReconnectInitialBackoff: stream.ReconnectInitialBackoff,
+		config.OptKeyRextResourceDefStreamReconnectMaxBackoff:     stream.ReconnectMaxBackoff,
+	}
+	for key, val := range durations {
+		if val != 0 {
+			vals[key] = val
+		}
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving stream setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillDecoder replace the resource decoder type keeping its options, it is kept if decoderType is empty. The decoder
+// types are registered with config.RegisterDecoder and the unknown ones are rejected by the config validation
+func fillDecoder(resDef config.RextResourceDef, decoderType string) {
+	if len(decoderType) == 0 {
+		return
+	}
+	var decoderOpts config.RextKeyValueStore
+	if resDef.GetDecoder() != nil {
+		decoderOpts = resDef.GetDecoder().GetOptions()
+	}
+	resDef.SetDecoder(memconfig.NewDecoder(decoderType, decoderOpts))
+}
+
+// fillCSV save the csv settings(can be nil) in the decoder options
+func fillCSV(decoder config.RextDecoderDef, csv *tomlconfig.CSV) (err error) {
+	if csv == nil || decoder == nil {
+		return nil
+	}
+	decoderOpts := map[string]string{
+		config.OptKeyRextDecoderCSVDelimiter:     csv.Delimiter,
+		config.OptKeyRextDecoderCSVCommentPrefix: csv.CommentPrefix,
+		config.OptKeyRextDecoderCSVHeader:        csv.Header,
+	}
+	for key, val := range decoderOpts {
+		if len(val) == 0 {
+			continue
+		}
+		if _, err = decoder.GetOptions().SetString(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving csv decoder setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillINI save the ini settings(can be nil) in the decoder options
+func fillINI(decoder config.RextDecoderDef, ini *tomlconfig.INI) (err error) {
+	if ini == nil || decoder == nil || len(ini.Separator) == 0 {
+		return nil
+	}
+	if _, err = decoder.GetOptions().SetString(config.OptKeyRextDecoderTextSeparator, ini.Separator); err != nil {
+		log.WithField("val", ini.Separator).Errorln("error saving ini decoder separator")
+		return err
+	}
+	return nil
+}
+
+// fillTCP save the tcp settings(can be nil) in opts and return a text decoder for the response
+func fillTCP(opts config.RextKeyValueStore, tcp *tomlconfig.TCP) (decoder *memconfig.Decoder, err error) {
+	decoder = memconfig.NewDecoder(config.DecoderText, nil)
+	if tcp == nil {
+		return decoder, nil
+	}
+	vals := make(map[string]interface{})
+	strs := map[string]string{
+		config.OptKeyRextResourceDefTCPCommand:    tcp.Command,
+		config.OptKeyRextResourceDefTCPTerminator: tcp.Terminator,
+	}
+	for key, val := range strs {
+		if len(val) != 0 {
+			vals[key] = val
+		}
+	}
+	if tcp.Timeout != 0 {
+		vals[config.OptKeyRextResourceDefTCPTimeout] = tcp.Timeout
+	}
+	if tcp.MaxSize != nil {
+		vals[config.OptKeyRextResourceDefTCPMaxSize] = *tcp.MaxSize
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving tcp setting")
+			return decoder, err
+		}
+	}
+	decoderOpts := map[string]string{
+		config.OptKeyRextDecoderTextSeparator:  tcp.Separator,
+		config.OptKeyRextDecoderTextLinePrefix: tcp.LinePrefix,
+	}
+	for key, val := range decoderOpts {
+		if len(val) == 0 {
+			continue
+		}
+		if _, err = decoder.GetOptions().SetString(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving text decoder setting")
+			return decoder, err
+		}
+	}
+	return decoder, nil
+}
+
+// fillSQL save the database settings(can be nil) in opts, only the defined values are saved
+func fillSQL(opts config.RextKeyValueStore, sqlDef *tomlconfig.SQL) (err error) {
+	if sqlDef == nil {
+		return nil
+	}
+	vals := make(map[string]interface{})
+	strs := map[string]string{
+		config.OptKeyRextServiceDefSQLDriver:  sqlDef.Driver,
+		config.OptKeyRextServiceDefSQLDSNFile: sqlDef.DSNFile,
+		config.OptKeyRextServiceDefSQLDSNEnv:  sqlDef.DSNEnv,
+	}
+	for key, val := range strs {
+		if len(val) != 0 {
+			vals[key] = val
+		}
+	}
+	ints := map[string]*int{
+		config.OptKeyRextServiceDefSQLMaxOpenConns: sqlDef.MaxOpenConns,
+		config.OptKeyRextServiceDefSQLMaxIdleConns: sqlDef.MaxIdleConns,
+	}
+	for key, val := range ints {
+		if val != nil {
+			vals[key] = *val
+		}
+	}
+	if sqlDef.ConnMaxLifetime != 0 {
+		vals[config.OptKeyRextServiceDefSQLConnMaxLifetime] = sqlDef.ConnMaxLifetime
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving database setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillSQLQuery save the query settings in opts, the query is required
+func fillSQLQuery(opts config.RextKeyValueStore, query *tomlconfig.SQLQuery) (err error) {
+	if query == nil || len(query.Query) == 0 {
+		log.Errorln("sql resources require a query")
+		return config.ErrKeyEmptyValue
+	}
+	vals := map[string]interface{}{
+		config.OptKeyRextResourceDefSQLQuery: query.Query,
+	}
+	if query.Timeout != 0 {
+		vals[config.OptKeyRextResourceDefSQLTimeout] = query.Timeout
+	}
+	if query.ValueColumns != nil {
+		vals[config.OptKeyRextResourceDefSQLValueColumns] = query.ValueColumns
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving sql query setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillLimits save the requests limits(can be nil) in opts, only the defined values are saved
+func fillLimits(opts config.RextKeyValueStore, limits *tomlconfig.Limits) (err error) {
+	if limits == nil {
+		return nil
+	}
+	vals := make(map[string]interface{})
+	if limits.RequestsPerSecond != 0 {
+		vals[config.OptKeyRextServiceDefLimitRequestsPerSecond] = limits.RequestsPerSecond
+	}
+	ints := map[string]*int{
+		config.OptKeyRextServiceDefLimitBurst:                 limits.Burst,
+		config.OptKeyRextServiceDefLimitMaxConcurrentRequests: limits.MaxConcurrentRequests,
+	}
+	for key, val := range ints {
+		if val != nil {
+			vals[key] = *val
+		}
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving limit setting")
+			return err
+		}
+	}
+	return nil
+}
+
 func createResourceFrom4API(mtrN2Metric map[string]tomlconfig.Metric, resPath tomlconfig.ResourcePath) (resDef config.RextResourceDef) {
 	resDef = &memconfig.ResourceDef{}
 	resDef.SetType(resPath.PathType)
@@ -107,8 +680,24 @@ func createResourceFrom4API(mtrN2Metric map[string]tomlconfig.Metric, resPath to
 		for _, tomlLabel := range mtr.Options.Labels {
 			label := &memconfig.LabelDef{}
 			label.SetName(tomlLabel.Name)
-			lns := &memconfig.NodeSolver{MType: "jsonNode"}
+			lns := &memconfig.NodeSolver{MType: resPath.NodeSolverType}
 			lns.SetNodePath(tomlLabel.Path)
+			if resPath.NodeSolverType == config.RextNodeSolverTypeRegex {
+				// NOTE(denisacostaq@gmail.com): the label value is the capture group named like the label, in the
+				// metric path if the label have not a path
+				if len(tomlLabel.Path) == 0 {
+					lns.SetNodePath(mtr.Path)
+				}
+				if _, err := lns.GetOptions().SetString(config.OptKeyRextNodeSolverRegexGroup, tomlLabel.Name); err != nil {
+					log.WithFields(log.Fields{"key": config.OptKeyRextNodeSolverRegexGroup, "val": tomlLabel.Name}).Errorln("error saving regex group for label")
+					return resDef
+				}
+			}
+			if resPath.NodeSolverType == config.RextNodeSolverTypePrometheus && len(tomlLabel.Path) == 0 {
+				// NOTE(denisacostaq@gmail.com): the label value is the source label with the same name in the series
+				// selected by the metric path
+				lns.SetNodePath("label_values(" + mtr.Path + ", " + tomlLabel.Name + ")")
+			}
 			label.SetNodeSolver(lns)
 			metric.AddLabel(label)
 		}
@@ -149,3 +738,96 @@ func Fill(conf tomlconfig.RootConfig) (root config.RextRoot, err error) {
 	}
 	return root, err
 }
+
+// fillRetry save the retry policy(can be nil) in opts, only the defined values are saved
+func fillRetry(opts config.RextKeyValueStore, retry *tomlconfig.Retry) (err error) {
+	if retry == nil {
+		return nil
+	}
+	vals := make(map[string]interface{})
+	if retry.MaxAttempts != nil {
+		vals[config.OptKeyRextRetryMaxAttempts] = *retry.MaxAttempts
+	}
+	durations := map[string]time.Duration{
+		config.OptKeyRextRetryInitialBackoff: retry.InitialBackoff,
+		config.OptKeyRextRetryMaxBackoff:     retry.MaxBackoff,
+		config.OptKeyRextRetryTimeBudget:     retry.TimeBudget,
+	}
+	for key, val := range durations {
+		if val != 0 {
+			vals[key] = val
+		}
+	}
+	if retry.StatusCodes != nil {
+		vals[config.OptKeyRextRetryStatusCodes] = retry.StatusCodes
+	}
+	if retry.Errors != nil {
+		vals[config.OptKeyRextRetryErrors] = retry.Errors
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving retry setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillPagination save the pagination settings(can be nil) in opts, only the defined values are saved
+func fillPagination(opts config.RextKeyValueStore, pagination *tomlconfig.Pagination) (err error) {
+	if pagination == nil {
+		return nil
+	}
+	vals := map[string]interface{}{
+		config.OptKeyRextResourceDefPaginationStrategy: pagination.Strategy,
+	}
+	strs := map[string]string{
+		config.OptKeyRextResourceDefPaginationItemsPath:   pagination.ItemsPath,
+		config.OptKeyRextResourceDefPaginationPageParam:   pagination.PageParam,
+		config.OptKeyRextResourceDefPaginationLimitParam:  pagination.LimitParam,
+		config.OptKeyRextResourceDefPaginationCursorPath:  pagination.CursorPath,
+		config.OptKeyRextResourceDefPaginationCursorParam: pagination.CursorParam,
+	}
+	for key, val := range strs {
+		if len(val) != 0 {
+			vals[key] = val
+		}
+	}
+	ints := map[string]*int{
+		config.OptKeyRextResourceDefPaginationPageSize:  pagination.PageSize,
+		config.OptKeyRextResourceDefPaginationFirstPage: pagination.FirstPage,
+		config.OptKeyRextResourceDefPaginationMaxPages:  pagination.MaxPages,
+	}
+	for key, val := range ints {
+		if val != nil {
+			vals[key] = *val
+		}
+	}
+	for key, val := range vals {
+		if _, err = opts.SetObject(key, val); err != nil {
+			log.WithFields(log.Fields{"key": key, "val": val}).Errorln("error saving pagination setting")
+			return err
+		}
+	}
+	return nil
+}
+
+// fillCircuitBreaker save the circuit breaker settings(can be nil) in opts, only the defined values are saved
+func fillCircuitBreaker(opts config.RextKeyValueStore, cb *tomlconfig.CircuitBreaker) (err error) {
+	if cb == nil {
+		return nil
+	}
+	if cb.FailureThreshold != nil {
+		if _, err = opts.SetObject(config.OptKeyRextCircuitBreakerFailureThreshold, *cb.FailureThreshold); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextCircuitBreakerFailureThreshold, "val": *cb.FailureThreshold}).Errorln("error saving circuit breaker failure threshold")
+			return err
+		}
+	}
+	if cb.CoolDown != 0 {
+		if _, err = opts.SetObject(config.OptKeyRextCircuitBreakerCoolDown, cb.CoolDown); err != nil {
+			log.WithFields(log.Fields{"key": config.OptKeyRextCircuitBreakerCoolDown, "val": cb.CoolDown}).Errorln("error saving circuit breaker cool down")
+			return err
+		}
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package tomlconfig
 
+import "time"
+
 // RootConfig is the top level node for the config tree, it has a list of services
 type RootConfig struct {
 	Services []Service
@@ -10,18 +12,69 @@ type RootConfig struct {
 // what is the filesystem path(in case of file protocol)?
 type Service struct {
 	Name string
-	// Protocol is file, http, https
+	// Protocol is file, exec, tcp, sql, http, https or http+unix, for file the Location is the base directory
 	Protocol string
 	Port     uint16
+	// Socket is the Unix domain socket path for the http+unix protocol, Location and Port are not used in this case
+	Socket string
 	// FIXME(denisacostaq@gmial.com): use this base path?
-	BasePath             string
-	AuthType             string
-	TokenHeaderKey       string
-	GenTokenEndpoint     string
+	BasePath string
+	AuthType string
+	// TokenHeaderKey is the name of the header, query parameter or cookie where the token will be
+	// sent, see TokenInjectAs
+	TokenHeaderKey   string
+	GenTokenEndpoint string
+	// GenTokenHTTPMethod is the http method to request the GenTokenEndpoint, GET by default
+	GenTokenHTTPMethod string
+	// GenTokenBody is a template for the body sent to the GenTokenEndpoint, for example:
+	// {"user": "{{.JobName}}", "password": "{{env "TOKEN_PASSWORD"}}"}
+	GenTokenBody string
+	// GenTokenHeaders are extra headers sent to the GenTokenEndpoint
+	GenTokenHeaders map[string]string
+	// TokenKeyFromEndpoint is a json path, a header name or a cookie name, see TokenSource
 	TokenKeyFromEndpoint string
-	Location             Server
-	ResourcePaths        ResourcePathTemplate
-	Metrics              MetricsTemplate
+	// TokenSource is where the token is in the GenTokenEndpoint response: json(default), header or cookie
+	TokenSource string
+	// TokenInjectAs is how the token is sent in the requests: header(default), query or cookie
+	TokenInjectAs string
+	// LoginEndpoint is the endpoint to login when AuthType is SessionLogin, the cookies set in the response
+	// are sent in next requests
+	LoginEndpoint string
+	// LoginHTTPMethod is the http method to request the LoginEndpoint, POST by default
+	LoginHTTPMethod string
+	// LoginBody is a template for the body sent to the LoginEndpoint, see GenTokenBody
+	LoginBody string
+	// LoginHeaders are extra headers sent to the LoginEndpoint
+	LoginHeaders map[string]string
+	// LoginPage is the path where the server redirect when the session expire, LoginEndpoint by default
+	LoginPage string
+	// HMACSecret is the secret to sign the requests when AuthType is HMAC
+	HMACSecret string
+	// HMACSigningString is a template for the string to be signed, the fields .Method, .Path, .Timestamp and
+	// .BodyHash are available, "{{.Method}}\n{{.Path}}\n{{.Timestamp}}\n{{.BodyHash}}" by default
+	HMACSigningString string
+	// HMACSignatureHeader is the header for the signature, X-Signature by default
+	HMACSignatureHeader string
+	// HMACTimestampHeader is the header for the timestamp, X-Timestamp by default
+	HMACTimestampHeader string
+	// HMACBodyHashHeader is the header for the body hash, X-Content-SHA256 by default
+	HMACBodyHashHeader string
+	// TLS are the tls settings for the service, can be overridden in each resource
+	TLS *TLS
+	// HTTP are the http client settings for the service
+	HTTP *HTTP
+	// Limits are the limits for the requests to the service
+	Limits *Limits
+	// Retry is the retry policy for the requests to the service, can be overridden in each resource
+	Retry *Retry
+	// CircuitBreaker are the circuit breaker settings for the data sources in the service, can be overridden in
+	// each resource
+	CircuitBreaker *CircuitBreaker `mapstructure:"circuit_breaker"`
+	// SQL are the database settings for the sql protocol
+	SQL           *SQL `mapstructure:"sql"`
+	Location      Server
+	ResourcePaths ResourcePathTemplate
+	Metrics       MetricsTemplate
 }
 
 // MetricsTemplate is a list of metrics definition, ready to be applied
@@ -75,6 +128,34 @@ type ResourcePath struct {
 	Path           string
 	NodeSolverType string
 	HTTPMethod     string
+	// Decoder is the format of the resource body like json(default), text or xml, see config.RegisterDecoder
+	Decoder string
+	// TLS override the service tls settings for this resource
+	TLS *TLS
+	// Retry override the service retry policy for this resource
+	Retry *Retry
+	// CircuitBreaker override the service circuit breaker settings for this resource
+	CircuitBreaker *CircuitBreaker `mapstructure:"circuit_breaker"`
+	// MaxFileSize is the maximum size in bytes for a file resource, 10MiB by default
+	MaxFileSize *int `mapstructure:"max_file_size"`
+	// Exec are the settings to run the command(the Path) in an exec resource
+	Exec *Exec
+	// JSONRPC are the settings for the call to the endpoint(the Path) in a json_rpc resource
+	JSONRPC *JSONRPC `mapstructure:"json_rpc"`
+	// GraphQL is the query to post to the endpoint(the Path) in a graphql resource
+	GraphQL *GraphQL `mapstructure:"graphql"`
+	// Pagination define how to request all the pages of a rest_api resource
+	Pagination *Pagination `mapstructure:"pagination"`
+	// Stream is the subscription to the endpoint(the Path) in a stream resource
+	Stream *Stream `mapstructure:"stream"`
+	// TCP are the settings for the request to the service address in a tcp resource
+	TCP *TCP `mapstructure:"tcp"`
+	// SQL is the query to run in a sql resource
+	SQL *SQLQuery `mapstructure:"sql"`
+	// CSV are the settings for a csv or tsv Decoder
+	CSV *CSV `mapstructure:"csv"`
+	// INI are the settings for an ini Decoder
+	INI *INI `mapstructure:"ini"`
 	// MetricNames TODO(denisacostaq@gmail.com): trying to define filtered metric can introduce
 	// some redundancy because the other fields
 	MetricNames []string
@@ -84,6 +165,215 @@ type ResourcePath struct {
 // service
 type ResourcePathTemplate []ResourcePath
 
+// TLS define the tls settings to connect to a service
+type TLS struct {
+	// CAFile is a PEM file with the CA certificates to trust
+	CAFile string `mapstructure:"ca_file"`
+	// CertFile is a PEM file with the client certificate, KeyFile is required too
+	CertFile string `mapstructure:"cert_file"`
+	// KeyFile is a PEM file with the client key
+	KeyFile string `mapstructure:"key_file"`
+	// ServerName is used to verify the server certificate and is sent as SNI
+	ServerName string `mapstructure:"server_name"`
+	// InsecureSkipVerify disable the server certificate verification
+	InsecureSkipVerify *bool `mapstructure:"insecure_skip_verify"`
+	// MinVersion is the minimum TLS version, one of 1.0, 1.1, 1.2 or 1.3
+	MinVersion string `mapstructure:"min_version"`
+}
+
+// HTTP define the http client settings to connect to a service, durations are written like "5s" or "1m30s"
+type HTTP struct {
+	// Timeout is the overall time limit for a request, including the connection and the response body read
+	Timeout time.Duration `mapstructure:"timeout"`
+	// ConnectTimeout is the time limit to establish a connection
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	// TLSHandshakeTimeout is the time limit for the TLS handshake
+	TLSHandshakeTimeout time.Duration `mapstructure:"tls_handshake_timeout"`
+	// KeepAlive is the keep-alive period for the connections
+	KeepAlive time.Duration `mapstructure:"keep_alive"`
+	// DisableKeepAlives avoid to reuse the connections
+	DisableKeepAlives *bool `mapstructure:"disable_keep_alives"`
+	// MaxIdleConns is the maximum number of idle connections
+	MaxIdleConns *int `mapstructure:"max_idle_conns"`
+	// MaxIdleConnsPerHost is the maximum number of idle connections per host
+	MaxIdleConnsPerHost *int `mapstructure:"max_idle_conns_per_host"`
+	// MaxConnsPerHost is the maximum number of connections per host, zero means no limit
+	MaxConnsPerHost *int `mapstructure:"max_conns_per_host"`
+	// IdleConnTimeout is how long an idle connection is kept
+	IdleConnTimeout time.Duration `mapstructure:"idle_conn_timeout"`
+	// ProxyURL is the proxy to use, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used
+	// if not present
+	ProxyURL string `mapstructure:"proxy_url"`
+}
+
+// Exec define how a command is run, durations are written like "5s"
+type Exec struct {
+	// Args are the command arguments
+	Args []string `mapstructure:"args"`
+	// Env are extra environment variables like KEY=VALUE, the exporter environment is inherited
+	Env []string `mapstructure:"env"`
+	// Dir is the working directory, the exporter one by default
+	Dir string `mapstructure:"dir"`
+	// Timeout is the time limit for the command, 10s by default
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxConcurrent is the maximum number of concurrent runs of the command, 1 by default
+	MaxConcurrent *int `mapstructure:"max_concurrent"`
+}
+
+// JSONRPC define a JSON-RPC 2.0 call
+type JSONRPC struct {
+	// Method is the method to call
+	Method string `mapstructure:"method"`
+	// Params are the params for the method, a json array or object like '["0.25.0"]'
+	Params string `mapstructure:"params"`
+	// Batch allow to send the call with the other calls to the same endpoint in a single request per scrape
+	Batch bool `mapstructure:"batch"`
+}
+
+// GraphQL define a GraphQL query
+type GraphQL struct {
+	// Query is the query document
+	Query string `mapstructure:"query"`
+	// Variables are the variables for the query, a json object like '{"first": 10}'
+	Variables string `mapstructure:"variables"`
+	// OperationName select the operation to run if the query document have more than one
+	OperationName string `mapstructure:"operation_name"`
+}
+
+// Pagination define how to request the pages of a resource, the items in all the pages are merged in a single array
+type Pagination struct {
+	// Strategy is one of page, cursor or link
+	Strategy string `mapstructure:"strategy"`
+	// ItemsPath is the path to the array of items in each page like "/connections", the page itself if empty
+	ItemsPath string `mapstructure:"items_path"`
+	// PageParam is the query param for the page number, "page" by default
+	PageParam string `mapstructure:"page_param"`
+	// LimitParam is the query param for the page size, "limit" by default
+	LimitParam string `mapstructure:"limit_param"`
+	// PageSize is the number of items per page, the server default if not present
+	PageSize *int `mapstructure:"page_size"`
+	// FirstPage is the number of the first page, 1 by default
+	FirstPage *int `mapstructure:"first_page"`
+	// CursorPath is the path to the cursor for the next page like "/next_cursor"
+	CursorPath string `mapstructure:"cursor_path"`
+	// CursorParam is the query param for the cursor, "cursor" by default
+	CursorParam string `mapstructure:"cursor_param"`
+	// MaxPages is the maximum number of pages to request, 10 by default
+	MaxPages *int `mapstructure:"max_pages"`
+}
+
+// Stream define a long-lived subscription, durations are written like "5s"
+type Stream struct {
+	// Kind is one of websocket or sse
+	Kind string `mapstructure:"kind"`
+	// Subscribe is a message to send after connect to a websocket
+	Subscribe string `mapstructure:"subscribe"`
+	// Window is how many of the latest messages are kept, 1 by default
+	Window *int `mapstructure:"window"`
+	// WithCount allow to read the number of messages received in a count node, the latest ones are in a latest node
+	WithCount bool `mapstructure:"with_count"`
+	// ReconnectInitialBackoff is the wait before the first reconnection, 1s by default
+	ReconnectInitialBackoff time.Duration `mapstructure:"reconnect_initial_backoff"`
+	// ReconnectMaxBackoff is the maximum wait between reconnections, 1m by default
+	ReconnectMaxBackoff time.Duration `mapstructure:"reconnect_max_backoff"`
+}
+
+// TCP define a request to a line oriented text protocol, durations are written like "5s"
+type TCP struct {
+	// Command is sent after connect followed by "\r\n", nothing is sent if empty
+	Command string `mapstructure:"command"`
+	// Terminator is the text that end the response like "END\r\n", the response end when the connection is closed
+	// or the timeout elapse if empty
+	Terminator string `mapstructure:"terminator"`
+	// Timeout is the time limit to connect and read the response, 5s by default
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxSize is the maximum size in bytes for the response, 1MiB by default
+	MaxSize *int `mapstructure:"max_size"`
+	// Separator is the text between the key and the value in each line, the first ':' or white space by default
+	Separator string `mapstructure:"separator"`
+	// LinePrefix is removed from each line like "STAT ", the lines without it are ignored
+	LinePrefix string `mapstructure:"line_prefix"`
+}
+
+// CSV define how to decode comma(or tab) separated values
+type CSV struct {
+	// Delimiter is the field delimiter, ',' for csv and '\t' for tsv by default
+	Delimiter string `mapstructure:"delimiter"`
+	// CommentPrefix is the prefix for the lines to ignore like "#"
+	CommentPrefix string `mapstructure:"comment_prefix"`
+	// Header is auto(default), first_row or none, in auto the first row is the header if none of its fields is a
+	// number
+	Header string `mapstructure:"header"`
+}
+
+// INI define how to decode key value lines grouped in sections
+type INI struct {
+	// Separator is the text between the key and the value, "=" by default
+	Separator string `mapstructure:"separator"`
+}
+
+// SQL define how to connect to a database through database/sql, durations are written like "5m"
+type SQL struct {
+	// Driver is the name of a driver registered in the exporter binary like "postgres" or "mysql"
+	Driver string `mapstructure:"driver"`
+	// DSNFile is a file with the data source name, DSNEnv is used if not present
+	DSNFile string `mapstructure:"dsn_file"`
+	// DSNEnv is an environment variable with the data source name
+	DSNEnv string `mapstructure:"dsn_env"`
+	// MaxOpenConns is the maximum number of open connections, 2 by default
+	MaxOpenConns *int `mapstructure:"max_open_conns"`
+	// MaxIdleConns is the maximum number of idle connections, 2 by default
+	MaxIdleConns *int `mapstructure:"max_idle_conns"`
+	// ConnMaxLifetime is the maximum time a connection is reused, forever by default
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+}
+
+// SQLQuery define a query to run, durations are written like "5s"
+type SQLQuery struct {
+	// Query is the query to run
+	Query string `mapstructure:"query"`
+	// Timeout is the time limit for the query, 5s by default
+	Timeout time.Duration `mapstructure:"timeout"`
+	// ValueColumns are the columns decoded as numbers, the other ones are decoded as strings to be used as labels,
+	// the numeric columns are decoded as numbers if not present
+	ValueColumns []string `mapstructure:"value_columns"`
+}
+
+// Limits define the maximum rate and concurrency of the requests to a service
+type Limits struct {
+	// RequestsPerSecond is the maximum rate of requests, zero means no limit
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	// Burst is how many requests can be made at once over the rate, 1 by default
+	Burst *int `mapstructure:"burst"`
+	// MaxConcurrentRequests is the maximum number of requests in flight, zero means no limit
+	MaxConcurrentRequests *int `mapstructure:"max_concurrent_requests"`
+}
+
+// Retry define how a failed request is retried, durations are written like "100ms" or "2s"
+type Retry struct {
+	// MaxAttempts is the maximum number of attempts for a request, 1 means no retries
+	MaxAttempts *int `mapstructure:"max_attempts"`
+	// InitialBackoff is the wait before the first retry, it is doubled for each next retry
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	// MaxBackoff is the maximum wait between retries
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+	// TimeBudget is the total time for all the attempts, the scrape deadline is used if not present
+	TimeBudget time.Duration `mapstructure:"time_budget"`
+	// StatusCodes are the response status codes to retry
+	StatusCodes []int `mapstructure:"status_codes"`
+	// Errors are the request error classes to retry: connection, timeout or dns
+	Errors []string `mapstructure:"errors"`
+}
+
+// CircuitBreaker define when the requests to a data source are stopped after consecutive failures, durations
+// are written like "30s" or "1m"
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures to open the circuit, 0 disable the circuit breaker
+	FailureThreshold *int `mapstructure:"failure_threshold"`
+	// CoolDown is how long the circuit stay open before a probe request is allowed
+	CoolDown time.Duration `mapstructure:"cool_down"`
+}
+
 // Server the server where is running the service
 type Server struct {
 	// Location should have the ip or URL.
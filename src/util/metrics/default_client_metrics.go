@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/simelo/rextporter/src/config"
+)
+
+// DefaultClientMetrics default metrics for the http clients used to reach the data sources
+type DefaultClientMetrics struct {
+	DataSourceConnections       *prometheus.CounterVec
+	DataSourceScrapeTimeouts    *prometheus.CounterVec
+	DataSourceRequestRetries    *prometheus.CounterVec
+	DataSourceCircuitState      *prometheus.GaugeVec
+	DataSourceThrottledRequests *prometheus.CounterVec
+	DataSourceFileAge           *prometheus.GaugeVec
+	DataSourceExecExitCode      *prometheus.GaugeVec
+	DataSourceExecDuration      *prometheus.GaugeVec
+	DataSourcePagesFetched      *prometheus.CounterVec
+	DataSourceStreamMessages    *prometheus.CounterVec
+	DataSourceStreamConnected   *prometheus.GaugeVec
+	DataSourceStreamReconnects  *prometheus.CounterVec
+}
+
+// NewDefaultClientMetrics create a new DefaultClientMetrics
+func NewDefaultClientMetrics() (clientMetrics *DefaultClientMetrics) {
+	clientMetrics = &DefaultClientMetrics{
+		DataSourceConnections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rextporter_data_source_connections_total",
+				Help: "Connections used to request a data source, reused is true if the connection was taken from the pool",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "reused"},
+		),
+		DataSourceScrapeTimeouts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rextporter_data_source_scrape_timeouts_total",
+				Help: "Metrics not collected from a data source because the scrape deadline was reached",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourceRequestRetries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rextporter_data_source_request_retries_total",
+				Help: "Requests to a data source made again by the retry policy",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourceCircuitState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rextporter_data_source_circuit_state",
+				Help: "State of the circuit breaker for a data source: 0 closed, 1 open and 2 half-open",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourceThrottledRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rextporter_data_source_throttled_requests_total",
+				Help: "Requests to a data source delayed or rejected by the service rate or concurrency limits",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance},
+		),
+		DataSourceFileAge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rextporter_data_source_file_age_seconds",
+				Help: "Time since the last modification of a file data source when it was read",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourceExecExitCode: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rextporter_data_source_exec_exit_code",
+				Help: "Exit code of the last run of a command data source, -1 if it could not be started or was killed",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourceExecDuration: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rextporter_data_source_exec_duration_seconds",
+				Help: "Duration of the last run of a command data source",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourcePagesFetched: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rextporter_data_source_pages_fetched_total",
+				Help: "Pages requested to a paginated data source",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourceStreamMessages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rextporter_data_source_stream_messages_total",
+				Help: "Messages received from a stream data source",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourceStreamConnected: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rextporter_data_source_stream_connected",
+				Help: "State of the subscription to a stream data source: 1 connected and 0 disconnected",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+		DataSourceStreamReconnects: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rextporter_data_source_stream_reconnects_total",
+				Help: "Reconnections to a stream data source after the subscription failed",
+			},
+			[]string{config.KeyLabelJob, config.KeyLabelInstance, "data_source"},
+		),
+	}
+	return clientMetrics
+}
+
+// MustRegister register default metrics for http clients in prometheus
+func (clientMetrics DefaultClientMetrics) MustRegister() {
+	prometheus.MustRegister(clientMetrics.DataSourceConnections)
+	prometheus.MustRegister(clientMetrics.DataSourceScrapeTimeouts)
+	prometheus.MustRegister(clientMetrics.DataSourceRequestRetries)
+	prometheus.MustRegister(clientMetrics.DataSourceCircuitState)
+	prometheus.MustRegister(clientMetrics.DataSourceThrottledRequests)
+	prometheus.MustRegister(clientMetrics.DataSourceFileAge)
+	prometheus.MustRegister(clientMetrics.DataSourceExecExitCode)
+	prometheus.MustRegister(clientMetrics.DataSourceExecDuration)
+	prometheus.MustRegister(clientMetrics.DataSourcePagesFetched)
+	prometheus.MustRegister(clientMetrics.DataSourceStreamMessages)
+	prometheus.MustRegister(clientMetrics.DataSourceStreamConnected)
+	prometheus.MustRegister(clientMetrics.DataSourceStreamReconnects)
+}
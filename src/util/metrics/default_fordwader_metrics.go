@@ -9,6 +9,7 @@ import (
 type DefaultFordwaderMetrics struct {
 	FordwaderResponseDuration      *prometheus.GaugeVec
 	FordwaderScrapeDurationSeconds *prometheus.GaugeVec
+	FordwaderScrapeTimeouts        *prometheus.CounterVec
 }
 
 // NewDefaultFordwaderMetrics create a new DefaultFordwaderMetrics
@@ -29,6 +30,13 @@ func NewDefaultFordwaderMetrics() (fordwaderMetrics *DefaultFordwaderMetrics) {
 			},
 			instance4JobLabels,
 		),
+		FordwaderScrapeTimeouts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rextporter_fordwader_scrape_timeouts_total",
+				Help: "Scrapes in which a fordwader could not get the metrics before the scrape deadline",
+			},
+			instance4JobLabels,
+		),
 	}
 	return fordwaderMetrics
 }
@@ -37,4 +45,5 @@ func NewDefaultFordwaderMetrics() (fordwaderMetrics *DefaultFordwaderMetrics) {
 func (fordwaderMetrics DefaultFordwaderMetrics) MustRegister() {
 	prometheus.MustRegister(fordwaderMetrics.FordwaderResponseDuration)
 	prometheus.MustRegister(fordwaderMetrics.FordwaderScrapeDurationSeconds)
+	prometheus.MustRegister(fordwaderMetrics.FordwaderScrapeTimeouts)
 }